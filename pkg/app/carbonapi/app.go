@@ -16,12 +16,14 @@ import (
 
 	"github.com/bookingcom/carbonapi/pkg/backend"
 	bnet "github.com/bookingcom/carbonapi/pkg/backend/net"
+	"github.com/bookingcom/carbonapi/pkg/backend/remoteread"
 	"github.com/bookingcom/carbonapi/pkg/blocker"
 	"github.com/bookingcom/carbonapi/pkg/cache"
 	"github.com/bookingcom/carbonapi/pkg/carbonapipb"
 	"github.com/bookingcom/carbonapi/pkg/cfg"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/cairo/png"
+	"github.com/bookingcom/carbonapi/pkg/limiter"
 	"github.com/bookingcom/carbonapi/pkg/parser"
 	"github.com/bookingcom/carbonapi/pkg/tldcache"
 	"github.com/dgryski/go-expirecache"
@@ -43,6 +45,7 @@ type App struct {
 
 	queryCache               cache.BytesCache
 	findCache                cache.BytesCache
+	metricCache              cache.BytesCache
 	TopLevelDomainCache      *expirecache.Cache
 	TopLevelDomainPrefixes   []tldcache.TopLevelDomainPrefix
 	NotFoundWhenTLDCacheMiss bool
@@ -75,6 +78,7 @@ func New(config cfg.API, lg *zap.Logger, buildVersion string) (*App, error) {
 		config:          config,
 		queryCache:      cache.NullCache{},
 		findCache:       cache.NullCache{},
+		metricCache:     cache.NullCache{},
 		defaultTimeZone: time.Local,
 		ms:              ms,
 		requestBlocker:  blocker.NewRequestBlocker(config.BlockHeaderFile, config.BlockHeaderUpdatePeriod, lg),
@@ -133,7 +137,9 @@ func setUpConfig(app *App, logger *zap.Logger) {
 		}
 	}
 
-	functions.New(app.config.FunctionsConfigs, logger)
+	if err := functions.New(app.config.FunctionsConfigs, logger); err != nil {
+		logger.Fatal("invalid functions config", zap.Error(err))
+	}
 
 	switch app.config.Cache.Type {
 	case "memcache":
@@ -147,6 +153,7 @@ func setUpConfig(app *App, logger *zap.Logger) {
 
 		app.queryCache = cache.NewMemcached(app.config.Cache.Prefix, app.config.Cache.QueryTimeoutMs, app.config.Cache.MemcachedServers...)
 		app.findCache = cache.NewMemcached(app.config.Cache.Prefix, app.config.Cache.QueryTimeoutMs, app.config.Cache.MemcachedServers...)
+		app.metricCache = cache.NewMemcached(app.config.Cache.Prefix, app.config.Cache.QueryTimeoutMs, app.config.Cache.MemcachedServers...)
 
 	case "memcacheReplicated":
 		if len(app.config.Cache.MemcachedServers) == 0 {
@@ -189,18 +196,52 @@ func setUpConfig(app *App, logger *zap.Logger) {
 			app.ms.CacheTimeouts.WithLabelValues("find"),
 			app.config.Cache.MemcachedServers...)
 
+		respReadMetric, err := app.ms.CacheRespRead.CurryWith(prometheus.Labels{"request": "metric"})
+		if err != nil {
+			logger.Fatal("could not form respRead metrics for the metric cache", zap.Error(err))
+		}
+		reqsMetric, err := app.ms.CacheRequests.CurryWith(prometheus.Labels{"request": "metric"})
+		if err != nil {
+			logger.Fatal("could not form reqests counter metric for the metric cache", zap.Error(err))
+		}
+		app.metricCache = cache.NewReplicatedMemcached(app.config.Cache.Prefix,
+			app.config.Cache.QueryTimeoutMs,
+			app.config.Cache.MemcachedTimeoutMs,
+			app.config.Cache.MemcachedMaxIdleConns,
+			reqsMetric,
+			respReadMetric,
+			app.ms.CacheTimeouts.WithLabelValues("metric"),
+			app.config.Cache.MemcachedServers...)
+
+	case "redis":
+		if len(app.config.Cache.RedisServers) == 0 {
+			logger.Fatal("redis cache requested but no redis servers provided")
+		}
+		logger.Info("redis configured",
+			zap.Strings("servers", app.config.Cache.RedisServers),
+			zap.String("master_name", app.config.Cache.RedisMasterName))
+
+		app.queryCache = newRedisCache(app, "render", logger)
+		app.findCache = newRedisCache(app, "find", logger)
+		app.metricCache = newRedisCache(app, "metric", logger)
+
 	case "mem":
 		app.queryCache = cache.NewExpireCache(uint64(app.config.Cache.Size * 1024 * 1024))
 		app.findCache = cache.NewExpireCache(uint64(app.config.Cache.Size * 1024 * 1024))
+		// the metric and the delta caches have their own budget, and none if they are disabled
+		if app.config.Cache.MetricCacheTimeoutSec > 0 || app.config.Cache.DeltaCacheTimeoutSec > 0 {
+			app.metricCache = cache.NewExpireCache(uint64(app.config.Cache.MetricCacheSize * 1024 * 1024))
+		}
 
 	case "null":
 		// defaults
 		app.queryCache = cache.NullCache{}
 		app.findCache = cache.NullCache{}
+		app.metricCache = cache.NullCache{}
 	default:
 		logger.Error("unknown cache type",
 			zap.String("cache_type", app.config.Cache.Type),
-			zap.Strings("known_cache_types", []string{"null", "mem", "memcache"}),
+			zap.Strings("known_cache_types", []string{"null", "mem", "memcache", "memcacheReplicated", "redis"}),
 		)
 	}
 
@@ -258,6 +299,25 @@ func setUpConfig(app *App, logger *zap.Logger) {
 
 }
 
+func newRedisCache(app *App, request string, logger *zap.Logger) cache.BytesCache {
+	reqs, err := app.ms.RedisCacheRequests.CurryWith(prometheus.Labels{"request": request})
+	if err != nil {
+		logger.Fatal("could not form requests counter metric for the redis cache",
+			zap.String("request", request), zap.Error(err))
+	}
+
+	return cache.NewRedis(app.config.Cache.Prefix,
+		app.config.Cache.QueryTimeoutMs,
+		cache.RedisConfig{
+			Servers:    app.config.Cache.RedisServers,
+			MasterName: app.config.Cache.RedisMasterName,
+			Password:   app.config.Cache.RedisPassword,
+			DB:         app.config.Cache.RedisDB,
+		},
+		reqs,
+		app.ms.RedisCacheTimeouts.WithLabelValues(request))
+}
+
 func (app *App) deferredAccessLogging(accessLogger *zap.Logger, r *http.Request, accessLogDetails *carbonapipb.AccessLogDetails, t time.Time, level zapcore.Level) {
 	accessLogDetails.Runtime = time.Since(t).Seconds()
 	accessLogDetails.RequestMethod = r.Method
@@ -300,6 +360,10 @@ func InitBackends(config cfg.Zipper, zms *ZipperPrometheusMetrics, ms *Prometheu
 
 	configBackendList := config.GetBackends()
 	backends := make([]backend.Backend, 0, len(configBackendList))
+	// the backends of a cluster share its hedger and hash ring
+	hedgers := make(map[[2]string]*backend.Hedger)
+	rings := make(map[[2]string]*backend.Ring)
+	routed := make(map[[2]string]map[string]bool)
 	for _, host := range configBackendList {
 		if host.Http == "" {
 			return nil, fmt.Errorf("backend without http address was provided: %+v", host)
@@ -317,9 +381,22 @@ func InitBackends(config cfg.Zipper, zms *ZipperPrometheusMetrics, ms *Prometheu
 			PathCacheExpirySec: uint32(config.ExpireDelaySec),
 			Responses:          zms.BackendResponses,
 			Logger:             lg,
+			Protocol:           host.Protocol,
 		}
 		var be backend.BackendImpl
-		if host.Grpc != "" {
+		if host.RemoteRead != nil {
+			be, err = remoteread.New(remoteread.Config{
+				Address:            host.Http,
+				RemoteRead:         *host.RemoteRead,
+				DC:                 dc,
+				Cluster:            cluster,
+				Client:             client,
+				Timeout:            config.Timeouts.AfterStarted,
+				PathCacheExpirySec: uint32(config.ExpireDelaySec),
+				Responses:          zms.BackendResponses,
+				Logger:             lg,
+			})
+		} else if host.Grpc != "" {
 			be, err = bnet.NewGrpc(bnet.GrpcConfig{
 				Config:                bConf,
 				GrpcAddress:           host.Grpc,
@@ -330,16 +407,45 @@ func InitBackends(config cfg.Zipper, zms *ZipperPrometheusMetrics, ms *Prometheu
 			be, err = bnet.New(bConf)
 		}
 		if err != nil {
-			return nil, errors.Wrapf(err, "could not create backend for host: %s", host)
+			return nil, errors.Wrapf(err, "could not create backend for host: %s", host.Http)
 		}
 
 		beDuration, err := ms.BackendDuration.CurryWith(prometheus.Labels{"dc": dc, "cluster": cluster})
 		if err != nil {
 			return nil, errors.Wrap(err, "could not curry backend duration metric")
 		}
+		backendLabels := prometheus.Labels{"dc": dc, "cluster": cluster, "backend": host.Http}
+		health := backend.NewHealth(config.BackendHealth,
+			zms.BackendHealthState.With(backendLabels),
+			zms.BackendFailureRatio.With(backendLabels))
+		hedger, ok := hedgers[[2]string{dc, cluster}]
+		if !ok {
+			hedger = backend.NewHedger(config.HedgeOfBackend(host.Http))
+			hedgers[[2]string{dc, cluster}] = hedger
+		}
+		ring, ok := rings[[2]string{dc, cluster}]
+		if !ok {
+			ring, err = backend.NewRing(config.RoutingOfBackend(host.Http))
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not create the hash ring of cluster: %s", cluster)
+			}
+			rings[[2]string{dc, cluster}] = ring
+		}
+		route := ring.Route(host.Http)
+		if ring != nil && route == nil {
+			return nil, fmt.Errorf("backend %s is not a node of the hash ring of cluster: %s", host.Http, cluster)
+		}
+		if routed[[2]string{dc, cluster}] == nil {
+			routed[[2]string{dc, cluster}] = make(map[string]bool)
+		}
+		routed[[2]string{dc, cluster}][host.Http] = true
 		b = backend.NewBackend(be,
 			config.BackendQueueSize,
-			config.ConcurrencyLimitPerServer,
+			limiter.New(config.AdaptiveLimit, config.ConcurrencyLimitPerServer, zms.BackendConcurrencyLimit.With(backendLabels)),
+			health,
+			hedger,
+			route,
+			config.RenderBatch,
 			zms.BackendRequestsInQueue,
 			zms.BackendSemaphoreSaturation,
 			zms.BackendTimeInQSec,
@@ -349,6 +455,15 @@ func InitBackends(config cfg.Zipper, zms *ZipperPrometheusMetrics, ms *Prometheu
 		backends = append(backends, b)
 	}
 
+	// the metrics owned by a node without a backend would be requested from none
+	for key, ring := range rings {
+		for _, address := range ring.Backends() {
+			if !routed[key][address] {
+				return nil, fmt.Errorf("node %s of the hash ring of cluster %s is not one of its backends", address, key[1])
+			}
+		}
+	}
+
 	return backends, nil
 }
 
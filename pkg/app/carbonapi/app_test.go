@@ -0,0 +1,54 @@
+package carbonapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"go.uber.org/zap"
+)
+
+func TestInitBackendsRouting(t *testing.T) {
+	nodes := []cfg.RoutingNode{
+		{Server: "a", Backend: "http://a:8080"},
+		{Server: "b", Backend: "http://b:8080"},
+		{Server: "c", Backend: "http://c:8080"},
+	}
+	tests := []struct {
+		name     string
+		backends []string
+		err      string
+	}{
+		{name: "all nodes", backends: []string{"http://a:8080", "http://b:8080", "http://c:8080"}},
+		{name: "backend without node", backends: []string{"http://a:8080", "http://b:8080", "http://c:8080", "http://d:8080"},
+			err: "backend http://d:8080 is not a node"},
+		{name: "node without backend", backends: []string{"http://a:8080", "http://b:8080"},
+			err: "node http://c:8080 of the hash ring of cluster cluster is not one of its backends"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := cfg.DefaultZipperConfig()
+			config.BackendsByCluster = []cfg.Cluster{{
+				Name:     "cluster",
+				Backends: tt.backends,
+				Routing:  &cfg.Routing{Hash: "carbon_ch", Nodes: nodes},
+			}}
+
+			ms := newPrometheusMetrics(cfg.DefaultAPIConfig())
+			backends, err := InitBackends(config, NewZipperPrometheusMetrics(config), &ms, zap.NewNop())
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(backends) != len(tt.backends) {
+					t.Errorf("expected %d backends, got %d", len(tt.backends), len(backends))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected the error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
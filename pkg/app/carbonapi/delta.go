@@ -0,0 +1,183 @@
+package carbonapi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	dataTypes "github.com/bookingcom/carbonapi/pkg/types"
+	"go.uber.org/zap"
+)
+
+// The delta cache remembers the last fetched window of a metric for the length of its time range.
+// When a relative range such as from=-24h is refreshed, only the tail that is newer than the window
+// is fetched and stitched to it, the expired head of the window is dropped.
+
+// deltaWindow is the remembered result of a fetch over [from, until).
+type deltaWindow struct {
+	from    int32
+	until   int32
+	metrics []*types.MetricData
+}
+
+func (app *App) deltaCacheEnabled(useCache bool) bool {
+	return useCache && app.config.Cache.DeltaCacheTimeoutSec > 0
+}
+
+// deltaCacheKey does not include the time range itself, only its length. This way the windows of
+// the refreshes of a relative range share the key.
+func deltaCacheKey(m parser.MetricRequest) string {
+	return fmt.Sprintf("delta:%s:%d:%d", m.Metric, m.Until-m.From, m.MaxDataPoints)
+}
+
+// getDeltaWindow returns the remembered window of the metric if the request can be completed from it
+// by fetching the tail.
+func (app *App) getDeltaWindow(m parser.MetricRequest, lg *zap.Logger) (*deltaWindow, bool) {
+	blob, err := app.metricCache.Get(deltaCacheKey(m))
+	if err != nil || len(blob) == 0 {
+		return nil, false
+	}
+
+	w, err := unmarshalDeltaWindow(blob)
+	if err != nil {
+		lg.Warn("failed to decode the delta cache window", zap.Error(err))
+		return nil, false
+	}
+
+	if w.from > m.From || m.From >= w.until || w.until > m.Until || len(w.metrics) == 0 {
+		return nil, false
+	}
+
+	return w, true
+}
+
+// setDeltaWindow remembers the fetched series as the window of the metric in the background.
+func (app *App) setDeltaWindow(m parser.MetricRequest, metrics []*types.MetricData, lg *zap.Logger) {
+	blob, err := marshalDeltaWindow(&deltaWindow{from: m.From, until: m.Until, metrics: metrics})
+	if err != nil {
+		lg.Warn("failed to encode the delta cache window", zap.Error(err))
+		return
+	}
+
+	key := deltaCacheKey(m)
+	go func() {
+		err := app.metricCache.Set(key, blob, app.config.Cache.DeltaCacheTimeoutSec)
+		if err != nil {
+			lg.Info("delta cache set failed", zap.String("metric", m.Metric), zap.Error(err))
+		}
+	}()
+}
+
+// tailFrom is the start of the fetch that completes the window. The last point of the window is
+// fetched again, as it may have been incomplete at the time of the previous fetch. The backends
+// return the points after the start of the fetch, hence the second before the last point.
+func (w *deltaWindow) tailFrom() int32 {
+	from := w.until
+	for _, m := range w.metrics {
+		if last := m.StopTime - m.StepTime; last < from {
+			from = last
+		}
+	}
+	if from <= w.from {
+		return w.from
+	}
+
+	return from - 1
+}
+
+// stitch joins the fetched tail to the window and drops the points before the start of the request.
+// The tail may have a finer step than the window, e.g. when the window falls into a coarser retention
+// or was consolidated to maxDataPoints, in which case it is consolidated to the step of the window.
+// It fails if the tail does not continue the window, e.g. when the set of series changed.
+func (w *deltaWindow) stitch(m parser.MetricRequest, tail []*types.MetricData) ([]*types.MetricData, bool) {
+	if len(tail) != len(w.metrics) {
+		return nil, false
+	}
+
+	tailByName := make(map[string]*types.MetricData, len(tail))
+	for _, t := range tail {
+		tailByName[t.Name] = t
+	}
+
+	res := make([]*types.MetricData, 0, len(w.metrics))
+	for _, c := range w.metrics {
+		t, ok := tailByName[c.Name]
+		if !ok {
+			return nil, false
+		}
+
+		step := c.StepTime
+		if step <= 0 || t.StepTime <= 0 || step%t.StepTime != 0 {
+			return nil, false
+		}
+		if t.StartTime < c.StartTime || t.StartTime > c.StopTime || (t.StartTime-c.StartTime)%step != 0 {
+			return nil, false
+		}
+		// the tail starts on a point of the window, so its points fall into the points of the window in order
+		t = t.Consolidate(int(step / t.StepTime))
+
+		// the first point of the window that is not before the start of the request
+		head := 0
+		if m.From > c.StartTime {
+			head = int((m.From - c.StartTime + step - 1) / step)
+		}
+		headEnd := int((t.StartTime - c.StartTime) / step)
+		if head > headEnd {
+			return nil, false
+		}
+
+		values := make([]float64, 0, headEnd-head+len(t.Values))
+		values = append(values, c.Values[head:headEnd]...)
+		values = append(values, t.Values...)
+		isAbsent := make([]bool, 0, cap(values))
+		isAbsent = append(isAbsent, c.IsAbsent[head:headEnd]...)
+		isAbsent = append(isAbsent, t.IsAbsent...)
+
+		start := c.StartTime + int32(head)*step
+		res = append(res, &types.MetricData{Metric: dataTypes.Metric{
+			Name:           c.Name,
+			StartTime:      start,
+			StopTime:       start + int32(len(values))*step,
+			StepTime:       step,
+			Values:         values,
+			IsAbsent:       isAbsent,
+			XFilesFactor:   t.XFilesFactor,
+			SourceClusters: t.SourceClusters,
+		}})
+	}
+
+	return res, true
+}
+
+// The window is stored as its time range followed by the series in the encoding of the metric cache.
+func marshalDeltaWindow(w *deltaWindow) ([]byte, error) {
+	blob, err := marshalCachedMetrics(w.metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 8, 8+len(blob))
+	binary.BigEndian.PutUint32(out[0:4], uint32(w.from))
+	binary.BigEndian.PutUint32(out[4:8], uint32(w.until))
+
+	return append(out, blob...), nil
+}
+
+func unmarshalDeltaWindow(blob []byte) (*deltaWindow, error) {
+	if len(blob) < 8 {
+		return nil, errors.New("delta cache window is too short")
+	}
+
+	metrics, err := unmarshalCachedMetrics(blob[8:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &deltaWindow{
+		from:    int32(binary.BigEndian.Uint32(blob[0:4])),
+		until:   int32(binary.BigEndian.Uint32(blob[4:8])),
+		metrics: metrics,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package carbonapi
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+func TestDeltaWindowStitch(t *testing.T) {
+	w := &deltaWindow{
+		from:  100,
+		until: 160,
+		metrics: []*types.MetricData{
+			types.MakeMetricData("foo", []float64{1, 2, 3, 4, 5, 6}, 10, 100),
+		},
+	}
+
+	// the backends return the points after the start of the fetch
+	if from := w.tailFrom(); from != 149 {
+		t.Fatalf("expected the tail to be fetched from the last point at 150, got %d", from)
+	}
+
+	// the refresh 25s later: the last point got updated and two points were added
+	m := parser.MetricRequest{Metric: "foo", From: 125, Until: 185}
+	tail := []*types.MetricData{
+		types.MakeMetricData("foo", []float64{60, 7, 8}, 10, 150),
+	}
+
+	got, ok := w.stitch(m, tail)
+	if !ok {
+		t.Fatal("expected the tail to be stitched")
+	}
+
+	exp := []*types.MetricData{
+		types.MakeMetricData("foo", []float64{4, 5, 60, 7, 8}, 10, 130),
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got %+v, expected %+v", got[0], exp[0])
+	}
+}
+
+func TestDeltaWindowStitchFinerStep(t *testing.T) {
+	// the window falls into a coarser retention than its tail
+	w := &deltaWindow{
+		from:  0,
+		until: 120,
+		metrics: []*types.MetricData{
+			types.MakeMetricData("foo", []float64{1, 2, 3, 4}, 30, 0),
+		},
+	}
+	if from := w.tailFrom(); from != 89 {
+		t.Fatalf("expected the tail to be fetched from the last point at 90, got %d", from)
+	}
+
+	m := parser.MetricRequest{Metric: "foo", From: 30, Until: 160}
+	tail := []*types.MetricData{
+		types.MakeMetricData("foo", []float64{10, 20, 30, 40, math.NaN(), 60, 70}, 10, 90),
+	}
+
+	got, ok := w.stitch(m, tail)
+	if !ok {
+		t.Fatal("expected the tail to be stitched")
+	}
+
+	// the tail is consolidated to the step of the window with the average
+	exp := []*types.MetricData{
+		types.MakeMetricData("foo", []float64{2, 3, 20, 50, 70}, 30, 30),
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got %+v, expected %+v", got[0], exp[0])
+	}
+}
+
+func TestDeltaWindowStitchMismatch(t *testing.T) {
+	w := &deltaWindow{
+		from:  100,
+		until: 160,
+		metrics: []*types.MetricData{
+			types.MakeMetricData("foo", []float64{1, 2, 3, 4, 5, 6}, 10, 100),
+		},
+	}
+	m := parser.MetricRequest{Metric: "foo", From: 125, Until: 185}
+
+	tests := []struct {
+		name string
+		tail []*types.MetricData
+	}{
+		{
+			name: "coarser step",
+			tail: []*types.MetricData{types.MakeMetricData("foo", []float64{1, 2}, 20, 140)},
+		},
+		{
+			name: "step not dividing the window step",
+			tail: []*types.MetricData{types.MakeMetricData("foo", []float64{1, 2, 3, 4}, 4, 150)},
+		},
+		{
+			name: "new series",
+			tail: []*types.MetricData{
+				types.MakeMetricData("foo", []float64{6, 7, 8}, 10, 150),
+				types.MakeMetricData("bar", []float64{6, 7, 8}, 10, 150),
+			},
+		},
+		{
+			name: "gap",
+			tail: []*types.MetricData{types.MakeMetricData("foo", []float64{1}, 10, 170)},
+		},
+		{
+			name: "misaligned",
+			tail: []*types.MetricData{types.MakeMetricData("foo", []float64{1, 2}, 10, 155)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := w.stitch(m, tt.tail); ok {
+				t.Error("expected the stitch to fail")
+			}
+		})
+	}
+}
+
+func TestDeltaWindowEncoding(t *testing.T) {
+	w := &deltaWindow{
+		from:  100,
+		until: 160,
+		metrics: []*types.MetricData{
+			types.MakeMetricData("foo", []float64{1, 2, 3, 4, 5, 6}, 10, 100),
+		},
+	}
+
+	blob, err := marshalDeltaWindow(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := unmarshalDeltaWindow(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, w) {
+		t.Errorf("got %+v, expected %+v", got, w)
+	}
+}
+
+func TestDeltaCacheKeySharedByRefreshes(t *testing.T) {
+	a := deltaCacheKey(parser.MetricRequest{Metric: "foo", From: 100, Until: 86500})
+	b := deltaCacheKey(parser.MetricRequest{Metric: "foo", From: 130, Until: 86530})
+	if a != b {
+		t.Errorf("expected the refreshes of a relative range to share the key, got %s and %s", a, b)
+	}
+}
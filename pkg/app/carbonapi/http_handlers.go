@@ -17,6 +17,7 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/bookingcom/carbonapi/pkg/backend"
 	"github.com/bookingcom/carbonapi/pkg/cache"
 	"github.com/bookingcom/carbonapi/pkg/carbonapipb"
 	"github.com/bookingcom/carbonapi/pkg/date"
@@ -177,6 +178,11 @@ func (app *App) renderHandler(w http.ResponseWriter, r *http.Request, lg *zap.Lo
 		writeError(uuid, r, w, http.StatusBadRequest, err.Error(), form.format, &toLog)
 		return
 	}
+	// the fetched series and their keys in the metric map carry maxDataPoints,
+	// so that it reaches the backends as well as the expression evaluation
+	ctx = util.WithMaxDataPoints(ctx, form.maxDataPoints)
+	// the functions working with calendar time, e.g. smartSummarize, use the time zone of the request
+	ctx = util.WithTimeZone(ctx, app.location(form, lg))
 
 	if form.from32 >= form.until32 {
 		var clientErrMsgFmt string
@@ -316,21 +322,28 @@ func (app *App) renderHandler(w http.ResponseWriter, r *http.Request, lg *zap.Lo
 		app.ms.RequestCancel.WithLabelValues("render", ctx.Err().Error()).Inc()
 	}
 
-	body, err := app.renderWriteBody(results, form, r, lg)
-	if err != nil {
-		writeError(uuid, r, w, http.StatusInternalServerError, err.Error(), form.format, &toLog)
-		logLevel = zapcore.ErrorLevel
-		return
-	}
+	var body []byte
+	var writeErr error
+	if isStreamedFormat(form.format) {
+		// the large exports would otherwise be held in memory in full before the first byte is sent
+		body, writeErr = app.renderStreamBody(ctx, w, results, form, lg)
+	} else {
+		body, err = app.renderWriteBody(results, form, r, lg)
+		if err != nil {
+			writeError(uuid, r, w, http.StatusInternalServerError, err.Error(), form.format, &toLog)
+			logLevel = zapcore.ErrorLevel
+			return
+		}
 
-	writeErr := writeResponse(ctx, w, body, form.format, form.jsonp)
+		writeErr = writeResponse(ctx, w, body, form.format, form.jsonp)
+	}
 	if writeErr != nil {
 		toLog.HttpCode = 499
 		logLevel = zapcore.WarnLevel
 	} else {
 		toLog.HttpCode = http.StatusOK
 	}
-	if len(results) != 0 {
+	if len(results) != 0 && body != nil {
 		go func() {
 			err := app.queryCache.Set(form.cacheKey, body, form.cacheTimeout)
 			if err != nil {
@@ -419,6 +432,8 @@ func (app *App) getTargetData(ctx context.Context, target string, exp parser.Exp
 	var metricErrs []error
 
 	ResultChannelByMetricRequest := make(map[parser.MetricRequest]chan RenderResponse)
+	renderRequestsByMetricRequest := make(map[parser.MetricRequest][]string)
+	deltaByMetricRequest := make(map[parser.MetricRequest]*deltaWindow)
 	for _, m := range exp.Metrics() {
 		lgm := lg.With(zap.String("metric", m.Metric))
 		Trace(lgm, "getting metric data from upstream")
@@ -426,6 +441,7 @@ func (app *App) getTargetData(ctx context.Context, target string, exp parser.Exp
 		mfetch := m
 		mfetch.From += from
 		mfetch.Until += until
+		mfetch.MaxDataPoints = util.GetMaxDataPoints(ctx)
 
 		if _, ok := ResultChannelByMetricRequest[mfetch]; ok {
 			// already requested this metric for this request
@@ -448,6 +464,18 @@ func (app *App) getTargetData(ctx context.Context, target string, exp parser.Exp
 			}
 		}
 
+		if app.metricCacheEnabled(useCache) {
+			if cached, ok := app.getCachedMetrics(mfetch, lgm); ok {
+				Trace(lgm, "got metric data from the metric cache", zap.Int("series", len(cached)))
+				for _, r := range cached {
+					metrics++
+					size += len(r.Values) // close enough
+				}
+				metricMap[mfetch] = cached
+				continue
+			}
+		}
+
 		// This _sometimes_ sends a *find* request
 		useCacheForRenderResolveGlobs := useCache && app.config.EnableCacheForRenderResolveGlobs
 		renderRequests, err := app.getRenderRequests(ctx, m, useCacheForRenderResolveGlobs, toLog, lgm)
@@ -462,60 +490,24 @@ func (app *App) getTargetData(ctx context.Context, target string, exp parser.Exp
 		}
 		Trace(lgm, "got sub-requests. sending them upstream", zap.Int("sub-requests", len(renderRequests)))
 
-		renderRequestContext := ctx
-		subrequestCount := len(renderRequests)
-		if subrequestCount > 1 {
-			renderRequestContext = util.WithPriority(ctx, subrequestCount)
-		}
-		app.ms.UpstreamSubRenderNum.Observe(float64(subrequestCount))
-		rch := make(chan RenderResponse, len(renderRequests))
-		for _, m := range renderRequests {
-			// This blocks when the queue fills up, which is fine as the below result read would block anyway.
-			//
-			// TODO: Maybe handle record drops when the queue is full.
-			req := &RenderReq{
-				Path:  m,
-				From:  mfetch.From,
-				Until: mfetch.Until,
-
-				Ctx:       renderRequestContext,
-				ToLog:     toLog,
-				StartTime: time.Now(),
-
-				Results: rch,
-			}
-
-			if subrequestCount > app.config.LargeReqSize {
-				app.slowQ <- req
-				app.ms.UpstreamEnqueuedRequests.WithLabelValues("slow").Inc()
-				app.ms.UpstreamRequestsInQueue.WithLabelValues("slow").Inc()
-			} else {
-				app.fastQ <- req
-				app.ms.UpstreamEnqueuedRequests.WithLabelValues("fast").Inc()
-				app.ms.UpstreamRequestsInQueue.WithLabelValues("fast").Inc()
+		fetchFrom := mfetch.From
+		if app.deltaCacheEnabled(useCache) {
+			if w, ok := app.getDeltaWindow(mfetch, lgm); ok {
+				// only the tail that is not in the remembered window is fetched
+				fetchFrom = w.tailFrom()
+				deltaByMetricRequest[mfetch] = w
+				Trace(lgm, "fetching the tail of the remembered window", zap.Int32("from", fetchFrom))
 			}
 		}
-		ResultChannelByMetricRequest[mfetch] = rch
+
+		renderRequestsByMetricRequest[mfetch] = renderRequests
+		ResultChannelByMetricRequest[mfetch] = app.enqueueRenderRequests(ctx, renderRequests, mfetch, fetchFrom, toLog)
 	}
 
 	for mfetch, rch := range ResultChannelByMetricRequest {
 		lgm := lg.With(zap.String("metric", mfetch.Metric))
 		renderRequestsCount := cap(rch)
-		errs := make([]error, 0)
-		for i := 0; i < renderRequestsCount; i++ {
-			resp := <-rch
-			if resp.error != nil {
-				errs = append(errs, resp.error)
-				continue
-			}
-
-			for _, r := range resp.data {
-				metrics++
-				size += len(r.Values) // close enough
-				metricMap[mfetch] = append(metricMap[mfetch], r)
-			}
-		}
-		close(rch)
+		data, errs := collectRenderResponses(rch)
 
 		Trace(lgm, "sub-requests returned", zap.Int("errors", len(errs)), zap.Int("total requests", renderRequestsCount))
 		// We have to check it here because we don't want to return before closing rch
@@ -526,6 +518,33 @@ func (app *App) getTargetData(ctx context.Context, target string, exp parser.Exp
 		default:
 		}
 
+		if w, ok := deltaByMetricRequest[mfetch]; ok {
+			stitched, ok := w.stitch(mfetch, data)
+			if ok && len(errs) == 0 {
+				app.ms.DeltaCacheHits.Inc()
+				data = stitched
+			} else {
+				// the tail does not fit the window, e.g. the step or the set of series changed
+				Trace(lgm, "failed to stitch the tail to the remembered window, fetching the full range")
+				app.ms.DeltaCacheFallbacks.Inc()
+				rch = app.enqueueRenderRequests(ctx, renderRequestsByMetricRequest[mfetch], mfetch, mfetch.From, toLog)
+				data, errs = collectRenderResponses(rch)
+
+				select {
+				case <-ctx.Done():
+					Trace(lgm, "context done while getting target data", zap.Error(ctx.Err()))
+					return ctx.Err(), 0
+				default:
+				}
+			}
+		}
+
+		for _, r := range data {
+			metrics++
+			size += len(r.Values) // close enough
+			metricMap[mfetch] = append(metricMap[mfetch], r)
+		}
+
 		metricErr, metricErrStr := optimistFanIn(errs, renderRequestsCount, "requests")
 		*partFail = (*partFail) || (metricErrStr != "")
 		if metricErr != nil {
@@ -533,6 +552,16 @@ func (app *App) getTargetData(ctx context.Context, target string, exp parser.Exp
 		}
 
 		expr.SortMetrics(metricMap[mfetch], mfetch)
+
+		// the partial results are not cached, the next request may get the complete ones
+		if len(errs) == 0 && len(metricMap[mfetch]) != 0 {
+			if app.metricCacheEnabled(useCache) {
+				app.setCachedMetrics(mfetch, metricMap[mfetch], lgm)
+			}
+			if app.deltaCacheEnabled(useCache) {
+				app.setDeltaWindow(mfetch, metricMap[mfetch], lgm)
+			}
+		}
 	} // range exp.Metrics
 
 	Trace(lg, "got metrics for target", zap.Int("metrics", len(exp.Metrics())), zap.Int("errors", len(metricErrs)))
@@ -545,6 +574,66 @@ func (app *App) getTargetData(ctx context.Context, target string, exp parser.Exp
 	return targetErr, size
 }
 
+// enqueueRenderRequests sends the sub-requests of the metric upstream from the given time.
+// The responses are read from the returned channel, its capacity is the number of sub-requests.
+func (app *App) enqueueRenderRequests(ctx context.Context, renderRequests []string, mfetch parser.MetricRequest,
+	from int32, toLog *carbonapipb.AccessLogDetails) chan RenderResponse {
+	renderRequestContext := ctx
+	subrequestCount := len(renderRequests)
+	if subrequestCount > 1 {
+		renderRequestContext = util.WithPriority(ctx, subrequestCount)
+	}
+	app.ms.UpstreamSubRenderNum.Observe(float64(subrequestCount))
+	rch := make(chan RenderResponse, len(renderRequests))
+	for _, m := range renderRequests {
+		// This blocks when the queue fills up, which is fine as the result read would block anyway.
+		//
+		// TODO: Maybe handle record drops when the queue is full.
+		req := &RenderReq{
+			Path:          m,
+			From:          from,
+			Until:         mfetch.Until,
+			MaxDataPoints: mfetch.MaxDataPoints,
+
+			Ctx:       renderRequestContext,
+			ToLog:     toLog,
+			StartTime: time.Now(),
+
+			Results: rch,
+		}
+
+		if subrequestCount > app.config.LargeReqSize {
+			app.slowQ <- req
+			app.ms.UpstreamEnqueuedRequests.WithLabelValues("slow").Inc()
+			app.ms.UpstreamRequestsInQueue.WithLabelValues("slow").Inc()
+		} else {
+			app.fastQ <- req
+			app.ms.UpstreamEnqueuedRequests.WithLabelValues("fast").Inc()
+			app.ms.UpstreamRequestsInQueue.WithLabelValues("fast").Inc()
+		}
+	}
+
+	return rch
+}
+
+// collectRenderResponses reads all the responses from the channel and closes it.
+func collectRenderResponses(rch chan RenderResponse) ([]*types.MetricData, []error) {
+	var data []*types.MetricData
+	errs := make([]error, 0)
+	for i := 0; i < cap(rch); i++ {
+		resp := <-rch
+		if resp.error != nil {
+			errs = append(errs, resp.error)
+			continue
+		}
+
+		data = append(data, resp.data...)
+	}
+	close(rch)
+
+	return data, errs
+}
+
 // returns non-nil error when errors result in an error
 // returns non-empty string when there are *some* errors, even when total err is nil
 // returned string can be used to indicate partial failure
@@ -582,7 +671,7 @@ func optimistFanIn(errs []error, n int, subj string) (error, string) {
 	return errors.New("all " + subj + " failed; merged errs: (" + errStr + ")"), errStr
 }
 
-func sendRenderRequest(app *App, ctx context.Context, path string, from, until int32,
+func sendRenderRequest(app *App, ctx context.Context, path string, from, until int32, maxDataPoints int64,
 	toLog *carbonapipb.AccessLogDetails, lg *zap.Logger) RenderResponse {
 
 	atomic.AddInt64(&toLog.ZipperRequests, 1)
@@ -593,11 +682,14 @@ func sendRenderRequest(app *App, ctx context.Context, path string, from, until i
 	app.ms.UpstreamRequests.WithLabelValues("render").Inc()
 	t0 := time.Now()
 	metrics, err = Render(app.TopLevelDomainCache, app.TopLevelDomainPrefixes, app.NotFoundWhenTLDCacheMiss, app.Backends,
-		app.ZipperConfig.RenderReplicaMismatchConfig, ctx, path, int64(from), int64(until), app.ZipperMetrics, lg)
+		app.ZipperConfig.RenderReplicaMismatchConfig, ctx, path, int64(from), int64(until), maxDataPoints, app.ZipperMetrics, lg)
 	app.ms.UpstreamDuration.WithLabelValues("render").Observe(time.Since(t0).Seconds())
 
 	metricData := make([]*types.MetricData, 0)
 	for i := range metrics {
+		if !app.config.StorageXFilesFactor {
+			metrics[i].XFilesFactor = 0
+		}
 		metricData = append(metricData, &types.MetricData{Metric: metrics[i]})
 	}
 
@@ -620,6 +712,8 @@ type renderForm struct {
 	cacheKey     string
 	cacheTimeout int32
 	qtz          string
+	// maxDataPoints limits the number of points per series in the response. Zero means no limit.
+	maxDataPoints int64
 }
 
 func (app *App) renderHandlerProcessForm(r *http.Request, accessLogDetails *carbonapipb.AccessLogDetails, logger *zap.Logger) (renderForm, error) {
@@ -650,6 +744,13 @@ func (app *App) renderHandlerProcessForm(r *http.Request, accessLogDetails *carb
 		res.format = pngFormat
 	}
 
+	if mdp := r.FormValue("maxDataPoints"); mdp != "" {
+		// graphite-web ignores invalid values, and so do we
+		if v, err := strconv.ParseInt(mdp, 10, 64); err == nil && v > 0 {
+			res.maxDataPoints = v
+		}
+	}
+
 	res.cacheTimeout = app.config.Cache.DefaultTimeoutSec
 
 	if tstr := r.FormValue("cacheTimeout"); tstr != "" {
@@ -704,16 +805,23 @@ func (app *App) renderHandlerProcessForm(r *http.Request, accessLogDetails *carb
 	return res, nil
 }
 
+func consolidateToMaxDataPoints(results []*types.MetricData, form renderForm) []*types.MetricData {
+	// the graphs are consolidated to their width instead
+	if form.maxDataPoints > 0 && form.format != pngFormat && form.format != svgFormat {
+		return types.Consolidate(int(form.maxDataPoints), results)
+	}
+
+	return results
+}
+
 func (app *App) renderWriteBody(results []*types.MetricData, form renderForm, r *http.Request, logger *zap.Logger) ([]byte, error) {
 	var body []byte
 	var err error
 
+	results = consolidateToMaxDataPoints(results, form)
+
 	switch form.format {
 	case jsonFormat:
-		if maxDataPoints, _ := strconv.Atoi(r.FormValue("maxDataPoints")); maxDataPoints > 0 {
-			results = types.ConsolidateJSON(maxDataPoints, results)
-		}
-
 		body = types.MarshalJSON(results)
 	case protobufFormat, protobuf3Format:
 		body, err = types.MarshalProtobuf(results)
@@ -723,19 +831,7 @@ func (app *App) renderWriteBody(results []*types.MetricData, form renderForm, r
 	case rawFormat:
 		body = types.MarshalRaw(results)
 	case csvFormat:
-		tz := app.defaultTimeZone
-		if form.qtz != "" {
-			var z *time.Location
-			z, err = time.LoadLocation(form.qtz)
-			if err != nil {
-				logger.Warn("Invalid time zone",
-					zap.String("tz", form.qtz),
-				)
-			} else {
-				tz = z
-			}
-		}
-		body = types.MarshalCSV(results, tz)
+		body = types.MarshalCSV(results, app.location(form, logger))
 	case pickleFormat:
 		body, err = types.MarshalPickle(results)
 		if err != nil {
@@ -834,6 +930,10 @@ func (app *App) getRenderRequests(ctx context.Context, m parser.MetricRequest, u
 	toLog *carbonapipb.AccessLogDetails, lg *zap.Logger) ([]string, error) {
 	Trace(lg, "getting sub-requests")
 
+	if m.IsSeriesByTag() {
+		return app.getTaggedRenderRequests(ctx, m, useCache, toLog, lg)
+	}
+
 	if app.config.ResolveGlobs == 0 {
 		return []string{m.Metric}, nil
 	}
@@ -872,6 +972,95 @@ func (app *App) getRenderRequests(ctx context.Context, m parser.MetricRequest, u
 	return renderRequests, nil
 }
 
+// resolveSeriesByTags finds the series matching the tag expressions. The result is cached
+// under the seriesByTag call, so that it is shared by /render and /tags/findSeries.
+func (app *App) resolveSeriesByTags(ctx context.Context, exprs []string, useCache bool, accessLogDetails *carbonapipb.AccessLogDetails, lg *zap.Logger) (dataTypes.Matches, bool, error) {
+	key := seriesByTagCall(exprs)
+	lg = lg.With(zap.String("tags_query", key))
+	Trace(lg, "executing tagged series find")
+
+	if useCache {
+		Trace(lg, "query cache for tagged series")
+		matches, err := app.resolveGlobsFromCache(key)
+		if err == nil {
+			Trace(lg, "tagged series found in cache")
+			return matches, true, nil
+		}
+		if err != cache.ErrNotFound {
+			Trace(lg, "cache error for tagged series", zap.Error(err))
+			addCacheErrorToLogDetails(accessLogDetails, true, err)
+		}
+		Trace(lg, "tagged series not found in cache")
+	}
+
+	accessLogDetails.ZipperRequests++
+
+	Trace(lg, "sending tagged series request upstream")
+	app.ms.UpstreamRequests.WithLabelValues("tags").Inc()
+	t0 := time.Now()
+	matches, err := FindSeriesByTags(app.Backends, ctx, exprs, app.ZipperMetrics, lg)
+	app.ms.UpstreamDuration.WithLabelValues("tags").Observe(time.Since(t0).Seconds())
+
+	if err != nil {
+		Trace(lg, "upstream tagged series request failed", zap.Error(err))
+		return matches, false, err
+	}
+
+	blob, err := carbonapi_v2.FindEncoder(matches)
+	if err == nil {
+		go func() {
+			errCache := app.findCache.Set(key, blob, app.config.Cache.DefaultTimeoutSec)
+			if errCache != nil {
+				Trace(lg, "writing tagged series to cache failed", zap.Error(errCache))
+			}
+		}()
+	} else {
+		Trace(lg, "encoding tagged series for caching failed", zap.Error(err))
+	}
+
+	return matches, false, nil
+}
+
+// seriesByTagCall formats the tag expressions as a seriesByTag call.
+func seriesByTagCall(exprs []string) string {
+	quoted := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		quoted = append(quoted, "'"+strings.Replace(e, "'", "\\'", -1)+"'")
+	}
+
+	return "seriesByTag(" + strings.Join(quoted, ",") + ")"
+}
+
+// getTaggedRenderRequests resolves a seriesByTag request into a render request per series.
+// Unlike globs, tag expressions are never sent to the backends as render targets.
+func (app *App) getTaggedRenderRequests(ctx context.Context, m parser.MetricRequest, useCache bool,
+	toLog *carbonapipb.AccessLogDetails, lg *zap.Logger) ([]string, error) {
+	exprs, err := m.TagExpressions()
+	if err != nil {
+		return nil, err
+	}
+
+	series, fromCache, err := app.resolveSeriesByTags(ctx, exprs, useCache, toLog, lg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same as for globs, populate the backend path caches with a preflight request.
+	if fromCache {
+		_, _, err := app.resolveSeriesByTags(ctx, exprs, false, toLog, lg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	renderRequests := make([]string, 0, len(series.Matches))
+	for _, s := range series.Matches {
+		renderRequests = append(renderRequests, s.Path)
+	}
+
+	return renderRequests, nil
+}
+
 func (app *App) findHandler(w http.ResponseWriter, r *http.Request, lg *zap.Logger) {
 	t0 := time.Now()
 	defer func() {
@@ -1516,6 +1705,64 @@ func (app *App) unblockHeaders(w http.ResponseWriter, r *http.Request, logger *z
 
 }
 
+// backendHealth is the health of a backend as shown by the backends debug handler.
+type backendHealth struct {
+	Address      string     `json:"address"`
+	DC           string     `json:"dc"`
+	Cluster      string     `json:"cluster"`
+	State        string     `json:"state"`
+	Requests     int        `json:"requests"`
+	Failures     int        `json:"failures"`
+	Ejections    int        `json:"ejections"`
+	EjectedUntil *time.Time `json:"ejectedUntil,omitempty"`
+}
+
+// It shows the health state of the backends
+func (app *App) backendsHandler(w http.ResponseWriter, r *http.Request, logger *zap.Logger) {
+	t0 := time.Now()
+	toLog := carbonapipb.NewAccessLogDetails(r, "backends", &app.config)
+	logLevel := zap.InfoLevel
+	defer func() {
+		app.deferredAccessLogging(logger, r, &toLog, t0, logLevel)
+	}()
+
+	backends := make([]backendHealth, 0, len(app.Backends))
+	for _, b := range app.Backends {
+		addr, cluster, dc := b.BackendInfo()
+		status := b.Health().Status()
+		bh := backendHealth{
+			Address:   addr,
+			DC:        dc,
+			Cluster:   cluster,
+			State:     status.State.String(),
+			Requests:  status.Requests,
+			Failures:  status.Failures,
+			Ejections: status.Ejections,
+		}
+		if status.State == backend.Ejected {
+			bh.EjectedUntil = &status.EjectedUntil
+		}
+		backends = append(backends, bh)
+	}
+
+	body, err := json.MarshalIndent(backends, "", "\t")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		toLog.HttpCode = http.StatusInternalServerError
+		toLog.Reason = err.Error()
+		logLevel = zapcore.ErrorLevel
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	_, err = w.Write(body)
+	toLog.HttpCode = http.StatusOK
+	if err != nil {
+		toLog.HttpCode = 499
+		logLevel = zapcore.WarnLevel
+	}
+}
+
 func logStepTimeMismatch(targetMetricFetches []parser.MetricRequest, metricMap map[parser.MetricRequest][]*types.MetricData, logger *zap.Logger, target string) {
 	var defaultStepTime int32 = -1
 	for _, mfetch := range targetMetricFetches {
@@ -1568,14 +1815,230 @@ func (app *App) usageHandler(w http.ResponseWriter, r *http.Request, logger *zap
 	}
 }
 
-// TODO : Fix this handler if and when tag support is added
-// This responds to grafana's tag requests, which were falling through to the usageHandler,
-// preventing a random, garbage list of tags (constructed from usageMsg) being added to the metrics list
-func (app *App) tagsHandler(w http.ResponseWriter, r *http.Request, logger *zap.Logger) {
+// parseTagsRequest builds the tags request from the /tags, /tags/<tag> or
+// /tags/autoComplete/{tags,values} request.
+func parseTagsRequest(r *http.Request) (dataTypes.TagsRequest, error) {
+	request := dataTypes.TagsRequest{
+		Exprs: r.Form["expr"],
+	}
+
+	if limit := r.FormValue("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 0 {
+			return request, fmt.Errorf("invalid limit '%s'", limit)
+		}
+		request.Limit = l
+	}
+
+	switch r.URL.Path {
+	case "/tags":
+		request.Type = dataTypes.TagNames
+		request.Filter = r.FormValue("filter")
+	case "/tags/autoComplete/tags":
+		request.Type = dataTypes.AutoCompleteTags
+		request.Prefix = r.FormValue("tagPrefix")
+	case "/tags/autoComplete/values":
+		request.Type = dataTypes.AutoCompleteValues
+		request.Tag = r.FormValue("tag")
+		request.Prefix = r.FormValue("valuePrefix")
+		if request.Tag == "" {
+			return request, errors.New("missing parameter `tag`")
+		}
+	default:
+		request.Type = dataTypes.TagValues
+		request.Tag = strings.TrimPrefix(r.URL.Path, "/tags/")
+		request.Filter = r.FormValue("filter")
+	}
+
+	return request, nil
+}
+
+func tagsCacheKey(request dataTypes.TagsRequest) string {
+	return fmt.Sprintf("tags:%d:%s:%s:%s:%s:%d", request.Type, request.Tag,
+		strings.Join(request.Exprs, ","), request.Filter, request.Prefix, request.Limit)
+}
+
+func encodeTags(request dataTypes.TagsRequest, values []dataTypes.TagValue) ([]byte, error) {
+	switch request.Type {
+	case dataTypes.TagNames:
+		return ourJson.TagNamesEncoder(values)
+	case dataTypes.TagValues:
+		return ourJson.TagValuesEncoder(request.Tag, values)
+	default:
+		return ourJson.AutoCompleteEncoder(values)
+	}
+}
+
+// tagsHandler serves the tag names and values of the backends, and their autocompletion.
+func (app *App) tagsHandler(w http.ResponseWriter, r *http.Request, lg *zap.Logger) {
+	t0 := time.Now()
+	defer func() {
+		app.ms.DurationTotal.WithLabelValues("tags").Observe(time.Since(t0).Seconds())
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), app.config.Timeouts.Global)
+	defer cancel()
+	uuid := util.GetUUID(ctx)
+
+	app.ms.Requests.Inc()
+
+	jsonp := r.FormValue("jsonp")
+	useCache := !parser.TruthyBool(r.FormValue("noCache"))
+
+	toLog := carbonapipb.NewAccessLogDetails(r, "tags", &app.config)
+
+	lg = lg.With(zap.String("request_id", uuid), zap.String("request_type", "tags"))
+	Trace(lg, "received request")
+
+	logLevel := zap.InfoLevel
+	defer func() {
+		app.deferredAccessLogging(lg, r, &toLog, t0, logLevel)
+	}()
+
+	err := r.ParseForm()
+	if err != nil {
+		writeError(uuid, r, w, http.StatusBadRequest, "error parsing form", "", &toLog)
+		return
+	}
+	request, err := parseTagsRequest(r)
+	if err != nil {
+		writeError(uuid, r, w, http.StatusBadRequest, err.Error(), "", &toLog)
+		return
+	}
+	toLog.Targets = request.Exprs
+
+	key := tagsCacheKey(request)
+	if useCache {
+		blob, err := app.findCache.Get(key)
+		if err == nil {
+			toLog.FromCache = true
+			if writeResponse(ctx, w, blob, jsonFormat, jsonp) != nil {
+				toLog.HttpCode = 499
+				logLevel = zapcore.WarnLevel
+				return
+			}
+			toLog.HttpCode = http.StatusOK
+			return
+		}
+		if err != cache.ErrNotFound {
+			addCacheErrorToLogDetails(&toLog, true, err)
+		}
+	}
+
+	toLog.ZipperRequests++
+	app.ms.UpstreamRequests.WithLabelValues("tags").Inc()
+	t1 := time.Now()
+	values, err := Tags(app.Backends, ctx, request, app.ZipperMetrics, lg)
+	app.ms.UpstreamDuration.WithLabelValues("tags").Observe(time.Since(t1).Seconds())
+	if err != nil {
+		var notFound dataTypes.ErrNotFound
+		switch {
+		case errors.As(err, &notFound):
+			// Grafana expects an empty list when nothing matches
+			Trace(lg, "not found")
+		case errors.Is(err, context.DeadlineExceeded):
+			writeError(uuid, r, w, http.StatusUnprocessableEntity, "context deadline exceeded", "", &toLog)
+			logLevel = zapcore.ErrorLevel
+			return
+		default:
+			writeError(uuid, r, w, http.StatusUnprocessableEntity, err.Error(), "", &toLog)
+			logLevel = zapcore.ErrorLevel
+			return
+		}
+	}
+	toLog.TotalMetricCount = int64(len(values))
+
+	blob, err := encodeTags(request, values)
+	if err != nil {
+		writeError(uuid, r, w, http.StatusInternalServerError, err.Error(), "", &toLog)
+		logLevel = zapcore.ErrorLevel
+		return
+	}
+
+	go func() {
+		errCache := app.findCache.Set(key, blob, app.config.Cache.DefaultTimeoutSec)
+		if errCache != nil {
+			Trace(lg, "writing tags to cache failed", zap.Error(errCache))
+		}
+	}()
+
+	if writeResponse(ctx, w, blob, jsonFormat, jsonp) != nil {
+		toLog.HttpCode = 499
+		logLevel = zapcore.WarnLevel
+		return
+	}
+	toLog.HttpCode = http.StatusOK
+}
+
+func (app *App) tagSeriesHandler(w http.ResponseWriter, r *http.Request, lg *zap.Logger) {
+	t0 := time.Now()
+	defer func() {
+		app.ms.DurationTotal.WithLabelValues("tags").Observe(time.Since(t0).Seconds())
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), app.config.Timeouts.Global)
+	defer cancel()
+	uuid := util.GetUUID(ctx)
+
 	app.ms.Requests.Inc()
+
+	jsonp := r.FormValue("jsonp")
+	useCache := !parser.TruthyBool(r.FormValue("noCache"))
+
+	toLog := carbonapipb.NewAccessLogDetails(r, "tags", &app.config)
+
+	lg = lg.With(zap.String("request_id", uuid), zap.String("request_type", "tags"))
+	Trace(lg, "received request")
+
+	logLevel := zap.InfoLevel
 	defer func() {
-		app.ms.Responses.WithLabelValues(strconv.Itoa(http.StatusOK), "tags", "false").Inc()
+		app.deferredAccessLogging(lg, r, &toLog, t0, logLevel)
 	}()
+
+	err := r.ParseForm()
+	if err != nil {
+		writeError(uuid, r, w, http.StatusBadRequest, "error parsing form", "", &toLog)
+		return
+	}
+	exprs := r.Form["expr"]
+	toLog.Targets = exprs
+	if len(exprs) == 0 {
+		writeError(uuid, r, w, http.StatusBadRequest, "missing parameter `expr`", "", &toLog)
+		return
+	}
+
+	series, fromCache, err := app.resolveSeriesByTags(ctx, exprs, useCache, &toLog, lg)
+	toLog.FromCache = fromCache
+	if err != nil {
+		var notFound dataTypes.ErrNotFound
+		switch {
+		case errors.As(err, &notFound):
+			Trace(lg, "not found")
+		case errors.Is(err, context.DeadlineExceeded):
+			writeError(uuid, r, w, http.StatusUnprocessableEntity, "context deadline exceeded", "", &toLog)
+			logLevel = zapcore.ErrorLevel
+			return
+		default:
+			writeError(uuid, r, w, http.StatusUnprocessableEntity, err.Error(), "", &toLog)
+			logLevel = zapcore.ErrorLevel
+			return
+		}
+	}
+	toLog.TotalMetricCount = int64(len(series.Matches))
+
+	blob, err := ourJson.TagSeriesEncoder(series)
+	if err != nil {
+		writeError(uuid, r, w, http.StatusInternalServerError, err.Error(), "", &toLog)
+		logLevel = zapcore.ErrorLevel
+		return
+	}
+
+	if writeResponse(ctx, w, blob, jsonFormat, jsonp) != nil {
+		toLog.HttpCode = 499
+		logLevel = zapcore.WarnLevel
+		return
+	}
+	toLog.HttpCode = http.StatusOK
 }
 
 func buildParseErrorString(target, e string, err error) string {
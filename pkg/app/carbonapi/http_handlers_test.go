@@ -1,10 +1,22 @@
 package carbonapi
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 
+	"github.com/bookingcom/carbonapi/pkg/backend"
+	"github.com/bookingcom/carbonapi/pkg/carbonapipb"
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
 	typ "github.com/bookingcom/carbonapi/pkg/types"
+	"github.com/dgryski/go-expirecache"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
 )
 
 func TestGetCompleterQuery(t *testing.T) {
@@ -215,3 +227,225 @@ func TestOptimistErrsFanIn(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTagsRequest(t *testing.T) {
+	tests := []struct {
+		url string
+		exp typ.TagsRequest
+		err bool
+	}{
+		{
+			url: "/tags?filter=^d&limit=10",
+			exp: typ.TagsRequest{Type: typ.TagNames, Filter: "^d", Limit: 10},
+		},
+		{
+			url: "/tags/dc",
+			exp: typ.TagsRequest{Type: typ.TagValues, Tag: "dc"},
+		},
+		{
+			url: "/tags/autoComplete/tags?expr=name=cpu&tagPrefix=d",
+			exp: typ.TagsRequest{Type: typ.AutoCompleteTags, Exprs: []string{"name=cpu"}, Prefix: "d"},
+		},
+		{
+			url: "/tags/autoComplete/values?tag=dc&expr=name=cpu&expr=env=prod&valuePrefix=am",
+			exp: typ.TagsRequest{Type: typ.AutoCompleteValues, Tag: "dc", Exprs: []string{"name=cpu", "env=prod"}, Prefix: "am"},
+		},
+		{
+			url: "/tags/autoComplete/values",
+			err: true,
+		},
+		{
+			url: "/tags?limit=x",
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.url, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := parseTagsRequest(r)
+			if tt.err {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.exp) {
+				t.Errorf("got %+v, expected %+v", got, tt.exp)
+			}
+		})
+	}
+}
+
+func TestSeriesByTagCall(t *testing.T) {
+	got := seriesByTagCall([]string{"name=cpu", "dc=~ams.*"})
+	exp := "seriesByTag('name=cpu','dc=~ams.*')"
+	if got != exp {
+		t.Errorf("got %s, expected %s", got, exp)
+	}
+}
+
+func TestRenderWriteBodyMaxDataPoints(t *testing.T) {
+	app := &App{}
+	r := httptest.NewRequest(http.MethodGet, "/render?target=foo&maxDataPoints=2&format=raw", nil)
+	form := renderForm{format: rawFormat, maxDataPoints: 2}
+
+	results := []*types.MetricData{
+		types.MakeMetricData("foo", []float64{1, 3, 5, 7}, 10, 100),
+	}
+
+	got, err := app.renderWriteBody(results, form, r, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "foo,100,140,20|2,6\n"
+	if string(got) != exp {
+		t.Errorf("got %q, expected %q", got, exp)
+	}
+}
+
+func TestRenderStreamBody(t *testing.T) {
+	app := &App{}
+	app.config.Cache.StreamedBodyLimitKB = 1
+
+	results := []*types.MetricData{
+		types.MakeMetricData("foo", []float64{1, 3, 5, 7}, 10, 100),
+	}
+
+	tests := []struct {
+		name   string
+		form   renderForm
+		body   string
+		cached string
+	}{
+		{
+			name:   "json",
+			form:   renderForm{format: jsonFormat},
+			body:   `[{"target":"foo","datapoints":[[1,100],[3,110],[5,120],[7,130]]}]`,
+			cached: `[{"target":"foo","datapoints":[[1,100],[3,110],[5,120],[7,130]]}]`,
+		},
+		{
+			name:   "jsonp",
+			form:   renderForm{format: jsonFormat, jsonp: "cb"},
+			body:   `cb([{"target":"foo","datapoints":[[1,100],[3,110],[5,120],[7,130]]}])`,
+			cached: `[{"target":"foo","datapoints":[[1,100],[3,110],[5,120],[7,130]]}]`,
+		},
+		{
+			name:   "raw with maxDataPoints",
+			form:   renderForm{format: rawFormat, maxDataPoints: 2},
+			body:   "foo,100,140,20|2,6\n",
+			cached: "foo,100,140,20|2,6\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			cached, err := app.renderStreamBody(context.Background(), w, results, tt.form, zap.NewNop())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w.Body.String() != tt.body {
+				t.Errorf("got body %q, expected %q", w.Body.String(), tt.body)
+			}
+			if string(cached) != tt.cached {
+				t.Errorf("got cached body %q, expected %q", cached, tt.cached)
+			}
+		})
+	}
+}
+
+func TestRenderStreamBodyOverLimit(t *testing.T) {
+	app := &App{}
+	app.config.Cache.StreamedBodyLimitKB = 1
+
+	results := []*types.MetricData{
+		types.MakeMetricData("foo", make([]float64, 1000), 10, 100),
+	}
+
+	w := httptest.NewRecorder()
+	cached, err := app.renderStreamBody(context.Background(), w, results, renderForm{format: jsonFormat}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached != nil {
+		t.Errorf("expected the body over the limit not to be cached, got %d bytes", len(cached))
+	}
+	if !bytes.Equal(w.Body.Bytes(), types.MarshalJSON(results)) {
+		t.Error("expected the full body to be written")
+	}
+}
+
+type xFilesFactorTestBackend struct {
+	backend.BackendImpl
+}
+
+func (b *xFilesFactorTestBackend) Render(ctx context.Context, request typ.RenderRequest) ([]typ.Metric, error) {
+	m := typ.Metric{
+		Name:         request.Targets[0],
+		StartTime:    request.From,
+		StopTime:     request.From + 30,
+		StepTime:     10,
+		Values:       []float64{1, 2, 3},
+		IsAbsent:     []bool{false, false, false},
+		XFilesFactor: 0.5,
+	}
+	return []typ.Metric{m}, nil
+}
+
+func (b *xFilesFactorTestBackend) Contains([]string) bool {
+	return true
+}
+
+func (b *xFilesFactorTestBackend) BackendInfo() (string, string, string) {
+	return "xff", "cluster", "dc"
+}
+
+func (b *xFilesFactorTestBackend) GetServerAddress() string {
+	return "http://xff:8080"
+}
+
+func TestSendRenderRequestXFilesFactor(t *testing.T) {
+	b := backend.NewBackend(&xFilesFactorTestBackend{}, 10, nil, nil, nil, nil, cfg.RenderBatch{},
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_requests_in_queue"}, []string{"request"}),
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_saturation"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_time_in_queue"}, []string{"request"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_enqueued_requests"}, []string{"request"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_backend_duration"}, []string{"request"}))
+
+	app := &App{
+		Backends:            []backend.Backend{b},
+		TopLevelDomainCache: expirecache.New(0),
+		ZipperMetrics:       NewZipperPrometheusMetrics(cfg.DefaultZipperConfig()),
+	}
+	app.ms.UpstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_upstream_requests"}, []string{"request"})
+	app.ms.UpstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_upstream_duration"}, []string{"request"})
+
+	for _, storage := range []bool{false, true} {
+		app.config.StorageXFilesFactor = storage
+		resp := sendRenderRequest(app, context.Background(), "foo", 100, 200, 0, &carbonapipb.AccessLogDetails{}, zap.NewNop())
+		if resp.error != nil {
+			t.Fatal(resp.error)
+		}
+		if len(resp.data) != 1 {
+			t.Fatalf("expected 1 series, got %d", len(resp.data))
+		}
+
+		var want float32
+		if storage {
+			want = 0.5
+		}
+		if got := resp.data[0].XFilesFactor; got != want {
+			t.Errorf("storageXFilesFactor %v: expected the xFilesFactor %v, got %v", storage, want, got)
+		}
+	}
+}
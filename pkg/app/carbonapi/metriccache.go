@@ -0,0 +1,162 @@
+package carbonapi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	"github.com/go-graphite/protocol/carbonapi_v3_pb"
+	"go.uber.org/zap"
+)
+
+// The metric cache sits below the query cache. It holds the fetched series of single metric requests,
+// so the targets shared between different render requests are not fetched from the backends again.
+
+func (app *App) metricCacheEnabled(useCache bool) bool {
+	return useCache && app.config.Cache.MetricCacheTimeoutSec > 0
+}
+
+// metricCacheKey aligns the time range of the request to the step of the series. The backends align
+// the time ranges the same way, so the requests for relative ranges issued within the same step get
+// the same points and share the cache entry. The maxDataPoints is part of the key, as the backends
+// may consolidate the series to it.
+func metricCacheKey(m parser.MetricRequest, step int32) string {
+	m.From -= m.From % step
+	m.Until -= m.Until % step
+
+	return fmt.Sprintf("metric:%s:%d:%d:%d:%d", m.Metric, m.From, m.Until, m.MaxDataPoints, step)
+}
+
+// metricStepKey is the key of the step of the series last fetched for the metric. The step depends
+// on the retention the time range falls into, so the length of the range is part of the key.
+func metricStepKey(m parser.MetricRequest) string {
+	return fmt.Sprintf("metricstep:%s:%d:%d", m.Metric, m.Until-m.From, m.MaxDataPoints)
+}
+
+// cacheStep is the step the cache keys of the series are aligned to. When the series have different
+// steps, the smallest one is used. It is 0 if there is no step to align to.
+func cacheStep(metrics []*types.MetricData) int32 {
+	var step int32
+	for _, m := range metrics {
+		if m.StepTime > 0 && (step == 0 || m.StepTime < step) {
+			step = m.StepTime
+		}
+	}
+
+	return step
+}
+
+// getCachedMetrics returns the cached series of the request. The ok result is false on a cache miss.
+func (app *App) getCachedMetrics(m parser.MetricRequest, lg *zap.Logger) ([]*types.MetricData, bool) {
+	stepBlob, err := app.metricCache.Get(metricStepKey(m))
+	if err != nil || len(stepBlob) != 4 {
+		app.ms.MetricCacheMisses.Inc()
+		return nil, false
+	}
+	step := int32(binary.BigEndian.Uint32(stepBlob))
+	if step <= 0 {
+		app.ms.MetricCacheMisses.Inc()
+		return nil, false
+	}
+
+	blob, err := app.metricCache.Get(metricCacheKey(m, step))
+	if err != nil || len(blob) == 0 {
+		app.ms.MetricCacheMisses.Inc()
+		return nil, false
+	}
+
+	metrics, err := unmarshalCachedMetrics(blob)
+	if err != nil {
+		lg.Warn("failed to decode cached metrics", zap.Error(err))
+		app.ms.MetricCacheMisses.Inc()
+		return nil, false
+	}
+	app.ms.MetricCacheHits.Inc()
+
+	return metrics, true
+}
+
+// setCachedMetrics encodes the series right away, as the functions may modify them in place later,
+// and stores them in the background.
+func (app *App) setCachedMetrics(m parser.MetricRequest, metrics []*types.MetricData, lg *zap.Logger) {
+	step := cacheStep(metrics)
+	if step <= 0 {
+		return
+	}
+	blob, err := marshalCachedMetrics(metrics)
+	if err != nil {
+		lg.Warn("failed to encode metrics for the cache", zap.Error(err))
+		return
+	}
+
+	key := metricCacheKey(m, step)
+	stepKey := metricStepKey(m)
+	stepBlob := make([]byte, 4)
+	binary.BigEndian.PutUint32(stepBlob, uint32(step))
+	go func() {
+		// the series are set first, so the step never points to a missing entry for long
+		err := app.metricCache.Set(key, blob, app.config.Cache.MetricCacheTimeoutSec)
+		if err == nil {
+			err = app.metricCache.Set(stepKey, stepBlob, app.config.Cache.MetricCacheTimeoutSec)
+		}
+		if err != nil {
+			lg.Info("metric cache set failed", zap.String("metric", m.Metric), zap.Error(err))
+		}
+	}()
+}
+
+// The cached series are stored in the carbonapi_v3_pb encoding, which keeps one float64 per point
+// and marks the absent points as NaN. It also keeps the xFilesFactor of the series.
+func marshalCachedMetrics(metrics []*types.MetricData) ([]byte, error) {
+	out := carbonapi_v3_pb.MultiFetchResponse{
+		Metrics: make([]*carbonapi_v3_pb.FetchResponse, len(metrics)),
+	}
+
+	for i, m := range metrics {
+		values := make([]float64, len(m.Values))
+		for j, v := range m.Values {
+			if j < len(m.IsAbsent) && m.IsAbsent[j] {
+				values[j] = math.NaN()
+			} else {
+				values[j] = v
+			}
+		}
+
+		out.Metrics[i] = &carbonapi_v3_pb.FetchResponse{
+			Name:           m.Name,
+			PathExpression: m.Name,
+			StartTime:      int64(m.StartTime),
+			StopTime:       int64(m.StopTime),
+			StepTime:       int64(m.StepTime),
+			XFilesFactor:   m.XFilesFactor,
+			Values:         values,
+		}
+	}
+
+	return out.MarshalVT()
+}
+
+func unmarshalCachedMetrics(blob []byte) ([]*types.MetricData, error) {
+	resp := carbonapi_v3_pb.MultiFetchResponse{}
+	if err := resp.UnmarshalVT(blob); err != nil {
+		return nil, err
+	}
+
+	metrics := make([]*types.MetricData, len(resp.Metrics))
+	for i, m := range resp.Metrics {
+		metrics[i] = types.New(m.Name, m.Values, make([]bool, len(m.Values)), int32(m.StepTime), int32(m.StartTime))
+		metrics[i].StopTime = int32(m.StopTime)
+		metrics[i].XFilesFactor = m.XFilesFactor
+
+		for j, v := range m.Values {
+			if math.IsNaN(v) {
+				metrics[i].Values[j] = 0
+				metrics[i].IsAbsent[j] = true
+			}
+		}
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,93 @@
+package carbonapi
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/cache"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+)
+
+func newMetricCacheTestApp() *App {
+	app := &App{metricCache: cache.NewExpireCache(0)}
+	app.config.Cache.MetricCacheTimeoutSec = 60
+	app.ms.MetricCacheHits = prometheus.NewCounter(prometheus.CounterOpts{Name: "hits"})
+	app.ms.MetricCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{Name: "misses"})
+
+	return app
+}
+
+func TestMetricCacheKey(t *testing.T) {
+	a := metricCacheKey(parser.MetricRequest{Metric: "foo", From: 1200, Until: 4810, MaxDataPoints: 10}, 60)
+	b := metricCacheKey(parser.MetricRequest{Metric: "foo", From: 1259, Until: 4859, MaxDataPoints: 10}, 60)
+	if a != b {
+		t.Errorf("expected the keys within one step to match, got %s and %s", a, b)
+	}
+
+	c := metricCacheKey(parser.MetricRequest{Metric: "foo", From: 1260, Until: 4860, MaxDataPoints: 10}, 60)
+	if a == c {
+		t.Errorf("expected the keys of different steps to differ, got %s", a)
+	}
+
+	d := metricCacheKey(parser.MetricRequest{Metric: "foo", From: 1200, Until: 4810, MaxDataPoints: 20}, 60)
+	if a == d {
+		t.Errorf("expected the keys of different maxDataPoints to differ, got %s", a)
+	}
+
+	e := metricCacheKey(parser.MetricRequest{Metric: "foo", From: 1200, Until: 4810, MaxDataPoints: 10}, 3600)
+	if a == e {
+		t.Errorf("expected the keys of different series steps to differ, got %s", a)
+	}
+}
+
+func TestMetricCacheGetSet(t *testing.T) {
+	app := newMetricCacheTestApp()
+	m := parser.MetricRequest{Metric: "foo.*", From: 100, Until: 130}
+	metrics := []*types.MetricData{
+		types.MakeMetricData("foo.bar", []float64{1, math.NaN(), 3}, 10, 100),
+		types.MakeMetricData("foo.baz", []float64{4, 5, 6}, 10, 100),
+	}
+	// the xFilesFactor from the /info of the series is cached with it
+	metrics[0].XFilesFactor = 0.5
+
+	if _, ok := app.getCachedMetrics(m, zap.NewNop()); ok {
+		t.Fatal("expected a miss on the empty cache")
+	}
+
+	app.setCachedMetrics(m, metrics, zap.NewNop())
+
+	var got []*types.MetricData
+	var ok bool
+	// the set happens in the background
+	for i := 0; i < 100 && !ok; i++ {
+		time.Sleep(time.Millisecond)
+		got, ok = app.getCachedMetrics(m, zap.NewNop())
+	}
+	if !ok {
+		t.Fatal("expected a hit after the set")
+	}
+
+	if !reflect.DeepEqual(got, metrics) {
+		t.Errorf("got %+v, expected %+v", got, metrics)
+	}
+	if hits := testutil.ToFloat64(app.ms.MetricCacheHits); hits != 1 {
+		t.Errorf("expected 1 hit, got %v", hits)
+	}
+	if misses := testutil.ToFloat64(app.ms.MetricCacheMisses); misses < 1 {
+		t.Errorf("expected misses to be counted, got %v", misses)
+	}
+
+	// the same range shifted within the step of the series gets the same points
+	if _, ok := app.getCachedMetrics(parser.MetricRequest{Metric: "foo.*", From: 109, Until: 139}, zap.NewNop()); !ok {
+		t.Error("expected a hit within the step of the series")
+	}
+	if _, ok := app.getCachedMetrics(parser.MetricRequest{Metric: "foo.*", From: 110, Until: 140}, zap.NewNop()); ok {
+		t.Error("expected a miss for the next step of the series")
+	}
+}
@@ -30,6 +30,7 @@ type PrometheusMetrics struct {
 	UpstreamRequests            *prometheus.CounterVec
 	UpstreamRequestsInQueue     *prometheus.GaugeVec
 	UpstreamSemaphoreSaturation prometheus.Gauge
+	UpstreamConcurrencyLimit    prometheus.Gauge
 	UpstreamEnqueuedRequests    *prometheus.CounterVec
 	UpstreamSubRenderNum        prometheus.Histogram
 
@@ -37,6 +38,15 @@ type PrometheusMetrics struct {
 	CacheRespRead *prometheus.CounterVec
 	CacheTimeouts *prometheus.CounterVec
 
+	RedisCacheRequests *prometheus.CounterVec
+	RedisCacheTimeouts *prometheus.CounterVec
+
+	MetricCacheHits   prometheus.Counter
+	MetricCacheMisses prometheus.Counter
+
+	DeltaCacheHits      prometheus.Counter
+	DeltaCacheFallbacks prometheus.Counter
+
 	Version *prometheus.GaugeVec
 }
 
@@ -55,6 +65,9 @@ type ZipperPrometheusMetrics struct {
 	BackendRequestsInQueue     *prometheus.GaugeVec
 	BackendSemaphoreSaturation prometheus.Gauge
 	BackendTimeInQSec          *prometheus.HistogramVec
+	BackendHealthState         *prometheus.GaugeVec
+	BackendFailureRatio        *prometheus.GaugeVec
+	BackendConcurrencyLimit    *prometheus.GaugeVec
 
 	TLDCacheProbeReqTotal prometheus.Counter
 	TLDCacheProbeErrors   prometheus.Counter
@@ -208,6 +221,10 @@ func newPrometheusMetrics(config cfg.API) PrometheusMetrics {
 			Name: "upstream_semaphore_saturation",
 			Help: "The number of requests put in the main queue semaphore. Needs to be compared to the semaphore size.",
 		}),
+		UpstreamConcurrencyLimit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "upstream_concurrency_limit",
+			Help: "The current adaptive limit of the concurrent requests of the main queue.",
+		}),
 		UpstreamEnqueuedRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "upstream_enqueued_requests",
 			Help: "The count of requests put into the queue.",
@@ -264,6 +281,44 @@ func newPrometheusMetrics(config cfg.API) PrometheusMetrics {
 			},
 			[]string{"request"},
 		),
+		RedisCacheRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "redis_cache_requests",
+				Help: "Counter of requests to the Redis cache",
+			},
+			[]string{"request", "operation", "status"},
+		),
+		RedisCacheTimeouts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "redis_cache_timeouts",
+				Help: "Counter of timed-out gets from the Redis cache",
+			},
+			[]string{"request"},
+		),
+		MetricCacheHits: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "metric_cache_hits",
+				Help: "Counter of metric requests served from the metric cache",
+			},
+		),
+		MetricCacheMisses: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "metric_cache_misses",
+				Help: "Counter of metric requests not found in the metric cache",
+			},
+		),
+		DeltaCacheHits: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "delta_cache_hits",
+				Help: "Counter of metric requests completed by fetching only the tail of the remembered window",
+			},
+		),
+		DeltaCacheFallbacks: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "delta_cache_fallbacks",
+				Help: "Counter of metric requests fetched in full again as the tail did not fit the remembered window",
+			},
+		),
 		Version: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "version",
@@ -296,6 +351,7 @@ func registerPrometheusMetrics(ms *PrometheusMetrics, zms *ZipperPrometheusMetri
 
 	prometheus.MustRegister(ms.UpstreamRequestsInQueue)
 	prometheus.MustRegister(ms.UpstreamSemaphoreSaturation)
+	prometheus.MustRegister(ms.UpstreamConcurrencyLimit)
 	prometheus.MustRegister(ms.UpstreamEnqueuedRequests)
 	prometheus.MustRegister(ms.UpstreamSubRenderNum)
 	prometheus.MustRegister(ms.UpstreamTimeInQSec)
@@ -304,6 +360,12 @@ func registerPrometheusMetrics(ms *PrometheusMetrics, zms *ZipperPrometheusMetri
 	prometheus.MustRegister(ms.CacheRequests)
 	prometheus.MustRegister(ms.CacheRespRead)
 	prometheus.MustRegister(ms.CacheTimeouts)
+	prometheus.MustRegister(ms.RedisCacheRequests)
+	prometheus.MustRegister(ms.RedisCacheTimeouts)
+	prometheus.MustRegister(ms.MetricCacheHits)
+	prometheus.MustRegister(ms.MetricCacheMisses)
+	prometheus.MustRegister(ms.DeltaCacheHits)
+	prometheus.MustRegister(ms.DeltaCacheFallbacks)
 
 	prometheus.MustRegister(ms.Version)
 
@@ -318,6 +380,9 @@ func registerPrometheusMetrics(ms *PrometheusMetrics, zms *ZipperPrometheusMetri
 	prometheus.MustRegister(zms.BackendRequestsInQueue)
 	prometheus.MustRegister(zms.BackendSemaphoreSaturation)
 	prometheus.MustRegister(zms.BackendTimeInQSec)
+	prometheus.MustRegister(zms.BackendHealthState)
+	prometheus.MustRegister(zms.BackendFailureRatio)
+	prometheus.MustRegister(zms.BackendConcurrencyLimit)
 	prometheus.MustRegister(zms.TLDCacheProbeErrors)
 	prometheus.MustRegister(zms.TLDCacheProbeReqTotal)
 	prometheus.MustRegister(zms.PathCacheFilteredRequests)
@@ -406,6 +471,27 @@ func NewZipperPrometheusMetrics(config cfg.Zipper) *ZipperPrometheusMetrics {
 			},
 			[]string{"request"},
 		),
+		BackendHealthState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "backend_health_state",
+				Help: "The health state of the backend: 0 healthy, 1 half-open, 2 ejected.",
+			},
+			[]string{"dc", "cluster", "backend"},
+		),
+		BackendFailureRatio: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "backend_failure_ratio",
+				Help: "The ratio of failed or slow requests to the backend in the current health window.",
+			},
+			[]string{"dc", "cluster", "backend"},
+		),
+		BackendConcurrencyLimit: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "backend_concurrency_limit",
+				Help: "The current adaptive limit of the concurrent requests to the backend.",
+			},
+			[]string{"dc", "cluster", "backend"},
+		),
 
 		TLDCacheProbeReqTotal: prometheus.NewCounter(
 			prometheus.CounterOpts{
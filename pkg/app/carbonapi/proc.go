@@ -2,23 +2,25 @@ package carbonapi
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/bookingcom/carbonapi/pkg/carbonapipb"
+	"github.com/bookingcom/carbonapi/pkg/limiter"
 	"go.uber.org/zap"
 )
 
 // ProcessRequests processes the queued requests.
 func ProcessRequests(app *App, lg *zap.Logger) {
-	// semaphore does what semaphores do: It limits the number of concurrent requests.
-	semaphore := make(chan bool, app.config.MaxConcurrentUpstreamRequests)
+	// lim limits the number of concurrent requests, and lowers the limit when they slow down if it is adaptive.
+	lim := limiter.New(app.config.AdaptiveLimit, app.config.MaxConcurrentUpstreamRequests, app.ms.UpstreamConcurrencyLimit)
 	for i := 0; i < app.config.ProcWorkers; i++ {
 		go func() {
 			for {
 				var req *RenderReq
 				var label string
 
-				// During processing we use two independent queues that share the semaphore:
+				// During processing we use two independent queues that share the limiter:
 				// fastQ includes regular requests while slowQ contains large requests.
 				//
 				// Large requests could stampede a queue for a long time if we only had a single one. This would prevent any new requests
@@ -44,16 +46,18 @@ func ProcessRequests(app *App, lg *zap.Logger) {
 				default:
 				}
 
-				semaphore <- true
+				lim.Acquire()
 				app.ms.UpstreamSemaphoreSaturation.Inc()
 				app.ms.UpstreamTimeInQSec.WithLabelValues(label).Observe(float64(time.Since(req.StartTime).Seconds()))
 
-				go func(r *RenderReq) {
-					r.Results <- sendRenderRequest(app, r.Ctx, r.Path, r.From, r.Until, r.ToLog, lg)
+				go func(r *RenderReq, label string) {
+					resp := sendRenderRequest(app, r.Ctx, r.Path, r.From, r.Until, r.MaxDataPoints, r.ToLog, lg)
+					r.Results <- resp
 
-					<-semaphore
+					// the latency includes the wait in the queue, the small and the large requests are judged apart
+					lim.Release(label, time.Since(r.StartTime), errors.Is(r.Ctx.Err(), context.DeadlineExceeded))
 					app.ms.UpstreamSemaphoreSaturation.Dec()
-				}(req)
+				}(req, label)
 			}
 		}()
 	}
@@ -61,9 +65,10 @@ func ProcessRequests(app *App, lg *zap.Logger) {
 
 // RenderReq represents a render requests in the processing queue.
 type RenderReq struct {
-	Path  string
-	From  int32
-	Until int32
+	Path          string
+	From          int32
+	Until         int32
+	MaxDataPoints int64
 
 	Ctx       context.Context
 	ToLog     *carbonapipb.AccessLogDetails
@@ -29,6 +29,8 @@ func initHandlersInternal(app *App, logger *zap.Logger) http.Handler {
 	r.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	r.HandleFunc("/debug/pprof/trace", pprof.Trace)
 
+	r.HandleFunc("/debug/backends", handlerlog.WithLogger(app.backendsHandler, logger))
+
 	return removeTrailingSlash(r)
 }
 
@@ -48,7 +50,11 @@ func initHandlers(app *App, lg *zap.Logger) http.Handler {
 	r.HandleFunc("/lb_check", handlerlog.WithLogger(app.lbcheckHandler, lg))
 	r.HandleFunc("/version", handlerlog.WithLogger(app.versionHandler, lg))
 	r.HandleFunc("/functions", handlerlog.WithLogger(app.functionsHandler, lg))
-	r.HandleFunc("/tags/autoComplete/tags", handlerlog.WithLogger(app.tagsHandler, lg))
+	r.HandleFunc("/tags", app.validateRequest(app.tagsHandler, "tags", lg))
+	r.HandleFunc("/tags/findSeries", app.validateRequest(app.tagSeriesHandler, "tags", lg))
+	r.HandleFunc("/tags/autoComplete/tags", app.validateRequest(app.tagsHandler, "tags", lg))
+	r.HandleFunc("/tags/autoComplete/values", app.validateRequest(app.tagsHandler, "tags", lg))
+	r.HandleFunc("/tags/{tag}", app.validateRequest(app.tagsHandler, "tags", lg))
 	r.HandleFunc("/", handlerlog.WithLogger(app.usageHandler, lg))
 
 	r.NotFoundHandler = handlerlog.WithLogger(app.usageHandler, lg)
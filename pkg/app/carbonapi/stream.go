@@ -0,0 +1,111 @@
+package carbonapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/util"
+	"go.uber.org/zap"
+)
+
+// isStreamedFormat reports whether the render responses of the format are written to the client
+// series by series, instead of being marshalled in memory first.
+func isStreamedFormat(format string) bool {
+	return format == jsonFormat || format == csvFormat || format == rawFormat
+}
+
+// renderStreamBody writes the results to w as they are encoded.
+// The body is collected for the query cache on the side, unless it grows over the configured limit,
+// in which case the returned body is nil.
+func (app *App) renderStreamBody(ctx context.Context, w http.ResponseWriter, results []*types.MetricData,
+	form renderForm, logger *zap.Logger) ([]byte, error) {
+	results = consolidateToMaxDataPoints(results, form)
+
+	tee := &boundedBuffer{limit: app.config.Cache.StreamedBodyLimitKB * 1024}
+	out := io.MultiWriter(w, tee)
+
+	w.Header().Set("X-Carbonapi-UUID", util.GetUUID(ctx))
+	var err error
+	switch form.format {
+	case jsonFormat:
+		if form.jsonp != "" {
+			// the query cache stores the body without the callback, see writeResponse
+			w.Header().Set("Content-Type", contentTypeJavaScript)
+			if _, err = w.Write([]byte(form.jsonp + "(")); err != nil {
+				return nil, err
+			}
+			if err = types.WriteJSON(out, results); err != nil {
+				return nil, err
+			}
+			if _, err = w.Write([]byte{')'}); err != nil {
+				return nil, err
+			}
+		} else {
+			w.Header().Set("Content-Type", contentTypeJSON)
+			err = types.WriteJSON(out, results)
+		}
+	case rawFormat:
+		w.Header().Set("Content-Type", contentTypeRaw)
+		err = types.WriteRaw(out, results)
+	case csvFormat:
+		w.Header().Set("Content-Type", contentTypeCSV)
+		err = types.WriteCSV(out, results, app.location(form, logger))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tee.Bytes(), nil
+}
+
+// location returns the time zone of the request, the default one if tz is not set or invalid.
+func (app *App) location(form renderForm, logger *zap.Logger) *time.Location {
+	if form.qtz == "" {
+		return app.defaultTimeZone
+	}
+
+	z, err := time.LoadLocation(form.qtz)
+	if err != nil {
+		logger.Warn("Invalid time zone",
+			zap.String("tz", form.qtz),
+		)
+		return app.defaultTimeZone
+	}
+
+	return z
+}
+
+// boundedBuffer collects the written bytes up to the limit.
+// Once the limit is exceeded the collected bytes are dropped and the following writes are ignored.
+// The writes never fail, so the buffer can be used as a tee in an io.MultiWriter.
+type boundedBuffer struct {
+	buf      []byte
+	limit    int
+	overflow bool
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	if b.overflow {
+		return len(p), nil
+	}
+	if len(b.buf)+len(p) > b.limit {
+		b.overflow = true
+		b.buf = nil
+		return len(p), nil
+	}
+	b.buf = append(b.buf, p...)
+
+	return len(p), nil
+}
+
+// Bytes returns the collected bytes, or nil if the limit was exceeded.
+func (b *boundedBuffer) Bytes() []byte {
+	if b.overflow {
+		return nil
+	}
+
+	return b.buf
+}
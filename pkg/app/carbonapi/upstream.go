@@ -52,11 +52,45 @@ func Find(cache *expirecache.Cache, TLDPrefixes []tldcache.TopLevelDomainPrefix,
 	return metrics, nil
 }
 
+// FindSeriesByTags executes the tagged series find request by sending it to the healthy backends.
+// Tagged series are not part of the top-level domain tree, so no other backends are filtered out.
+func FindSeriesByTags(backends []backend.Backend, ctx context.Context,
+	exprs []string, ms *ZipperPrometheusMetrics, lg *zap.Logger) (types.Matches, error) {
+	request := types.NewTagSeriesRequest(exprs)
+	bs := backend.FilterHealthy(backends)
+	metrics, errs := backend.SeriesByTags(ctx, bs, request)
+	err := errorsFanIn(errs, len(bs))
+
+	if err != nil {
+		var notFound types.ErrNotFound
+		if !errors.As(err, &notFound) {
+			return metrics, err
+		}
+	}
+
+	sort.Slice(metrics.Matches, func(i, j int) bool {
+		return metrics.Matches[i].Path < metrics.Matches[j].Path
+	})
+
+	return metrics, nil
+}
+
+// Tags executes the tag names or values request by sending it to the healthy backends.
+func Tags(backends []backend.Backend, ctx context.Context,
+	request types.TagsRequest, ms *ZipperPrometheusMetrics, lg *zap.Logger) ([]types.TagValue, error) {
+	bs := backend.FilterHealthy(backends)
+	values, errs := backend.Tags(ctx, bs, request)
+	err := errorsFanIn(errs, len(bs))
+
+	return values, err
+}
+
 // Render executes the render request by checking cache and sending it to the backends.
 func Render(cache *expirecache.Cache, TLDPrefixes []tldcache.TopLevelDomainPrefix, NotFoundWhenTLDCacheMiss bool, backends []backend.Backend, mismatchConfig cfg.RenderReplicaMismatchConfig, ctx context.Context,
-	target string, from int64, until int64, ms *ZipperPrometheusMetrics, lg *zap.Logger) ([]types.Metric, error) {
+	target string, from int64, until int64, maxDataPoints int64, ms *ZipperPrometheusMetrics, lg *zap.Logger) ([]types.Metric, error) {
 
 	request := types.NewRenderRequest([]string{target}, int32(from), int32(until))
+	request.MaxDataPoints = maxDataPoints
 	bs, tldCacheMiss := tldcache.FilterBackendByTopLevelDomain(cache, TLDPrefixes, backends, request.Targets)
 	if NotFoundWhenTLDCacheMiss && tldCacheMiss {
 		return nil, types.ErrNotFound(fmt.Sprintf(
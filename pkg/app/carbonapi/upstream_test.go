@@ -0,0 +1,82 @@
+package carbonapi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/backend"
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/bookingcom/carbonapi/pkg/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+type tagsTestBackend struct {
+	backend.BackendImpl
+
+	addr  string
+	calls int32
+}
+
+func (b *tagsTestBackend) FindSeriesByTags(ctx context.Context, request types.TagSeriesRequest) (types.Matches, error) {
+	atomic.AddInt32(&b.calls, 1)
+	return types.Matches{Name: request.Exprs[0], Matches: []types.Match{{Path: "cpu;dc=ams", IsLeaf: true}}}, nil
+}
+
+func (b *tagsTestBackend) Tags(ctx context.Context, request types.TagsRequest) ([]types.TagValue, error) {
+	atomic.AddInt32(&b.calls, 1)
+	return []types.TagValue{{Value: "dc", Count: 1}}, nil
+}
+
+func (b *tagsTestBackend) BackendInfo() (string, string, string) {
+	return b.addr, "cluster", "dc"
+}
+
+func (b *tagsTestBackend) GetServerAddress() string {
+	return "http://" + b.addr + ":8080"
+}
+
+func newTagsTestBackend(impl *tagsTestBackend, health *backend.Health) backend.Backend {
+	return backend.NewBackend(impl, 10, nil, health, nil, nil, cfg.RenderBatch{},
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_requests_in_queue"}, []string{"request"}),
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_saturation"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_time_in_queue"}, []string{"request"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_enqueued_requests"}, []string{"request"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_backend_duration"}, []string{"request"}))
+}
+
+func TestTagRequestsSkipEjectedBackends(t *testing.T) {
+	ejected := backend.NewHealth(cfg.BackendHealth{
+		Enabled:         true,
+		Window:          time.Minute,
+		MinRequests:     1,
+		MaxFailureRatio: 0.5,
+		EjectionTime:    time.Minute,
+		MaxEjectionTime: time.Minute,
+	}, nil, nil)
+	ejected.Record(time.Millisecond, errors.New("failed"), false)
+	if ejected.Available() {
+		t.Fatal("expected the backend to be ejected")
+	}
+
+	healthy := &tagsTestBackend{addr: "healthy"}
+	unhealthy := &tagsTestBackend{addr: "ejected"}
+	backends := []backend.Backend{newTagsTestBackend(healthy, nil), newTagsTestBackend(unhealthy, ejected)}
+
+	if _, err := FindSeriesByTags(backends, context.Background(), []string{"name=cpu"}, nil, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Tags(backends, context.Background(), types.TagsRequest{Type: types.TagNames}, nil, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls := atomic.LoadInt32(&healthy.calls); calls != 2 {
+		t.Errorf("expected 2 calls to the healthy backend, got %d", calls)
+	}
+	if calls := atomic.LoadInt32(&unhealthy.calls); calls != 0 {
+		t.Errorf("expected no calls to the ejected backend, got %d", calls)
+	}
+}
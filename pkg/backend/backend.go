@@ -2,8 +2,11 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/bookingcom/carbonapi/pkg/limiter"
 	"github.com/bookingcom/carbonapi/pkg/types"
 	"github.com/bookingcom/carbonapi/pkg/util"
 	"github.com/prometheus/client_golang/prometheus"
@@ -23,10 +26,23 @@ type Backend struct {
 	slowRenderQ chan *renderReq
 	findQ       chan *findReq
 	infoQ       chan *infoReq // the info requests are expected to be relatively rare
+	tagQ        chan *tagSeriesReq
+	tagsQ       chan *tagsReq
 
-	// The size of the requests semaphore:
-	// The maximum number of simultaneous requests.
-	semaSize int
+	// The limit of the simultaneous requests, which adapts to their latency.
+	limiter *limiter.Limiter
+
+	// The health of the backend, nil when it is not tracked.
+	health *Health
+
+	// The hedger of the render requests to the cluster of the backend, nil when they are not hedged.
+	hedger *Hedger
+
+	// The route of the backend in the hash ring of its cluster, nil when the cluster is not routed.
+	route *Route
+
+	// The bounds of the batches of render requests.
+	batch cfg.RenderBatch
 
 	requestsInQueue  *prometheus.GaugeVec
 	saturation       prometheus.Gauge
@@ -44,6 +60,8 @@ type BackendImpl interface {
 	Find(context.Context, types.FindRequest) (types.Matches, error)
 	Info(context.Context, types.InfoRequest) ([]types.Info, error)
 	Render(context.Context, types.RenderRequest) ([]types.Metric, error)
+	FindSeriesByTags(context.Context, types.TagSeriesRequest) (types.Matches, error)
+	Tags(context.Context, types.TagsRequest) ([]types.TagValue, error)
 
 	Contains([]string) bool // Reports whether a backend contains any of the given targets.
 	Logger() *zap.Logger    // A logger used to communicate non-fatal warnings.
@@ -56,6 +74,8 @@ type renderReq struct {
 
 	Ctx       context.Context
 	StartTime time.Time
+	// Slow is set on the requests of the slow queue, which yield to the fast ones.
+	Slow bool
 
 	Results chan []types.Metric
 	Errors  chan error
@@ -81,8 +101,28 @@ type infoReq struct {
 	Errors  chan error
 }
 
+type tagSeriesReq struct {
+	types.TagSeriesRequest
+
+	Ctx       context.Context
+	StartTime time.Time
+
+	Results chan types.Matches
+	Errors  chan error
+}
+
+type tagsReq struct {
+	types.TagsRequest
+
+	Ctx       context.Context
+	StartTime time.Time
+
+	Results chan []types.TagValue
+	Errors  chan error
+}
+
 // Creates a new backend and starts processing the queues
-func NewBackend(impl BackendImpl, qSize int, semaSize int,
+func NewBackend(impl BackendImpl, qSize int, limiter *limiter.Limiter, health *Health, hedger *Hedger, route *Route, batch cfg.RenderBatch,
 	requestsInQueue *prometheus.GaugeVec,
 	saturation prometheus.Gauge,
 	timeInQSec *prometheus.HistogramVec,
@@ -95,7 +135,13 @@ func NewBackend(impl BackendImpl, qSize int, semaSize int,
 		slowRenderQ: make(chan *renderReq, qSize),
 		findQ:       make(chan *findReq, qSize),
 		infoQ:       make(chan *infoReq, qSize),
-		semaSize:    semaSize,
+		tagQ:        make(chan *tagSeriesReq, qSize),
+		tagsQ:       make(chan *tagsReq, qSize),
+		limiter:     limiter,
+		health:      health,
+		hedger:      hedger,
+		route:       route,
+		batch:       batch,
 
 		requestsInQueue:  requestsInQueue,
 		saturation:       saturation,
@@ -113,91 +159,154 @@ func NewBackend(impl BackendImpl, qSize int, semaSize int,
 // Should not be called when async processing is disabled.
 // Expects the metrics to be non-nil.
 func (b *Backend) Proc() {
-	semaphore := make(chan bool, b.semaSize)
-
 	// The duplication below is the simplest solution at the moment without adding dynamic typing.
 	// After https://github.com/golang/go/issues/48522 is closed, we can use generics to avoid duplication.
 	// Without that issue resolved, the use of generics would produce too much boilerplate and would look much worse than
 	// the solution below — I've tried.
 	go func() {
+		requestLabel := "render"
+		// the requests taken from the queues while batching, which did not fit in the batch
+		var held []*renderReq
 		for {
 			var r *renderReq
-			select {
-			case r = <-b.fastRenderQ:
-			default:
-				select {
-				case r = <-b.fastRenderQ:
-				case r = <-b.slowRenderQ:
-				}
-			}
+			r, held = b.nextRender(held)
 
-			requestLabel := "render"
-			b.requestsInQueue.WithLabelValues(requestLabel).Dec()
 			select {
 			case <-r.Ctx.Done():
 				r.Errors <- r.Ctx.Err()
 				continue
 			default:
 			}
-			semaphore <- true
+			b.limiter.Acquire()
 			b.saturation.Inc()
-			b.timeInQSec.WithLabelValues(requestLabel).Observe(float64(time.Since(r.StartTime)))
-			go func(req *renderReq) {
-				t := prometheus.NewTimer(b.backendDuration.WithLabelValues("render"))
-				res, err := b.BackendImpl.Render(req.Ctx, req.RenderRequest)
-				t.ObserveDuration()
+			queued := time.Since(r.StartTime)
+			probe := b.health.Dispatch()
 
-				if err != nil {
-					req.Errors <- err
-				} else {
-					b.addSourceMetaToMetrics(res)
-					req.Results <- res
+			// the requests queued while waiting for the limiter are batched with this one
+			var batch []*renderReq
+			batch, held = b.batchRenders(r, held)
+			for _, req := range batch {
+				b.timeInQSec.WithLabelValues(requestLabel).Observe(float64(time.Since(req.StartTime)))
+			}
+			go func(batch []*renderReq) {
+				var latency time.Duration
+				var err error
+				targets := 0
+				b.renderBatch(batch, func(ctx context.Context, request types.RenderRequest) ([]types.Metric, error) {
+					t := prometheus.NewTimer(b.backendDuration.WithLabelValues(requestLabel))
+					res, e := b.BackendImpl.Render(ctx, request)
+					latency += t.ObserveDuration()
+					targets += len(request.Targets)
+					if err == nil {
+						err = e
+					}
+					return res, e
+				})
+				// the latency of a batch grows with its targets, the health and the limiter judge it per target
+				if targets > 1 {
+					latency /= time.Duration(targets)
 				}
-				<-semaphore
-				b.saturation.Dec()
-			}(r)
+				b.release(requestLabel, queued, latency, err, probe)
+			}(batch)
 		}
 	}()
 	go func() {
 		for r := range b.findQ {
 			requestLabel := "find"
 			b.requestsInQueue.WithLabelValues(requestLabel).Dec()
-			semaphore <- true
+			b.limiter.Acquire()
 			b.saturation.Inc()
-			b.timeInQSec.WithLabelValues(requestLabel).Observe(float64(time.Since(r.StartTime)))
+			queued := time.Since(r.StartTime)
+			probe := b.health.Dispatch()
+			b.timeInQSec.WithLabelValues(requestLabel).Observe(float64(queued))
 			go func(req *findReq) {
 				t := prometheus.NewTimer(b.backendDuration.WithLabelValues(requestLabel))
 				res, err := b.BackendImpl.Find(req.Ctx, req.FindRequest)
-				t.ObserveDuration()
+				latency := t.ObserveDuration()
 				if err != nil {
 					req.Errors <- err
 				} else {
 					req.Results <- res
 				}
-				<-semaphore
-				b.saturation.Dec()
+				b.release(requestLabel, queued, latency, err, probe)
 			}(r)
 		}
 	}()
 	go func() {
 		for r := range b.infoQ {
 			b.requestsInQueue.WithLabelValues("info").Dec()
-			semaphore <- true
+			b.limiter.Acquire()
 			b.saturation.Inc()
+			queued := time.Since(r.StartTime)
+			probe := b.health.Dispatch()
 			// not adding time in queue histogram for info requests to reduce the number of exposed metrics
 			go func(req *infoReq) {
 				// not adding duration histogram for info requests to reduce the number of exposed metrics
+				start := time.Now()
 				res, err := b.BackendImpl.Info(req.Ctx, req.InfoRequest)
+				latency := time.Since(start)
 				if err != nil {
 					req.Errors <- err
 				} else {
 					req.Results <- res
 				}
-				<-semaphore
-				b.saturation.Dec()
+				b.release("info", queued, latency, err, probe)
 			}(r)
 		}
 	}()
+	go func() {
+		for r := range b.tagQ {
+			requestLabel := "tags"
+			b.requestsInQueue.WithLabelValues(requestLabel).Dec()
+			b.limiter.Acquire()
+			b.saturation.Inc()
+			queued := time.Since(r.StartTime)
+			probe := b.health.Dispatch()
+			b.timeInQSec.WithLabelValues(requestLabel).Observe(float64(queued))
+			go func(req *tagSeriesReq) {
+				t := prometheus.NewTimer(b.backendDuration.WithLabelValues(requestLabel))
+				res, err := b.BackendImpl.FindSeriesByTags(req.Ctx, req.TagSeriesRequest)
+				latency := t.ObserveDuration()
+				if err != nil {
+					req.Errors <- err
+				} else {
+					req.Results <- res
+				}
+				b.release(requestLabel, queued, latency, err, probe)
+			}(r)
+		}
+	}()
+	go func() {
+		for r := range b.tagsQ {
+			b.requestsInQueue.WithLabelValues("tag_values").Dec()
+			b.limiter.Acquire()
+			b.saturation.Inc()
+			queued := time.Since(r.StartTime)
+			probe := b.health.Dispatch()
+			// not adding time in queue histogram for tag value requests to reduce the number of exposed metrics
+			go func(req *tagsReq) {
+				// not adding duration histogram for tag value requests to reduce the number of exposed metrics
+				start := time.Now()
+				res, err := b.BackendImpl.Tags(req.Ctx, req.TagsRequest)
+				latency := time.Since(start)
+				if err != nil {
+					req.Errors <- err
+				} else {
+					req.Results <- res
+				}
+				b.release("tag_values", queued, latency, err, probe)
+			}(r)
+		}
+	}()
+}
+
+// release records the outcome of a request of the kind acquired from the limiter, and releases it.
+// The health judges the latency of the backend, the limiter also the time the request waited in the queue.
+// probe is whether the health dispatched the request as the probe of the backend.
+func (b *Backend) release(kind string, queued, latency time.Duration, err error, probe bool) {
+	b.health.Record(latency, err, probe)
+	b.limiter.Release(kind, queued+latency, errors.Is(err, context.DeadlineExceeded))
+	b.saturation.Dec()
 }
 
 // The duplication below is the simplest solution at the moment without adding dynamic typing.
@@ -219,6 +328,7 @@ func (backend Backend) SendRender(ctx context.Context, request types.RenderReque
 			RenderRequest: request,
 			Ctx:           ctx,
 			StartTime:     time.Now(),
+			Slow:          true,
 			Results:       msgCh,
 			Errors:        errCh,
 		}
@@ -252,6 +362,35 @@ func (backend Backend) SendInfo(ctx context.Context, request types.InfoRequest,
 	backend.enqueuedRequests.WithLabelValues("info").Inc()
 }
 
+func (backend Backend) SendFindSeriesByTags(ctx context.Context, request types.TagSeriesRequest, msgCh chan types.Matches, errCh chan error) {
+	backend.tagQ <- &tagSeriesReq{
+		TagSeriesRequest: request,
+		Ctx:              ctx,
+		StartTime:        time.Now(),
+		Results:          msgCh,
+		Errors:           errCh,
+	}
+	backend.requestsInQueue.WithLabelValues("tags").Inc()
+	backend.enqueuedRequests.WithLabelValues("tags").Inc()
+}
+
+func (backend Backend) SendTags(ctx context.Context, request types.TagsRequest, msgCh chan []types.TagValue, errCh chan error) {
+	backend.tagsQ <- &tagsReq{
+		TagsRequest: request,
+		Ctx:         ctx,
+		StartTime:   time.Now(),
+		Results:     msgCh,
+		Errors:      errCh,
+	}
+	backend.requestsInQueue.WithLabelValues("tag_values").Inc()
+	backend.enqueuedRequests.WithLabelValues("tag_values").Inc()
+}
+
+// Health returns the health of the backend, nil when it is not tracked.
+func (backend Backend) Health() *Health {
+	return backend.health
+}
+
 func (backend Backend) addSourceMetaToMetrics(metrics []types.Metric) {
 	_, cluster, _ := backend.BackendInfo()
 	for i := range metrics {
@@ -0,0 +1,223 @@
+package backend
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/types"
+	"github.com/bookingcom/carbonapi/pkg/util"
+)
+
+// The length of the render URL besides the address and the targets, with the longest time range.
+const renderURLBaseLength = len("/render/?format=protobuf&from=-2147483648&until=-2147483648")
+
+// nextRender takes the next render request to send. The fast requests go first, whether held
+// or queued, the slow ones only when there are no fast ones waiting.
+func (b *Backend) nextRender(held []*renderReq) (*renderReq, []*renderReq) {
+	for i, r := range held {
+		if !r.Slow {
+			return r, append(held[:i:i], held[i+1:]...)
+		}
+	}
+
+	select {
+	case r := <-b.fastRenderQ:
+		b.requestsInQueue.WithLabelValues("render").Dec()
+		return r, held
+	default:
+	}
+	if len(held) > 0 {
+		return held[0], held[1:]
+	}
+
+	var r *renderReq
+	select {
+	case r = <-b.fastRenderQ:
+	case r = <-b.slowRenderQ:
+	}
+	b.requestsInQueue.WithLabelValues("render").Dec()
+	return r, held
+}
+
+// batchRenders returns the render requests to send along with the given one as a single request,
+// and the requests held back for the next ones.
+// The held requests are batched first to keep them in order, the queued ones are then taken
+// while the batch and the held requests are not full, the fast queue before the slow one.
+func (b *Backend) batchRenders(r *renderReq, held []*renderReq) ([]*renderReq, []*renderReq) {
+	batch := []*renderReq{r}
+	if b.batch.MaxTargets <= 1 || !batchable(r) {
+		return batch, held
+	}
+
+	targets := len(r.Targets)
+	urlLength := len(b.GetServerAddress()) + renderURLBaseLength + targetsURLLength(r.Targets)
+	add := func(req *renderReq) bool {
+		if !sameRender(req, r) || req.From != r.From || req.Until != r.Until || req.MaxDataPoints != r.MaxDataPoints || !batchable(req) {
+			return false
+		}
+
+		n := targets + len(req.Targets)
+		l := urlLength + targetsURLLength(req.Targets)
+		if n > b.batch.MaxTargets || (b.batch.MaxURLLength > 0 && l > b.batch.MaxURLLength) {
+			return false
+		}
+
+		batch = append(batch, req)
+		targets, urlLength = n, l
+		return true
+	}
+
+	rest := make([]*renderReq, 0, len(held))
+	for _, req := range held {
+		if !add(req) {
+			rest = append(rest, req)
+		}
+	}
+	held = rest
+
+	for len(held) < b.batch.MaxTargets && targets < b.batch.MaxTargets {
+		var req *renderReq
+		select {
+		case req = <-b.fastRenderQ:
+		default:
+			select {
+			case req = <-b.slowRenderQ:
+			default:
+			}
+		}
+		if req == nil {
+			break
+		}
+
+		b.requestsInQueue.WithLabelValues("render").Dec()
+		if !add(req) {
+			held = append(held, req)
+		}
+	}
+
+	return batch, held
+}
+
+// sameRender reports whether the requests are part of the same render. The sub-requests of a render
+// carry its UUID, while their contexts may differ, e.g. the replicas of hedged requests have their own.
+func sameRender(a, b *renderReq) bool {
+	id := util.GetUUID(a.Ctx)
+	if id == "" {
+		return a.Ctx == b.Ctx
+	}
+	return id == util.GetUUID(b.Ctx)
+}
+
+// batchable reports whether the targets of the request can be told apart in the metrics of a batch,
+// which is not the case of the glob patterns and of the tagged series.
+func batchable(r *renderReq) bool {
+	for _, target := range r.Targets {
+		if strings.ContainsAny(target, globChars) || types.IsTagged(target) {
+			return false
+		}
+	}
+	return true
+}
+
+func targetsURLLength(targets []string) int {
+	length := 0
+	for _, target := range targets {
+		length += len("&target=") + len(url.QueryEscape(target))
+	}
+	return length
+}
+
+// renderBatch sends the batch of render requests as a single one, and routes the metrics
+// back to the requests of their targets. The requests left without metrics, e.g. because the
+// backend returned their metric under another name, are sent on their own.
+func (b *Backend) renderBatch(batch []*renderReq, render func(context.Context, types.RenderRequest) ([]types.Metric, error)) {
+	send := func(req *renderReq) {
+		res, err := render(req.Ctx, req.RenderRequest)
+		if err != nil {
+			req.Errors <- err
+		} else {
+			b.addSourceMetaToMetrics(res)
+			req.Results <- res
+		}
+	}
+	if len(batch) == 1 {
+		send(batch[0])
+		return
+	}
+
+	request := batch[0].RenderRequest
+	request.Targets = nil
+	requestsOf := make(map[string][]int)
+	for i, req := range batch {
+		for _, target := range req.Targets {
+			if _, ok := requestsOf[target]; !ok {
+				request.Targets = append(request.Targets, target)
+			}
+			requestsOf[target] = append(requestsOf[target], i)
+		}
+	}
+
+	ctx, cancel := batchContext(batch)
+	res, err := render(ctx, request)
+	cancel()
+	if err != nil {
+		for _, req := range batch {
+			req.Errors <- err
+		}
+		return
+	}
+
+	b.addSourceMetaToMetrics(res)
+	results := make([][]types.Metric, len(batch))
+	for _, m := range res {
+		for j, i := range requestsOf[m.Name] {
+			// the metrics are merged in place, each request needs its own
+			if j > 0 {
+				m.Values = append([]float64(nil), m.Values...)
+				m.IsAbsent = append([]bool(nil), m.IsAbsent...)
+			}
+			results[i] = append(results[i], m)
+		}
+	}
+	for i, req := range batch {
+		if len(results[i]) == 0 {
+			send(req)
+		} else {
+			req.Results <- results[i]
+		}
+	}
+}
+
+// batchContext returns the context to send a batch with. It has the values of the first request,
+// and is done once all of the requests are, so that a request that is given up on, such as
+// the replica of a hedged render that lost, does not fail the others.
+func batchContext(batch []*renderReq) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(detachedContext{batch[0].Ctx})
+	go func() {
+		for _, req := range batch {
+			select {
+			case <-req.Ctx.Done():
+			case <-ctx.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+
+	return ctx, cancel
+}
+
+// detachedContext has the values of its parent, but neither its deadline nor its cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
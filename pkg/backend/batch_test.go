@@ -0,0 +1,310 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/bookingcom/carbonapi/pkg/limiter"
+	"github.com/bookingcom/carbonapi/pkg/types"
+	"github.com/bookingcom/carbonapi/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type batchTestBackend struct {
+	BackendImpl
+
+	mu      sync.Mutex
+	calls   [][]string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *batchTestBackend) Render(ctx context.Context, request types.RenderRequest) ([]types.Metric, error) {
+	b.mu.Lock()
+	first := len(b.calls) == 0
+	b.calls = append(b.calls, request.Targets)
+	b.mu.Unlock()
+
+	if first {
+		close(b.started)
+		<-b.release
+	}
+
+	var metrics []types.Metric
+	for _, target := range request.Targets {
+		if target == "missing" {
+			continue
+		}
+		// the globs are expanded and the renamed metrics canonicalised by the backend
+		name := strings.Replace(target, "*", "x", 1)
+		name = strings.Replace(name, "renamed", "canonical", 1)
+		metrics = append(metrics, types.Metric{Name: name, Values: []float64{1}, IsAbsent: []bool{false}})
+	}
+	if len(metrics) == 0 {
+		return nil, types.ErrMetricsNotFound
+	}
+	return metrics, nil
+}
+
+func (b *batchTestBackend) BackendInfo() (string, string, string) {
+	return "batch", "cluster", "dc"
+}
+
+func (b *batchTestBackend) GetServerAddress() string {
+	return "http://batch:8080"
+}
+
+func TestRenderBatches(t *testing.T) {
+	impl := &batchTestBackend{started: make(chan struct{}), release: make(chan struct{})}
+	b := NewBackend(impl, 100, limiter.New(cfg.AdaptiveLimit{}, 1, nil), nil, nil, nil, cfg.RenderBatch{MaxTargets: 10},
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_requests_in_queue"}, []string{"request"}),
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_saturation"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_time_in_queue"}, []string{"request"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_enqueued_requests"}, []string{"request"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_backend_duration"}, []string{"request"}))
+
+	ctx := context.Background()
+	otherCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// the replicas of hedged requests have their own contexts, with the UUID of their render
+	renderCtx := util.WithUUID(ctx)
+	hedgedCtx, cancelHedged := context.WithCancel(renderCtx)
+	defer cancelHedged()
+
+	type sent struct {
+		target string
+		msgCh  chan []types.Metric
+		errCh  chan error
+	}
+	send := func(ctx context.Context, target string) sent {
+		s := sent{target: target, msgCh: make(chan []types.Metric, 1), errCh: make(chan error, 1)}
+		b.SendRender(ctx, types.NewRenderRequest([]string{target}, 0, 1), s.msgCh, s.errCh)
+		return s
+	}
+
+	// the first request blocks the backend, the next ones queue up behind it
+	requests := []sent{send(ctx, "first")}
+	<-impl.started
+	requests = append(requests,
+		send(ctx, "a"),
+		send(ctx, "b"),
+		send(ctx, "glob.*"),
+		send(otherCtx, "other"),
+		send(ctx, "c"),
+		send(ctx, "missing"),
+		send(ctx, "renamed"),
+		send(renderCtx, "d"),
+		send(hedgedCtx, "e"),
+	)
+	close(impl.release)
+
+	for _, s := range requests {
+		select {
+		case metrics := <-s.msgCh:
+			name := strings.Replace(s.target, "*", "x", 1)
+			name = strings.Replace(name, "renamed", "canonical", 1)
+			if len(metrics) != 1 || metrics[0].Name != name {
+				t.Errorf("expected the metric of %s, got %v", s.target, metrics)
+			}
+			if !reflect.DeepEqual(metrics[0].SourceClusters, []string{"cluster"}) {
+				t.Errorf("expected the source cluster of %s, got %v", s.target, metrics[0].SourceClusters)
+			}
+		case err := <-s.errCh:
+			if s.target != "missing" || !errors.Is(err, types.ErrMetricsNotFound) {
+				t.Errorf("unexpected error for %s: %v", s.target, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no response for %s", s.target)
+		}
+	}
+
+	impl.mu.Lock()
+	calls := impl.calls
+	impl.mu.Unlock()
+	sort.Slice(calls, func(i, j int) bool { return calls[i][0] < calls[j][0] })
+	// the requests left without metrics by the batch are sent on their own
+	expected := [][]string{{"a", "b", "c", "missing", "renamed"}, {"d", "e"}, {"first"}, {"glob.*"}, {"missing"}, {"other"}, {"renamed"}}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected the calls %v, got %v", expected, calls)
+	}
+}
+
+func TestBatchRendersLimits(t *testing.T) {
+	newReq := func(target string) *renderReq {
+		return &renderReq{RenderRequest: types.NewRenderRequest([]string{target}, 0, 1), Ctx: context.Background()}
+	}
+
+	tests := []struct {
+		name     string
+		batch    cfg.RenderBatch
+		expected int
+	}{
+		{name: "default", batch: cfg.DefaultCommonConfig().RenderBatch, expected: 1},
+		{name: "disabled", batch: cfg.RenderBatch{MaxTargets: 1}, expected: 1},
+		{name: "max targets", batch: cfg.RenderBatch{MaxTargets: 3}, expected: 3},
+		{name: "max url length", batch: cfg.RenderBatch{MaxTargets: 10,
+			MaxURLLength: len("http://batch:8080") + renderURLBaseLength + 4*len("&target=foo.bar.x")}, expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Backend{
+				BackendImpl:     &batchTestBackend{},
+				fastRenderQ:     make(chan *renderReq, 10),
+				slowRenderQ:     make(chan *renderReq, 10),
+				batch:           tt.batch,
+				requestsInQueue: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_requests_in_queue"}, []string{"request"}),
+			}
+			for i := 0; i < 5; i++ {
+				b.fastRenderQ <- newReq("foo.bar." + string(rune('a'+i)))
+			}
+
+			batch, held := b.batchRenders(newReq("foo.bar.x"), nil)
+			if len(batch) != tt.expected {
+				t.Errorf("expected a batch of %d, got %d", tt.expected, len(batch))
+			}
+			if len(batch)+len(held)+len(b.fastRenderQ) != 6 {
+				t.Errorf("expected no requests to be lost, got %d in the batch, %d held and %d queued", len(batch), len(held), len(b.fastRenderQ))
+			}
+		})
+	}
+}
+
+func TestNextRenderPriority(t *testing.T) {
+	b := Backend{
+		BackendImpl:     &batchTestBackend{},
+		fastRenderQ:     make(chan *renderReq, 10),
+		slowRenderQ:     make(chan *renderReq, 10),
+		batch:           cfg.RenderBatch{MaxTargets: 10},
+		requestsInQueue: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_requests_in_queue"}, []string{"request"}),
+	}
+	// the requests of different contexts are not batched together
+	newReq := func(target string, slow bool) *renderReq {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		return &renderReq{RenderRequest: types.NewRenderRequest([]string{target}, 0, 1), Ctx: ctx, Slow: slow}
+	}
+
+	b.slowRenderQ <- newReq("s1", true)
+	b.slowRenderQ <- newReq("s2", true)
+	b.fastRenderQ <- newReq("f1", false)
+	b.fastRenderQ <- newReq("f2", false)
+
+	var order []string
+	var held []*renderReq
+	next := func() {
+		var r *renderReq
+		r, held = b.nextRender(held)
+		var batch []*renderReq
+		batch, held = b.batchRenders(r, held)
+		for _, req := range batch {
+			order = append(order, req.Targets[0])
+		}
+	}
+
+	next()
+	// the fast requests queued after the slow ones were held go first
+	b.fastRenderQ <- newReq("f3", false)
+	for i := 0; i < 4; i++ {
+		next()
+	}
+
+	expected := []string{"f1", "f2", "f3", "s1", "s2"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected the order %v, got %v", expected, order)
+	}
+}
+
+type slowBatchTestBackend struct {
+	batchTestBackend
+}
+
+func (b *slowBatchTestBackend) Render(ctx context.Context, request types.RenderRequest) ([]types.Metric, error) {
+	time.Sleep(30 * time.Millisecond)
+	var metrics []types.Metric
+	for _, target := range request.Targets {
+		metrics = append(metrics, types.Metric{Name: target, Values: []float64{1}, IsAbsent: []bool{false}})
+	}
+	return metrics, nil
+}
+
+func TestRenderBatchLatencyPerTarget(t *testing.T) {
+	config := testHealthConfig
+	config.MinRequests = 1
+	config.SlowRequest = 20 * time.Millisecond
+	health := NewHealth(config, nil, nil)
+
+	b := Backend{
+		BackendImpl:     &slowBatchTestBackend{},
+		fastRenderQ:     make(chan *renderReq, 10),
+		slowRenderQ:     make(chan *renderReq, 10),
+		health:          health,
+		batch:           cfg.RenderBatch{MaxTargets: 10},
+		requestsInQueue: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_requests_in_queue"}, []string{"request"}),
+		saturation:      prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_saturation"}),
+		timeInQSec:      prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_time_in_queue"}, []string{"request"}),
+		backendDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_backend_duration"}, []string{"request"}),
+	}
+
+	// the batch of four takes longer than a slow request, but not per target
+	errCh := make(chan error, 4)
+	msgCh := make(chan []types.Metric, 4)
+	for _, target := range []string{"a", "b", "c", "d"} {
+		b.fastRenderQ <- &renderReq{RenderRequest: types.NewRenderRequest([]string{target}, 0, 1), Ctx: context.Background(),
+			Results: msgCh, Errors: errCh}
+	}
+	b.Proc()
+
+	for i := 0; i < 4; i++ {
+		select {
+		case <-msgCh:
+		case err := <-errCh:
+			t.Fatal(err)
+		case <-time.After(time.Second):
+			t.Fatal("no response")
+		}
+	}
+	// the outcome is recorded after the responses are sent
+	for i := 0; i < 100 && health.Status().Requests == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if status := health.Status(); status.Requests != 1 || status.Failures != 0 {
+		t.Errorf("expected a single request that is not slow, got %d requests and %d failures", status.Requests, status.Failures)
+	}
+}
+
+func TestBatchContext(t *testing.T) {
+	renderCtx := util.WithUUID(context.Background())
+	firstCtx, cancelFirst := context.WithCancel(renderCtx)
+	secondCtx, cancelSecond := context.WithCancel(renderCtx)
+	defer cancelSecond()
+
+	ctx, cancel := batchContext([]*renderReq{{Ctx: firstCtx}, {Ctx: secondCtx}})
+	defer cancel()
+	if util.GetUUID(ctx) != util.GetUUID(renderCtx) {
+		t.Errorf("expected the UUID of the render, got %q", util.GetUUID(ctx))
+	}
+
+	// the batch goes on while any of its requests does
+	cancelFirst()
+	select {
+	case <-ctx.Done():
+		t.Fatal("expected the batch not to be cancelled with its first request")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancelSecond()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the batch to be cancelled with all of its requests")
+	}
+}
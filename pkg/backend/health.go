@@ -0,0 +1,246 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/bookingcom/carbonapi/pkg/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HealthState is the state of a backend as seen by the circuit breaker.
+type HealthState int
+
+const (
+	// Healthy backends get all the requests.
+	Healthy HealthState = iota
+	// HalfOpen backends are past their ejection and get a single probe request.
+	HalfOpen
+	// Ejected backends get no requests, unless they are the last replicas of their cluster.
+	Ejected
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case Healthy:
+		return "healthy"
+	case HalfOpen:
+		return "half-open"
+	case Ejected:
+		return "ejected"
+	}
+	return "unknown"
+}
+
+// HealthStatus is a snapshot of the health of a backend.
+type HealthStatus struct {
+	State        HealthState
+	Requests     int // the requests in the current window
+	Failures     int // the failed requests in the current window
+	Ejections    int // the consecutive ejections
+	EjectedUntil time.Time
+}
+
+// Health tracks the health of a backend from the errors and the latency of its requests.
+// The zero value is not usable, a nil *Health is always healthy and records nothing.
+type Health struct {
+	config cfg.BackendHealth
+	now    func() time.Time
+
+	mu           sync.Mutex
+	state        HealthState
+	windowStart  time.Time
+	requests     int
+	failures     int
+	ejections    int
+	ejectedUntil time.Time
+	probeStart   time.Time // zero when no probe is in flight
+
+	stateGauge        prometheus.Gauge
+	failureRatioGauge prometheus.Gauge
+}
+
+// NewHealth makes the health tracker of a backend. It returns nil when the tracking is disabled.
+// The gauges are optional and expose the state and the failure ratio of the current window.
+func NewHealth(config cfg.BackendHealth, stateGauge, failureRatioGauge prometheus.Gauge) *Health {
+	if !config.Enabled {
+		return nil
+	}
+
+	h := &Health{
+		config:            config,
+		now:               time.Now,
+		stateGauge:        stateGauge,
+		failureRatioGauge: failureRatioGauge,
+	}
+	h.windowStart = h.now()
+	h.setState(Healthy)
+
+	return h
+}
+
+// Available reports whether the backend should get a request. It does not change the health,
+// the probe of a backend past its ejection is only claimed by Dispatch when a request is sent.
+func (h *Health) Available() bool {
+	if h == nil {
+		return true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	switch h.state {
+	case Ejected:
+		return !now.Before(h.ejectedUntil)
+	case HalfOpen:
+		return !h.probing(now)
+	}
+
+	return true
+}
+
+// Dispatch is called when a request is sent to the backend, and reports whether it is the probe.
+// An ejected backend turns half-open at the end of its ejection, and its next request is the probe
+// until the outcome of that probe is recorded.
+func (h *Health) Dispatch() bool {
+	if h == nil {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	switch h.state {
+	case Ejected:
+		if now.Before(h.ejectedUntil) {
+			return false
+		}
+		h.setState(HalfOpen)
+	case HalfOpen:
+		if h.probing(now) {
+			return false
+		}
+	default:
+		return false
+	}
+	h.probeStart = now
+
+	return true
+}
+
+// probing reports whether a probe is in flight. A probe that never got its outcome recorded is retried.
+func (h *Health) probing(now time.Time) bool {
+	return !h.probeStart.IsZero() && now.Sub(h.probeStart) < h.config.EjectionTime
+}
+
+// Record records the outcome of a request to the backend, the probe when Dispatch reported it was.
+func (h *Health) Record(duration time.Duration, err error, probe bool) {
+	if h == nil {
+		return
+	}
+
+	// the requests canceled by the clients say nothing of the backend
+	if errors.Is(err, context.Canceled) {
+		if probe {
+			h.mu.Lock()
+			h.probeStart = time.Time{}
+			h.mu.Unlock()
+		}
+		return
+	}
+	var notFound types.ErrNotFound
+	failed := err != nil && !errors.As(err, &notFound)
+	if !failed && h.config.SlowRequest > 0 && duration > h.config.SlowRequest {
+		failed = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	switch h.state {
+	case Ejected:
+		// the requests sent before the ejection, or to the last replica of a cluster
+		return
+	case HalfOpen:
+		// only the probe judges a half-open backend
+		if !probe {
+			return
+		}
+		h.probeStart = time.Time{}
+		if failed {
+			h.eject(now)
+			return
+		}
+		h.ejections = 0
+		h.resetWindow(now)
+		h.setState(Healthy)
+		return
+	}
+
+	if now.Sub(h.windowStart) > h.config.Window {
+		h.resetWindow(now)
+	}
+	h.requests++
+	if failed {
+		h.failures++
+	}
+	ratio := float64(h.failures) / float64(h.requests)
+	if h.failureRatioGauge != nil {
+		h.failureRatioGauge.Set(ratio)
+	}
+
+	if h.requests >= h.config.MinRequests && ratio > h.config.MaxFailureRatio {
+		h.eject(now)
+	}
+}
+
+// Status returns a snapshot of the health of the backend.
+func (h *Health) Status() HealthStatus {
+	if h == nil {
+		return HealthStatus{State: Healthy}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return HealthStatus{
+		State:        h.state,
+		Requests:     h.requests,
+		Failures:     h.failures,
+		Ejections:    h.ejections,
+		EjectedUntil: h.ejectedUntil,
+	}
+}
+
+func (h *Health) eject(now time.Time) {
+	ejection := h.config.EjectionTime << h.ejections
+	if ejection > h.config.MaxEjectionTime || ejection <= 0 {
+		ejection = h.config.MaxEjectionTime
+	}
+	h.ejections++
+	h.ejectedUntil = now.Add(ejection)
+	h.resetWindow(now)
+	h.setState(Ejected)
+}
+
+func (h *Health) resetWindow(now time.Time) {
+	h.windowStart = now
+	h.requests = 0
+	h.failures = 0
+	if h.failureRatioGauge != nil {
+		h.failureRatioGauge.Set(0)
+	}
+}
+
+func (h *Health) setState(state HealthState) {
+	h.state = state
+	if h.stateGauge != nil {
+		h.stateGauge.Set(float64(state))
+	}
+}
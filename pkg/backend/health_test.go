@@ -0,0 +1,275 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/bookingcom/carbonapi/pkg/types"
+)
+
+var testHealthConfig = cfg.BackendHealth{
+	Enabled:         true,
+	Window:          10 * time.Second,
+	MinRequests:     4,
+	MaxFailureRatio: 0.5,
+	SlowRequest:     time.Second,
+	EjectionTime:    30 * time.Second,
+	MaxEjectionTime: time.Minute,
+}
+
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) now() time.Time {
+	return c.t
+}
+
+func newTestHealth() (*Health, *testClock) {
+	clock := &testClock{t: time.Unix(1500000000, 0)}
+	h := NewHealth(testHealthConfig, nil, nil)
+	h.now = clock.now
+	h.windowStart = clock.t
+	return h, clock
+}
+
+func TestHealthDisabled(t *testing.T) {
+	h := NewHealth(cfg.BackendHealth{}, nil, nil)
+	if h != nil {
+		t.Fatal("expected no health tracking when disabled")
+	}
+
+	for i := 0; i < 100; i++ {
+		h.Record(time.Minute, errors.New("failed"), false)
+	}
+	if !h.Available() {
+		t.Error("expected an untracked backend to be available")
+	}
+	if got := h.Status().State; got != Healthy {
+		t.Errorf("expected an untracked backend to be healthy, got %s", got)
+	}
+}
+
+func TestHealthEjection(t *testing.T) {
+	h, clock := newTestHealth()
+
+	h.Record(10*time.Millisecond, nil, false)
+	h.Record(10*time.Millisecond, errors.New("failed"), false)
+	h.Record(2*time.Second, nil, false)
+	if got := h.Status().State; got != Healthy {
+		t.Fatalf("expected no judgement below the minimum requests, got %s", got)
+	}
+
+	h.Record(10*time.Millisecond, errors.New("failed"), false)
+	status := h.Status()
+	if status.State != Ejected {
+		t.Fatalf("expected the backend to be ejected, got %s", status.State)
+	}
+	if want := clock.t.Add(30 * time.Second); !status.EjectedUntil.Equal(want) {
+		t.Errorf("expected the ejection until %v, got %v", want, status.EjectedUntil)
+	}
+	if h.Available() {
+		t.Error("expected an ejected backend to be unavailable")
+	}
+
+	clock.t = clock.t.Add(31 * time.Second)
+	if !h.Available() {
+		t.Fatal("expected the backend to be available for a probe after the ejection")
+	}
+	if got := h.Status().State; got != Ejected {
+		t.Fatalf("expected the availability not to change the health, got %s", got)
+	}
+	if !h.Dispatch() {
+		t.Fatal("expected the next request to be the probe")
+	}
+	if got := h.Status().State; got != HalfOpen {
+		t.Fatalf("expected the backend to be half-open, got %s", got)
+	}
+	if h.Available() || h.Dispatch() {
+		t.Error("expected a single probe while half-open")
+	}
+
+	// the requests other than the probe, e.g. to the last replicas of a cluster, do not judge the backend
+	h.Record(10*time.Millisecond, nil, false)
+	if got := h.Status().State; got != HalfOpen {
+		t.Fatalf("expected the backend to stay half-open, got %s", got)
+	}
+
+	// a failed probe ejects the backend for twice as long
+	h.Record(10*time.Millisecond, errors.New("failed"), true)
+	status = h.Status()
+	if status.State != Ejected || status.Ejections != 2 {
+		t.Fatalf("expected the backend to be ejected a second time, got %s after %d ejections", status.State, status.Ejections)
+	}
+	if want := clock.t.Add(time.Minute); !status.EjectedUntil.Equal(want) {
+		t.Errorf("expected the ejection until %v, got %v", want, status.EjectedUntil)
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	if !h.Dispatch() {
+		t.Fatal("expected a probe after the ejection")
+	}
+	h.Record(10*time.Millisecond, nil, true)
+	status = h.Status()
+	if status.State != Healthy || status.Ejections != 0 {
+		t.Fatalf("expected a successful probe to heal the backend, got %s after %d ejections", status.State, status.Ejections)
+	}
+}
+
+func TestHealthIgnoredOutcomes(t *testing.T) {
+	h, _ := newTestHealth()
+
+	for i := 0; i < 10; i++ {
+		h.Record(10*time.Millisecond, types.ErrMetricsNotFound, false)
+		h.Record(10*time.Millisecond, context.Canceled, false)
+	}
+
+	status := h.Status()
+	if status.State != Healthy {
+		t.Fatalf("expected the backend to stay healthy, got %s", status.State)
+	}
+	if status.Requests != 10 || status.Failures != 0 {
+		t.Errorf("expected 10 requests and no failures, got %d and %d", status.Requests, status.Failures)
+	}
+}
+
+func TestHealthWindow(t *testing.T) {
+	h, clock := newTestHealth()
+
+	for i := 0; i < 3; i++ {
+		h.Record(10*time.Millisecond, errors.New("failed"), false)
+	}
+	clock.t = clock.t.Add(11 * time.Second)
+	h.Record(10*time.Millisecond, errors.New("failed"), false)
+
+	status := h.Status()
+	if status.State != Healthy || status.Requests != 1 {
+		t.Fatalf("expected the failures of the previous window to be forgotten, got %s with %d requests", status.State, status.Requests)
+	}
+}
+
+func TestHealthStuckProbe(t *testing.T) {
+	h, clock := newTestHealth()
+
+	for i := 0; i < 4; i++ {
+		h.Record(10*time.Millisecond, errors.New("failed"), false)
+	}
+	clock.t = clock.t.Add(30 * time.Second)
+	if !h.Dispatch() {
+		t.Fatal("expected a probe after the ejection")
+	}
+
+	// the outcome of the probe is never recorded
+	clock.t = clock.t.Add(30 * time.Second)
+	if !h.Available() || !h.Dispatch() {
+		t.Error("expected another probe when the previous one got no outcome")
+	}
+
+	// a canceled probe says nothing of the backend, the next request probes it
+	h.Record(10*time.Millisecond, context.Canceled, true)
+	if !h.Dispatch() {
+		t.Error("expected another probe when the previous one was canceled")
+	}
+}
+
+type healthTestBackend struct {
+	BackendImpl
+
+	addr    string
+	cluster string
+}
+
+func (b healthTestBackend) Contains([]string) bool {
+	return true
+}
+
+func (b healthTestBackend) BackendInfo() (string, string, string) {
+	return b.addr, b.cluster, "dc"
+}
+
+func ejectedHealth() *Health {
+	h, _ := newTestHealth()
+	h.eject(time.Now())
+	h.now = time.Now
+	return h
+}
+
+func TestFilterHealthy(t *testing.T) {
+	backends := []Backend{
+		{BackendImpl: healthTestBackend{addr: "a1", cluster: "a"}},
+		{BackendImpl: healthTestBackend{addr: "a2", cluster: "a"}, health: ejectedHealth()},
+		{BackendImpl: healthTestBackend{addr: "b1", cluster: "b"}, health: ejectedHealth()},
+		{BackendImpl: healthTestBackend{addr: "b2", cluster: "b"}, health: ejectedHealth()},
+		{BackendImpl: healthTestBackend{addr: "c1", cluster: "c"}, health: ejectedHealth()},
+	}
+
+	got, ok := Filter(backends, []string{"foo.bar"})
+	if !ok {
+		t.Error("expected the backends to be filtered by the path cache")
+	}
+
+	var addrs []string
+	for _, b := range got {
+		addr, _, _ := b.BackendInfo()
+		addrs = append(addrs, addr)
+	}
+
+	// the ejected backends are kept when they are all the backends of their cluster
+	want := []string{"a1", "b1", "b2", "c1"}
+	if len(addrs) != len(want) {
+		t.Fatalf("expected the backends %v, got %v", want, addrs)
+	}
+	for i := range want {
+		if addrs[i] != want[i] {
+			t.Fatalf("expected the backends %v, got %v", want, addrs)
+		}
+	}
+}
+
+func TestFilterHealthyKeepsProbe(t *testing.T) {
+	h, clock := newTestHealth()
+	h.eject(clock.t)
+	clock.t = clock.t.Add(time.Minute)
+	backends := []Backend{
+		{BackendImpl: healthTestBackend{addr: "a1", cluster: "a"}},
+		{BackendImpl: healthTestBackend{addr: "a2", cluster: "a"}, health: h},
+	}
+
+	// filtering the backends of requests that are then never sent leaves the probe to the next one
+	for i := 0; i < 3; i++ {
+		if got := FilterHealthy(backends); len(got) != 2 {
+			t.Fatalf("expected the backend past its ejection to be kept, got %d backends", len(got))
+		}
+	}
+	if got := h.Status().State; got != Ejected {
+		t.Errorf("expected the filtering not to change the health, got %s", got)
+	}
+	if !h.Dispatch() {
+		t.Error("expected the probe to be left for the request sent")
+	}
+}
+
+func TestHealthSlowRequests(t *testing.T) {
+	config := testHealthConfig
+	config.SlowRequest = 0
+	h := NewHealth(config, nil, nil)
+
+	// the slow successful requests only count when a slow threshold is set
+	for i := 0; i < 10; i++ {
+		h.Record(time.Minute, nil, false)
+	}
+	if status := h.Status(); status.State != Healthy || status.Failures != 0 {
+		t.Fatalf("expected the slow requests not to count by default, got %s with %d failures", status.State, status.Failures)
+	}
+
+	h, _ = newTestHealth()
+	for i := 0; i < 10; i++ {
+		h.Record(time.Minute, nil, false)
+	}
+	if status := h.Status(); status.State != Ejected {
+		t.Errorf("expected the slow requests to eject the backend above the threshold, got %s", status.State)
+	}
+}
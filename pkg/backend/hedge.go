@@ -0,0 +1,229 @@
+package backend
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/bookingcom/carbonapi/pkg/types"
+)
+
+const (
+	// The number of the recent render latencies the hedge delay is computed from.
+	hedgeLatencies = 1000
+	// The number of latencies below which the maximum delay is used.
+	hedgeMinLatencies = 20
+
+	defaultHedgePercentile = 95
+	defaultHedgeMaxDelay   = time.Second
+)
+
+// Hedger hedges the render requests to the replicas of a cluster.
+// It is shared by the backends of the cluster, a nil *Hedger disables the hedging.
+type Hedger struct {
+	config cfg.Hedge
+
+	// the replica requested first, rotated to spread the load
+	first uint32
+
+	mu        sync.Mutex
+	latencies []time.Duration // a ring of the recent latencies
+	next      int
+}
+
+// NewHedger makes the hedger of a cluster. It returns nil when the config is nil.
+func NewHedger(config *cfg.Hedge) *Hedger {
+	if config == nil {
+		return nil
+	}
+
+	h := &Hedger{
+		config:    *config,
+		latencies: make([]time.Duration, 0, hedgeLatencies),
+	}
+	if h.config.Percentile <= 0 || h.config.Percentile > 100 {
+		h.config.Percentile = defaultHedgePercentile
+	}
+	if h.config.MaxDelay <= 0 {
+		h.config.MaxDelay = defaultHedgeMaxDelay
+	}
+	if h.config.MinDelay > h.config.MaxDelay {
+		h.config.MinDelay = h.config.MaxDelay
+	}
+
+	return h
+}
+
+// Observe records the latency of a successful render request to the cluster.
+func (h *Hedger) Observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < hedgeLatencies {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % hedgeLatencies
+}
+
+// Delay returns how long to wait for a replica before requesting the next one.
+func (h *Hedger) Delay() time.Duration {
+	h.mu.Lock()
+	if len(h.latencies) < hedgeMinLatencies {
+		h.mu.Unlock()
+		return h.config.MaxDelay
+	}
+	latencies := make([]time.Duration, len(h.latencies))
+	copy(latencies, h.latencies)
+	h.mu.Unlock()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	i := int(math.Ceil(h.config.Percentile/100*float64(len(latencies)))) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	delay := latencies[i]
+	if delay < h.config.MinDelay {
+		delay = h.config.MinDelay
+	}
+	if delay > h.config.MaxDelay {
+		delay = h.config.MaxDelay
+	}
+
+	return delay
+}
+
+// order returns the order to request n replicas in.
+func (h *Hedger) order(n int) []int {
+	first := int(atomic.AddUint32(&h.first, 1) % uint32(n))
+	order := make([]int, n)
+	for i := range order {
+		order[i] = (first + i) % n
+	}
+
+	return order
+}
+
+type hedgedResult struct {
+	metrics []types.Metric
+	err     error
+	latency time.Duration
+}
+
+// hedgedRender sends the render request to the replicas one after the other until one of them answers.
+// The next replica is requested when the previous one fails, or has not answered within the hedge delay.
+// The first answer wins and the other requests are canceled, the answers that arrived with it are kept
+// for their null points to be healed by the merge.
+// The errors of the replicas that failed before the answer are returned along with it.
+func hedgedRender(ctx context.Context, hedger *Hedger, replicas []Backend, request types.RenderRequest) ([][]types.Metric, []error) {
+	order := hedger.order(len(replicas))
+	results := make(chan hedgedResult, len(replicas))
+	cancels := make([]context.CancelFunc, 0, len(replicas))
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	var hedge <-chan time.Time
+	send := func() {
+		replica := replicas[order[len(cancels)]]
+		replicaCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+
+		go func() {
+			start := time.Now()
+			msgCh := make(chan []types.Metric, 1)
+			errCh := make(chan error, 1)
+			replica.SendRender(replicaCtx, request, msgCh, errCh)
+			select {
+			case msg := <-msgCh:
+				results <- hedgedResult{metrics: msg, latency: time.Since(start)}
+			case err := <-errCh:
+				results <- hedgedResult{err: err}
+			}
+		}()
+
+		hedge = nil
+		if len(cancels) < len(replicas) {
+			hedge = time.After(hedger.Delay())
+		}
+	}
+
+	send()
+	pending := 1
+	errs := make([]error, 0, len(replicas))
+	for pending > 0 {
+		select {
+		case <-hedge:
+			send()
+			pending++
+		case r := <-results:
+			pending--
+			if r.err != nil {
+				errs = append(errs, r.err)
+				if len(cancels) < len(replicas) {
+					send()
+					pending++
+				}
+				continue
+			}
+
+			hedger.Observe(r.latency)
+			msgs := [][]types.Metric{r.metrics}
+		arrived:
+			for pending > 0 {
+				select {
+				case r := <-results:
+					pending--
+					if r.err == nil {
+						hedger.Observe(r.latency)
+						msgs = append(msgs, r.metrics)
+					}
+				default:
+					break arrived
+				}
+			}
+
+			return msgs, errs
+		}
+	}
+
+	return nil, errs
+}
+
+// splitHedged splits the backends into the ones requested directly, and the groups of replicas
+// of the hedged clusters.
+func splitHedged(backends []Backend) ([]Backend, [][]Backend) {
+	direct := make([]Backend, 0, len(backends))
+	var hedgers []*Hedger
+	groups := make(map[*Hedger][]Backend)
+	for _, b := range backends {
+		if b.hedger == nil {
+			direct = append(direct, b)
+			continue
+		}
+		if _, ok := groups[b.hedger]; !ok {
+			hedgers = append(hedgers, b.hedger)
+		}
+		groups[b.hedger] = append(groups[b.hedger], b)
+	}
+
+	var hedged [][]Backend
+	for _, h := range hedgers {
+		// a single replica has nothing to hedge with
+		if len(groups[h]) == 1 {
+			direct = append(direct, groups[h][0])
+			continue
+		}
+		hedged = append(hedged, groups[h])
+	}
+
+	return direct, hedged
+}
@@ -0,0 +1,182 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/bookingcom/carbonapi/pkg/limiter"
+	"github.com/bookingcom/carbonapi/pkg/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+type hedgeTestBackend struct {
+	BackendImpl
+
+	name     string
+	cluster  string
+	latency  time.Duration
+	err      error
+	canceled chan string
+}
+
+func (b hedgeTestBackend) Render(ctx context.Context, request types.RenderRequest) ([]types.Metric, error) {
+	select {
+	case <-time.After(b.latency):
+	case <-ctx.Done():
+		if b.canceled != nil {
+			b.canceled <- b.name
+		}
+		return nil, ctx.Err()
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return []types.Metric{{Name: b.name}}, nil
+}
+
+func (b hedgeTestBackend) BackendInfo() (string, string, string) {
+	return b.name, b.cluster, ""
+}
+
+func newHedgeTestBackend(impl BackendImpl, hedger *Hedger) Backend {
+	return NewBackend(impl, 10, limiter.New(cfg.AdaptiveLimit{}, 10, nil), nil, hedger, nil, cfg.RenderBatch{},
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_requests_in_queue"}, []string{"request"}),
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_saturation"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_time_in_queue"}, []string{"request"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_enqueued_requests"}, []string{"request"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_backend_duration"}, []string{"request"}))
+}
+
+// firstReplica makes the next hedged request start with the i-th replica.
+func firstReplica(h *Hedger, i, n int) {
+	h.first = uint32(i + n - 1)
+}
+
+func TestHedgerDelay(t *testing.T) {
+	h := NewHedger(&cfg.Hedge{Percentile: 90, MinDelay: 5 * time.Millisecond, MaxDelay: 50 * time.Millisecond})
+
+	if got := h.Delay(); got != 50*time.Millisecond {
+		t.Errorf("expected the maximum delay without latencies, got %v", got)
+	}
+
+	for i := 1; i <= 100; i++ {
+		h.Observe(time.Duration(i) * 100 * time.Microsecond)
+	}
+	if got := h.Delay(); got != 9*time.Millisecond {
+		t.Errorf("expected the 90th percentile delay, got %v", got)
+	}
+
+	for i := 1; i <= hedgeLatencies; i++ {
+		h.Observe(time.Microsecond)
+	}
+	if got := h.Delay(); got != 5*time.Millisecond {
+		t.Errorf("expected the minimum delay, got %v", got)
+	}
+
+	for i := 1; i <= hedgeLatencies; i++ {
+		h.Observe(time.Second)
+	}
+	if got := h.Delay(); got != 50*time.Millisecond {
+		t.Errorf("expected the maximum delay, got %v", got)
+	}
+
+	if NewHedger(nil) != nil {
+		t.Error("expected no hedger without config")
+	}
+}
+
+func TestHedgedRenderSlowReplica(t *testing.T) {
+	h := NewHedger(&cfg.Hedge{MaxDelay: 10 * time.Millisecond})
+	canceled := make(chan string, 2)
+	replicas := []Backend{
+		newHedgeTestBackend(hedgeTestBackend{name: "slow", latency: time.Minute, canceled: canceled}, h),
+		newHedgeTestBackend(hedgeTestBackend{name: "fast"}, h),
+	}
+	firstReplica(h, 0, len(replicas))
+
+	start := time.Now()
+	msgs, errs := hedgedRender(context.Background(), h, replicas, types.NewRenderRequest([]string{"foo"}, 0, 1))
+	if time.Since(start) > time.Second {
+		t.Error("expected the slow replica not to be waited for")
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	if len(msgs) != 1 || msgs[0][0].Name != "fast" {
+		t.Fatalf("expected the answer of the fast replica, got %v", msgs)
+	}
+
+	select {
+	case name := <-canceled:
+		if name != "slow" {
+			t.Errorf("expected the slow replica to be canceled, got %s", name)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the slow replica to be canceled")
+	}
+}
+
+func TestHedgedRenderFailedReplica(t *testing.T) {
+	// the next replica is requested right away on failure
+	h := NewHedger(&cfg.Hedge{MinDelay: time.Minute, MaxDelay: time.Minute})
+	replicas := []Backend{
+		newHedgeTestBackend(hedgeTestBackend{name: "failed", err: errors.New("failed")}, h),
+		newHedgeTestBackend(hedgeTestBackend{name: "ok"}, h),
+	}
+	firstReplica(h, 0, len(replicas))
+
+	msgs, errs := hedgedRender(context.Background(), h, replicas, types.NewRenderRequest([]string{"foo"}, 0, 1))
+	if len(errs) != 1 {
+		t.Errorf("expected the error of the failed replica, got %v", errs)
+	}
+	if len(msgs) != 1 || msgs[0][0].Name != "ok" {
+		t.Fatalf("expected the answer of the other replica, got %v", msgs)
+	}
+
+	replicas = []Backend{
+		newHedgeTestBackend(hedgeTestBackend{name: "failed1", err: errors.New("failed")}, h),
+		newHedgeTestBackend(hedgeTestBackend{name: "failed2", err: errors.New("failed")}, h),
+	}
+	msgs, errs = hedgedRender(context.Background(), h, replicas, types.NewRenderRequest([]string{"foo"}, 0, 1))
+	if len(errs) != 2 || msgs != nil {
+		t.Errorf("expected the errors of all the replicas, got %v and %v", errs, msgs)
+	}
+}
+
+func TestRendersHedged(t *testing.T) {
+	h := NewHedger(&cfg.Hedge{MaxDelay: 10 * time.Millisecond})
+	backends := []Backend{
+		newHedgeTestBackend(hedgeTestBackend{name: "a1", cluster: "a", latency: time.Minute}, h),
+		newHedgeTestBackend(hedgeTestBackend{name: "b1", cluster: "b"}, nil),
+		newHedgeTestBackend(hedgeTestBackend{name: "a2", cluster: "a"}, h),
+	}
+	firstReplica(h, 0, 2)
+
+	direct, hedged := splitHedged(backends)
+	if len(direct) != 1 || len(hedged) != 1 || len(hedged[0]) != 2 {
+		t.Fatalf("expected a direct backend and a hedged cluster, got %d and %d", len(direct), len(hedged))
+	}
+
+	start := time.Now()
+	metrics, _, errs := Renders(context.Background(), backends, types.NewRenderRequest([]string{"foo"}, 0, 1),
+		cfg.RenderReplicaMismatchConfig{}, zap.NewNop())
+	if time.Since(start) > time.Second {
+		t.Error("expected the slow replica not to be waited for")
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	names := make(map[string]bool)
+	for _, m := range metrics {
+		names[m.Name] = true
+	}
+	if len(names) != 2 || !names["a2"] || !names["b1"] {
+		t.Errorf("expected the metrics of a2 and b1, got %v", names)
+	}
+}
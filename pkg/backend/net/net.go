@@ -3,6 +3,7 @@
 package net
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -16,6 +17,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/bookingcom/carbonapi/pkg/types/encoding/carbonapi_v2"
+	"github.com/bookingcom/carbonapi/pkg/types/encoding/carbonapi_v3"
+	"github.com/bookingcom/carbonapi/pkg/types/encoding/json"
 	"github.com/bookingcom/carbonapi/pkg/util"
 
 	"github.com/dgryski/go-expirecache"
@@ -49,6 +52,7 @@ type NetBackend struct {
 	timeout        time.Duration
 	cache          *expirecache.Cache
 	cacheExpirySec int32
+	protocol       string
 
 	responsesCount *prometheus.CounterVec
 
@@ -71,13 +75,23 @@ type Config struct {
 	Timeout            time.Duration // Set request timeout. Defaults to no timeout.
 	PathCacheExpirySec uint32        // Set time in seconds before items in path cache expire. Defaults to 10 minutes.
 	Logger             *zap.Logger   // Logger to use. Defaults to a no-op logger.
+	Protocol           string        // The format of render and find requests, ProtocolV2 or ProtocolV3. Defaults to ProtocolV2.
 
 	// TODO (grzkv): Make metrics mandatory to simplify code. Nil can be replaced by the mock metrics in tests.
 	QHist     *prometheus.HistogramVec
 	Responses *prometheus.CounterVec
 }
 
+// The protocols a backend can speak for render and find requests.
+const (
+	ProtocolV2 = "carbonapi_v2_pb"
+	ProtocolV3 = "carbonapi_v3_pb"
+)
+
 var fmtProto = []string{"protobuf"}
+var fmtProtoV3 = []string{ProtocolV3}
+
+const contentTypeV3 = "application/x-carbonapi-v3-pb"
 
 // New creates a new backend from the given configuration.
 func New(cfg Config) (*NetBackend, error) {
@@ -98,6 +112,15 @@ func New(cfg Config) (*NetBackend, error) {
 
 	b.address = address
 	b.scheme = scheme
+
+	switch cfg.Protocol {
+	case "", ProtocolV2:
+		b.protocol = ProtocolV2
+	case ProtocolV3:
+		b.protocol = ProtocolV3
+	default:
+		return nil, errors.Errorf("unknown protocol %q", cfg.Protocol)
+	}
 	b.cluster = cfg.Cluster
 	b.dc = cfg.DC
 
@@ -176,6 +199,20 @@ func (b NetBackend) request(ctx context.Context, u *url.URL) (*http.Request, err
 	return req, nil
 }
 
+func (b NetBackend) postRequest(ctx context.Context, u *url.URL, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest("POST", "", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.URL = u
+	req.Header.Set("Content-Type", contentTypeV3)
+
+	req = req.WithContext(ctx)
+	req = util.MarshalCtx(ctx, req)
+
+	return req, nil
+}
+
 func (b NetBackend) do(req *http.Request, request string) (string, []byte, error) {
 	resp, err := b.client.Do(req)
 
@@ -227,6 +264,19 @@ func (b NetBackend) call(ctx context.Context, u *url.URL, request string) (strin
 	return contentType, body, err
 }
 
+// post is like call, but sends the body in a POST request. It is used for the carbonapi_v3_pb requests.
+func (b NetBackend) post(ctx context.Context, u *url.URL, body []byte, request string) (string, []byte, error) {
+	ctx, cancel := b.setTimeout(ctx)
+	defer cancel()
+
+	req, err := b.postRequest(ctx, u, body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return b.do(req, request)
+}
+
 // Contains reports whether the backend contains any of the given targets.
 func (b NetBackend) Contains(targets []string) bool {
 	for _, target := range targets {
@@ -240,14 +290,22 @@ func (b NetBackend) Contains(targets []string) bool {
 
 // Render fetches raw metrics from a backend.
 func (b NetBackend) Render(ctx context.Context, request types.RenderRequest) ([]types.Metric, error) {
-	from := request.From
-	until := request.Until
-	targets := request.Targets
-
+	var contentType string
+	var resp []byte
+	var err error
 	u := b.url("/render/")
-	u = carbonapiV2RenderEncoder(u, from, until, targets)
-
-	contentType, resp, err := b.call(ctx, u, "render")
+	if b.protocol == ProtocolV3 {
+		var body []byte
+		body, err = carbonapi_v3.RenderRequestEncoder(request)
+		if err != nil {
+			return nil, errors.Wrap(err, "Marshal failed")
+		}
+		u = carbonapiV3Encoder(u)
+		contentType, resp, err = b.post(ctx, u, body, "render")
+	} else {
+		u = carbonapiV2RenderEncoder(u, request.From, request.Until, request.Targets)
+		contentType, resp, err = b.call(ctx, u, "render")
+	}
 	if err != nil {
 		if code, ok := err.(ErrHTTPCode); ok && code == http.StatusNotFound {
 			return nil, types.ErrMetricsNotFound
@@ -259,8 +317,14 @@ func (b NetBackend) Render(ctx context.Context, request types.RenderRequest) ([]
 	var metrics []types.Metric
 
 	switch contentType {
+	case contentTypeV3:
+		metrics, err = carbonapi_v3.RenderDecoder(resp)
 	case "application/x-protobuf", "application/protobuf", "application/octet-stream":
-		metrics, err = carbonapi_v2.RenderDecoder(resp)
+		if b.protocol == ProtocolV3 {
+			metrics, err = carbonapi_v3.RenderDecoder(resp)
+		} else {
+			metrics, err = carbonapi_v2.RenderDecoder(resp)
+		}
 	case "application/text":
 		return nil, errors.Errorf("Unexpected application/text response:\n%s", string(resp))
 
@@ -295,6 +359,15 @@ func carbonapiV2RenderEncoder(u *url.URL, from int32, until int32, targets []str
 	return u
 }
 
+func carbonapiV3Encoder(u *url.URL) *url.URL {
+	vals := url.Values{
+		"format": fmtProtoV3,
+	}
+	u.RawQuery = vals.Encode()
+
+	return u
+}
+
 // Info fetches metadata about a metric from a backend.
 func (b NetBackend) Info(ctx context.Context, request types.InfoRequest) ([]types.Info, error) {
 	metric := request.Target
@@ -347,12 +420,22 @@ func carbonapiV2InfoEncoder(u *url.URL, metric string) *url.URL {
 
 // Find resolves globs and finds metrics in a backend.
 func (b NetBackend) Find(ctx context.Context, request types.FindRequest) (types.Matches, error) {
-	query := request.Query
-
+	var contentType string
+	var resp []byte
+	var err error
 	u := b.url("/metrics/find/")
-	u = carbonapiV2FindEncoder(u, query)
-
-	contentType, resp, err := b.call(ctx, u, "find")
+	if b.protocol == ProtocolV3 {
+		var body []byte
+		body, err = carbonapi_v3.FindRequestEncoder(request)
+		if err != nil {
+			return types.Matches{}, errors.Wrap(err, "Marshal failed")
+		}
+		u = carbonapiV3Encoder(u)
+		contentType, resp, err = b.post(ctx, u, body, "find")
+	} else {
+		u = carbonapiV2FindEncoder(u, request.Query)
+		contentType, resp, err = b.call(ctx, u, "find")
+	}
 	if err != nil {
 		if code, ok := err.(ErrHTTPCode); ok && code == http.StatusNotFound {
 			return types.Matches{}, types.ErrMatchesNotFound
@@ -364,8 +447,16 @@ func (b NetBackend) Find(ctx context.Context, request types.FindRequest) (types.
 	var matches types.Matches
 
 	switch contentType {
+	case contentTypeV3:
+		matches, err = carbonapi_v3.FindDecoder(resp)
+		matches.Name = request.Query
 	case "application/x-protobuf", "application/protobuf", "application/octet-stream":
-		matches, err = carbonapi_v2.FindDecoder(resp)
+		if b.protocol == ProtocolV3 {
+			matches, err = carbonapi_v3.FindDecoder(resp)
+			matches.Name = request.Query
+		} else {
+			matches, err = carbonapi_v2.FindDecoder(resp)
+		}
 	default:
 		return types.Matches{}, errors.Errorf("Unknown content type '%s'", contentType)
 	}
@@ -397,6 +488,112 @@ func carbonapiV2FindEncoder(u *url.URL, query string) *url.URL {
 	return u
 }
 
+// FindSeriesByTags finds the series matching all of the tag expressions in a backend.
+func (b NetBackend) FindSeriesByTags(ctx context.Context, request types.TagSeriesRequest) (types.Matches, error) {
+	u := b.url("/tags/findSeries")
+	u = tagSeriesEncoder(u, request.Exprs)
+
+	_, resp, err := b.call(ctx, u, "tags")
+	if err != nil {
+		if code, ok := err.(ErrHTTPCode); ok && code == http.StatusNotFound {
+			return types.Matches{}, types.ErrMatchesNotFound
+		}
+
+		return types.Matches{}, err
+	}
+
+	matches, err := json.TagSeriesDecoder(resp)
+	if err != nil {
+		return matches, errors.Wrap(err, "JSON unmarshal failed")
+	}
+	matches.Name = strings.Join(request.Exprs, ",")
+
+	if len(matches.Matches) == 0 {
+		return matches, types.ErrMatchesNotFound
+	}
+
+	for _, match := range matches.Matches {
+		b.cache.Set(match.Path, struct{}{}, 0, b.cacheExpirySec)
+	}
+
+	return matches, nil
+}
+
+func tagSeriesEncoder(u *url.URL, exprs []string) *url.URL {
+	vals := url.Values{
+		"expr": exprs,
+	}
+	u.RawQuery = vals.Encode()
+
+	return u
+}
+
+// Tags fetches tag names or values from a backend.
+func (b NetBackend) Tags(ctx context.Context, request types.TagsRequest) ([]types.TagValue, error) {
+	var u *url.URL
+	var decoder func([]byte) ([]types.TagValue, error)
+	switch request.Type {
+	case types.TagNames:
+		u, decoder = b.url("/tags"), json.TagNamesDecoder
+	case types.TagValues:
+		u, decoder = b.url("/tags/"+url.PathEscape(request.Tag)), json.TagValuesDecoder
+	case types.AutoCompleteTags:
+		u, decoder = b.url("/tags/autoComplete/tags"), json.AutoCompleteDecoder
+	case types.AutoCompleteValues:
+		u, decoder = b.url("/tags/autoComplete/values"), json.AutoCompleteDecoder
+	default:
+		return nil, errors.Errorf("Unknown tags request type %d", request.Type)
+	}
+	u = tagsEncoder(u, request)
+
+	_, resp, err := b.call(ctx, u, "tags")
+	if err != nil {
+		if code, ok := err.(ErrHTTPCode); ok && code == http.StatusNotFound {
+			return nil, types.ErrTagsNotFound
+		}
+
+		return nil, err
+	}
+
+	values, err := decoder(resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON unmarshal failed")
+	}
+
+	if len(values) == 0 {
+		return nil, types.ErrTagsNotFound
+	}
+
+	return values, nil
+}
+
+func tagsEncoder(u *url.URL, request types.TagsRequest) *url.URL {
+	vals := url.Values{}
+	switch request.Type {
+	case types.TagNames, types.TagValues:
+		if request.Filter != "" {
+			vals.Set("filter", request.Filter)
+		}
+	case types.AutoCompleteTags:
+		vals["expr"] = request.Exprs
+		if request.Prefix != "" {
+			vals.Set("tagPrefix", request.Prefix)
+		}
+	case types.AutoCompleteValues:
+		vals.Set("tag", request.Tag)
+		vals["expr"] = request.Exprs
+		if request.Prefix != "" {
+			vals.Set("valuePrefix", request.Prefix)
+		}
+	}
+	if request.Limit > 0 {
+		vals.Set("limit", strconv.Itoa(request.Limit))
+	}
+	u.RawQuery = vals.Encode()
+
+	return u
+}
+
 func (b NetBackend) BackendInfo() (addr string, cluster string, dc string) {
 	return b.address, b.cluster, b.dc
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -11,7 +12,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bookingcom/carbonapi/pkg/types"
+	"github.com/bookingcom/carbonapi/pkg/types/encoding/carbonapi_v3"
 	"github.com/dgryski/go-expirecache"
+	"github.com/go-graphite/protocol/carbonapi_v3_pb"
 )
 
 func TestAddress(t *testing.T) {
@@ -340,3 +344,198 @@ func TestCarbonapiv2FindEncoder(t *testing.T) {
 	}
 
 }
+
+func TestFindSeriesByTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tags/findSeries" {
+			http.Error(w, "Bad path", http.StatusNotFound)
+			return
+		}
+		exprs := r.URL.Query()["expr"]
+		if len(exprs) != 2 || exprs[0] != "name=cpu" || exprs[1] != "dc=~ams.*" {
+			http.Error(w, "Bad exprs", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`["cpu;dc=ams1","cpu;dc=ams2"]`))
+	}))
+	defer server.Close()
+
+	b, err := New(Config{
+		Address: server.URL,
+		Client:  server.Client(),
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	got, err := b.FindSeriesByTags(context.Background(), types.NewTagSeriesRequest([]string{"name=cpu", "dc=~ams.*"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Matches) != 2 || got.Matches[0].Path != "cpu;dc=ams1" || got.Matches[1].Path != "cpu;dc=ams2" {
+		t.Errorf("Bad matches: %v", got.Matches)
+	}
+
+	if ok := b.Contains([]string{"cpu;dc=ams1"}); !ok {
+		t.Error("Expected tagged series to be in the path cache")
+	}
+}
+
+func TestTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tags/dc":
+			w.Write([]byte(`{"tag":"dc","values":[{"count":2,"value":"ams"}]}`))
+		case "/tags/autoComplete/values":
+			if r.URL.Query().Get("tag") != "dc" || r.URL.Query().Get("valuePrefix") != "a" {
+				http.Error(w, "Bad query", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`["ams"]`))
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b, err := New(Config{
+		Address: server.URL,
+		Client:  server.Client(),
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	got, err := b.Tags(context.Background(), types.TagsRequest{Type: types.TagValues, Tag: "dc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Value != "ams" || got[0].Count != 2 {
+		t.Errorf("Bad tag values: %v", got)
+	}
+
+	got, err = b.Tags(context.Background(), types.TagsRequest{Type: types.AutoCompleteValues, Tag: "dc", Prefix: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Value != "ams" {
+		t.Errorf("Bad autocompleted values: %v", got)
+	}
+
+	_, err = b.Tags(context.Background(), types.TagsRequest{Type: types.TagNames})
+	if err != types.ErrTagsNotFound {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
+
+func TestUnknownProtocol(t *testing.T) {
+	if _, err := New(Config{Address: "localhost:8080", Protocol: "carbonapi_v4_pb"}); err == nil {
+		t.Error("Expected an error for an unknown protocol")
+	}
+}
+
+func TestRenderV3(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/render/" || r.URL.Query().Get("format") != ProtocolV3 {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request := carbonapi_v3_pb.MultiFetchRequest{}
+		if err := request.UnmarshalVT(body); err != nil {
+			t.Fatal(err)
+		}
+		if len(request.Metrics) != 2 || request.Metrics[1].PathExpression != "bar" || request.Metrics[1].MaxDataPoints != 10 {
+			http.Error(w, "Bad fetch requests", http.StatusBadRequest)
+			return
+		}
+
+		resp, err := carbonapi_v3.RenderEncoder([]types.Metric{
+			{Name: "foo", StartTime: 100, StopTime: 120, StepTime: 10, Values: []float64{1, 0}, IsAbsent: []bool{false, true}},
+			{Name: "bar", StartTime: 100, StopTime: 120, StepTime: 10, Values: []float64{2, 3}, IsAbsent: []bool{false, false}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/x-carbonapi-v3-pb")
+		w.Write(resp)
+	}))
+	defer server.Close()
+
+	b, err := New(Config{
+		Address:  server.URL,
+		Client:   server.Client(),
+		Protocol: ProtocolV3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := types.NewRenderRequest([]string{"foo", "bar"}, 100, 120)
+	request.MaxDataPoints = 10
+	got, err := b.Render(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 || got[0].Name != "foo" || !got[0].IsAbsent[1] || got[1].Values[1] != 3 {
+		t.Errorf("Bad metrics: %+v", got)
+	}
+
+	if ok := b.Contains([]string{"bar"}); !ok {
+		t.Error("Expected rendered metric to be in the path cache")
+	}
+}
+
+func TestFindV3(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request := carbonapi_v3_pb.MultiGlobRequest{}
+		if err := request.UnmarshalVT(body); err != nil {
+			t.Fatal(err)
+		}
+		if r.URL.Path != "/metrics/find/" || len(request.Metrics) != 1 || request.Metrics[0] != "foo.*" {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		resp, err := carbonapi_v3.FindEncoder(types.Matches{
+			Name:    "foo.*",
+			Matches: []types.Match{{Path: "foo.bar", IsLeaf: true}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/x-carbonapi-v3-pb")
+		w.Write(resp)
+	}))
+	defer server.Close()
+
+	b, err := New(Config{
+		Address:  server.URL,
+		Client:   server.Client(),
+		Protocol: ProtocolV3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := b.Find(context.Background(), types.NewFindRequest("foo.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != "foo.*" || len(got.Matches) != 1 || got.Matches[0].Path != "foo.bar" {
+		t.Errorf("Bad matches: %+v", got)
+	}
+}
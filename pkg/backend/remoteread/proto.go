@@ -0,0 +1,190 @@
+package remoteread
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The messages of the Prometheus remote-read protocol are small and stable,
+// so they are encoded by hand instead of depending on the Prometheus module.
+// See prompb/remote.proto and prompb/types.proto in the Prometheus repository.
+
+type matchType int
+
+const (
+	matchEqual matchType = iota
+	matchNotEqual
+	matchRegexp
+	matchNotRegexp
+)
+
+type matcher struct {
+	Type  matchType
+	Name  string
+	Value string
+}
+
+type query struct {
+	StartMs  int64
+	EndMs    int64
+	Matchers []matcher
+}
+
+type sample struct {
+	Value       float64
+	TimestampMs int64
+}
+
+type timeSeries struct {
+	Labels  map[string]string
+	Samples []sample
+}
+
+func encodeReadRequest(queries []query) []byte {
+	var b []byte
+	for _, q := range queries {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodeQuery(q))
+	}
+
+	return b
+}
+
+func encodeQuery(q query) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(q.StartMs))
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(q.EndMs))
+	for _, m := range q.Matchers {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodeMatcher(m))
+	}
+
+	return b
+}
+
+func encodeMatcher(m matcher) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(m.Type))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, m.Name)
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendString(b, m.Value)
+
+	return b
+}
+
+// decodeReadResponse returns the time series of every query result, in the order of the queries.
+func decodeReadResponse(b []byte) ([][]timeSeries, error) {
+	var results [][]timeSeries
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		result, err := decodeQueryResult(v)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+
+		return nil
+	})
+
+	return results, err
+}
+
+func decodeQueryResult(b []byte) ([]timeSeries, error) {
+	var series []timeSeries
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		ts, err := decodeTimeSeries(v)
+		if err != nil {
+			return err
+		}
+		series = append(series, ts)
+
+		return nil
+	})
+
+	return series, err
+}
+
+func decodeTimeSeries(b []byte) (timeSeries, error) {
+	ts := timeSeries{Labels: make(map[string]string)}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			var name, value string
+			err := consumeFields(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				switch {
+				case num == 1 && typ == protowire.BytesType:
+					name = string(v)
+				case num == 2 && typ == protowire.BytesType:
+					value = string(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Labels[name] = value
+		case 2:
+			var s sample
+			err := consumeFields(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				switch {
+				case num == 1 && typ == protowire.Fixed64Type:
+					u, _ := protowire.ConsumeFixed64(v)
+					s.Value = math.Float64frombits(u)
+				case num == 2 && typ == protowire.VarintType:
+					u, _ := protowire.ConsumeVarint(v)
+					s.TimestampMs = int64(u)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, s)
+		}
+
+		return nil
+	})
+
+	return ts, err
+}
+
+// consumeFields calls fn for every field of the message in b.
+// For length-delimited fields v is the payload, for the others it is the raw encoded value.
+func consumeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errors.Wrap(protowire.ParseError(n), "invalid tag")
+		}
+		b = b[n:]
+
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		if m < 0 {
+			return errors.Wrapf(protowire.ParseError(m), "invalid field %d", num)
+		}
+		v := b[:m]
+		if typ == protowire.BytesType {
+			v, _ = protowire.ConsumeBytes(v)
+		}
+		if err := fn(num, typ, v); err != nil {
+			return err
+		}
+		b = b[m:]
+	}
+
+	return nil
+}
@@ -0,0 +1,378 @@
+// Package remoteread implements a backend that reads metrics from a
+// Prometheus-compatible TSDB using the Prometheus remote-read protocol.
+// Graphite paths are mapped to Prometheus series by configurable rules.
+package remoteread
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	bnet "github.com/bookingcom/carbonapi/pkg/backend/net"
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/bookingcom/carbonapi/pkg/types"
+	"github.com/bookingcom/carbonapi/pkg/util"
+
+	"github.com/dgryski/go-expirecache"
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// RemoteReadBackend represents a Prometheus-compatible TSDB that accepts remote-read requests over HTTP.
+type RemoteReadBackend struct {
+	url            *url.URL
+	dc             string
+	cluster        string
+	client         *http.Client
+	timeout        time.Duration
+	step           time.Duration
+	findLookback   time.Duration
+	rules          []rule
+	cache          *expirecache.Cache
+	cacheExpirySec int32
+
+	responsesCount *prometheus.CounterVec
+
+	lg *zap.Logger
+}
+
+// Config configures a remote-read backend.
+//
+// The required fields are Address, which must be of the form
+// "[scheme://]address[:port]", and at least one rule in RemoteRead.
+type Config struct {
+	Address    string         // The backend address.
+	RemoteRead cfg.RemoteRead // The remote-read endpoint and the path mapping rules.
+
+	// Optional fields
+	DC                 string        // The DC where backend belongs to
+	Cluster            string        // The cluster where backend belongs to
+	Client             *http.Client  // The client to use to communicate with backend. Defaults to http.DefaultClient.
+	Timeout            time.Duration // Set request timeout. Defaults to no timeout.
+	PathCacheExpirySec uint32        // Set time in seconds before items in path cache expire. Defaults to 10 minutes.
+	Logger             *zap.Logger   // Logger to use. Defaults to a no-op logger.
+
+	Responses *prometheus.CounterVec
+}
+
+// New creates a new remote-read backend from the given configuration.
+func New(c Config) (*RemoteReadBackend, error) {
+	b := &RemoteReadBackend{
+		dc:             c.DC,
+		cluster:        c.Cluster,
+		client:         c.Client,
+		timeout:        c.Timeout,
+		step:           c.RemoteRead.Step,
+		findLookback:   c.RemoteRead.FindLookback,
+		cache:          expirecache.New(0),
+		cacheExpirySec: int32(c.PathCacheExpirySec),
+		responsesCount: c.Responses,
+		lg:             c.Logger,
+	}
+
+	address := c.Address
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = c.RemoteRead.Path
+	if u.Path == "" {
+		u.Path = "/api/v1/read"
+	}
+	b.url = u
+
+	if len(c.RemoteRead.Rules) == 0 {
+		return nil, errors.New("no remote-read rules configured")
+	}
+	for _, rc := range c.RemoteRead.Rules {
+		r, err := newRule(rc)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid remote-read rule")
+		}
+		b.rules = append(b.rules, r)
+	}
+
+	if b.step < time.Second {
+		b.step = time.Minute
+	}
+	if b.findLookback <= 0 {
+		b.findLookback = time.Hour
+	}
+	if b.cacheExpirySec <= 0 {
+		b.cacheExpirySec = int32(10 * time.Minute / time.Second)
+	}
+	if b.client == nil {
+		b.client = http.DefaultClient
+	}
+	if b.lg == nil {
+		b.lg = zap.New(nil)
+	}
+
+	return b, nil
+}
+
+// GetServerAddress returns the server address for this backend.
+func (b *RemoteReadBackend) GetServerAddress() string {
+	return b.url.Host
+}
+
+// Logger returns logger for this backend. Needed to satisfy interface.
+func (b *RemoteReadBackend) Logger() *zap.Logger {
+	return b.lg
+}
+
+func (b *RemoteReadBackend) BackendInfo() (addr string, cluster string, dc string) {
+	return b.url.Host, b.cluster, b.dc
+}
+
+// Contains reports whether the backend contains any of the given targets.
+func (b *RemoteReadBackend) Contains(targets []string) bool {
+	for _, target := range targets {
+		if _, ok := b.cache.Get(target); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// read sends the queries in a single remote-read request and returns the results in the order of the queries.
+func (b *RemoteReadBackend) read(ctx context.Context, queries []query, request string) ([][]timeSeries, error) {
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+
+	body := snappy.Encode(nil, encodeReadRequest(queries))
+	req, err := http.NewRequest("POST", b.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Read-Version", "0.1.0")
+	req = req.WithContext(ctx)
+	req = util.MarshalCtx(ctx, req)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		if b.responsesCount != nil {
+			b.responsesCount.WithLabelValues("http_client_error", request).Inc()
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if b.responsesCount != nil {
+			b.responsesCount.WithLabelValues("http_body_error", request).Inc()
+		}
+		return nil, err
+	}
+	if b.responsesCount != nil {
+		b.responsesCount.WithLabelValues(strconv.Itoa(resp.StatusCode), request).Inc()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, bnet.ErrHTTPCode(resp.StatusCode)
+	}
+
+	raw, err := snappy.Decode(nil, respBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "Snappy decode failed")
+	}
+	results, err := decodeReadResponse(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "Protobuf unmarshal failed")
+	}
+	if len(results) != len(queries) {
+		return nil, errors.Errorf("got %d results for %d queries", len(results), len(queries))
+	}
+
+	return results, nil
+}
+
+// Render fetches the series mapped from the targets and consolidates them to the configured step.
+func (b *RemoteReadBackend) Render(ctx context.Context, request types.RenderRequest) ([]types.Metric, error) {
+	step := int32(b.step / time.Second)
+	// The same alignment as whisper uses.
+	start := request.From - request.From%step + step
+	stop := request.Until - request.Until%step + step
+	if stop < start {
+		stop = start
+	}
+
+	var queries []query
+	var rules []rule
+	for _, target := range request.Targets {
+		nodes := parseNodes(target)
+		for _, r := range b.rules {
+			if matchers, ok := r.match(nodes, false); ok {
+				queries = append(queries, query{
+					StartMs:  int64(start) * 1000,
+					EndMs:    int64(stop)*1000 - 1,
+					Matchers: matchers,
+				})
+				rules = append(rules, r)
+				break
+			}
+		}
+	}
+	if len(queries) == 0 {
+		return nil, types.ErrMetricsNotFound
+	}
+
+	results, err := b.read(ctx, queries, "render")
+	if err != nil {
+		if code, ok := err.(bnet.ErrHTTPCode); ok && code == http.StatusNotFound {
+			return nil, types.ErrMetricsNotFound
+		}
+
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var metrics []types.Metric
+	for i, series := range results {
+		r := rules[i]
+		for _, ts := range series {
+			name, ok := r.path(ts.Labels, len(r.nodes))
+			if !ok || seen[name] {
+				continue
+			}
+			seen[name] = true
+			metrics = append(metrics, consolidate(name, ts.Samples, start, stop, step))
+		}
+	}
+
+	if len(metrics) == 0 {
+		return nil, types.ErrMetricsNotFound
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+
+	for _, metric := range metrics {
+		b.cache.Set(metric.Name, struct{}{}, 0, b.cacheExpirySec)
+	}
+
+	return metrics, nil
+}
+
+// consolidate puts the raw samples into the points of the given step.
+// The last sample within a step wins, as it would in whisper.
+func consolidate(name string, samples []sample, start, stop, step int32) types.Metric {
+	n := int((stop - start) / step)
+	m := types.Metric{
+		Name:      name,
+		StartTime: start,
+		StopTime:  stop,
+		StepTime:  step,
+		Values:    make([]float64, n),
+		IsAbsent:  make([]bool, n),
+	}
+	for i := range m.IsAbsent {
+		m.IsAbsent[i] = true
+	}
+
+	for _, s := range samples {
+		// NaN is used by Prometheus as a staleness marker.
+		if math.IsNaN(s.Value) {
+			continue
+		}
+		ts := int32(s.TimestampMs / 1000)
+		i := int((ts - ts%step - start) / step)
+		if i < 0 || i >= n {
+			continue
+		}
+		m.Values[i] = s.Value
+		m.IsAbsent[i] = false
+	}
+
+	return m
+}
+
+// Info is not supported by the remote-read protocol.
+func (b *RemoteReadBackend) Info(ctx context.Context, request types.InfoRequest) ([]types.Info, error) {
+	return nil, types.ErrInfoNotFound
+}
+
+// Find resolves globs against the series seen within the find lookback window.
+func (b *RemoteReadBackend) Find(ctx context.Context, request types.FindRequest) (types.Matches, error) {
+	nodes := parseNodes(request.Query)
+	now := time.Now()
+
+	var queries []query
+	var rules []rule
+	for _, r := range b.rules {
+		if matchers, ok := r.match(nodes, true); ok {
+			queries = append(queries, query{
+				StartMs:  now.Add(-b.findLookback).UnixNano() / int64(time.Millisecond),
+				EndMs:    now.UnixNano() / int64(time.Millisecond),
+				Matchers: matchers,
+			})
+			rules = append(rules, r)
+		}
+	}
+	if len(queries) == 0 {
+		return types.Matches{}, types.ErrMatchesNotFound
+	}
+
+	results, err := b.read(ctx, queries, "find")
+	if err != nil {
+		if code, ok := err.(bnet.ErrHTTPCode); ok && code == http.StatusNotFound {
+			return types.Matches{}, types.ErrMatchesNotFound
+		}
+
+		return types.Matches{}, err
+	}
+
+	matches := types.Matches{Name: request.Query}
+	seen := make(map[string]bool)
+	for i, series := range results {
+		r := rules[i]
+		isLeaf := len(nodes) == len(r.nodes)
+		for _, ts := range series {
+			path, ok := r.path(ts.Labels, len(nodes))
+			if !ok || seen[path] {
+				continue
+			}
+			seen[path] = true
+			matches.Matches = append(matches.Matches, types.Match{Path: path, IsLeaf: isLeaf})
+		}
+	}
+
+	if len(matches.Matches) == 0 {
+		return matches, types.ErrMatchesNotFound
+	}
+	sort.Slice(matches.Matches, func(i, j int) bool { return matches.Matches[i].Path < matches.Matches[j].Path })
+
+	for _, match := range matches.Matches {
+		if match.IsLeaf {
+			b.cache.Set(match.Path, struct{}{}, 0, b.cacheExpirySec)
+		}
+	}
+
+	return matches, nil
+}
+
+// FindSeriesByTags is not supported, graphite tags are not mapped to Prometheus labels.
+func (b *RemoteReadBackend) FindSeriesByTags(ctx context.Context, request types.TagSeriesRequest) (types.Matches, error) {
+	return types.Matches{}, types.ErrMatchesNotFound
+}
+
+// Tags is not supported, graphite tags are not mapped to Prometheus labels.
+func (b *RemoteReadBackend) Tags(ctx context.Context, request types.TagsRequest) ([]types.TagValue, error) {
+	return nil, types.ErrTagsNotFound
+}
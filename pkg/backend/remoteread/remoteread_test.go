@@ -0,0 +1,423 @@
+package remoteread
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	bnet "github.com/bookingcom/carbonapi/pkg/backend/net"
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/bookingcom/carbonapi/pkg/types"
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var testRules = cfg.RemoteRead{
+	Step: 10 * time.Second,
+	Rules: []cfg.RemoteReadRule{
+		{
+			Pattern: "prom.{instance}.cpu.{mode}",
+			Metric:  "node_cpu_seconds_total",
+			Labels:  map[string]string{"job": "node"},
+		},
+		{
+			Pattern: "prom.{instance}.{__name__}",
+		},
+	},
+}
+
+var testSeries = []timeSeries{
+	{
+		Labels: map[string]string{"__name__": "node_cpu_seconds_total", "job": "node", "instance": "host1", "mode": "idle"},
+		Samples: []sample{
+			{Value: 1, TimestampMs: 100000},
+			{Value: 2, TimestampMs: 105000}, // within the same step as the previous one
+			{Value: math.NaN(), TimestampMs: 110000},
+			{Value: 4, TimestampMs: 120000},
+		},
+	},
+	{
+		Labels:  map[string]string{"__name__": "node_cpu_seconds_total", "job": "node", "instance": "host1", "mode": "user"},
+		Samples: []sample{{Value: 5, TimestampMs: 100000}},
+	},
+	{
+		Labels:  map[string]string{"__name__": "node_cpu_seconds_total", "job": "other", "instance": "host2", "mode": "idle"},
+		Samples: []sample{{Value: 6, TimestampMs: 100000}},
+	},
+	{
+		Labels:  map[string]string{"__name__": "up", "instance": "host2.example.com"},
+		Samples: []sample{{Value: 1, TimestampMs: 100000}},
+	},
+}
+
+// newTestServer returns a stand-in remote-read server that serves the given series.
+func newTestServer(t *testing.T, series []timeSeries) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/read" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Content-Encoding") != "snappy" {
+			t.Errorf("Expected snappy encoded request, got '%s'", r.Header.Get("Content-Encoding"))
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := snappy.Decode(nil, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		queries, err := decodeReadRequest(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var results [][]timeSeries
+		for _, q := range queries {
+			var result []timeSeries
+			for _, ts := range series {
+				if selects(q, ts) {
+					result = append(result, ts)
+				}
+			}
+			results = append(results, result)
+		}
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.Header().Set("Content-Encoding", "snappy")
+		_, _ = w.Write(snappy.Encode(nil, encodeReadResponse(results)))
+	}))
+}
+
+func selects(q query, ts timeSeries) bool {
+	for _, m := range q.Matchers {
+		value := ts.Labels[m.Name]
+		switch m.Type {
+		case matchEqual:
+			if value != m.Value {
+				return false
+			}
+		case matchRegexp:
+			if !regexp.MustCompile("^(?:" + m.Value + ")$").MatchString(value) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func decodeReadRequest(b []byte) ([]query, error) {
+	var queries []query
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var q query
+		err := consumeFields(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+			switch num {
+			case 1:
+				u, _ := protowire.ConsumeVarint(v)
+				q.StartMs = int64(u)
+			case 2:
+				u, _ := protowire.ConsumeVarint(v)
+				q.EndMs = int64(u)
+			case 3:
+				var m matcher
+				err := consumeFields(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+					switch num {
+					case 1:
+						u, _ := protowire.ConsumeVarint(v)
+						m.Type = matchType(u)
+					case 2:
+						m.Name = string(v)
+					case 3:
+						m.Value = string(v)
+					}
+					return nil
+				})
+				q.Matchers = append(q.Matchers, m)
+				return err
+			}
+			return nil
+		})
+		queries = append(queries, q)
+		return err
+	})
+
+	return queries, err
+}
+
+func encodeReadResponse(results [][]timeSeries) []byte {
+	var b []byte
+	for _, result := range results {
+		var qr []byte
+		for _, ts := range result {
+			var tsb []byte
+			for name, value := range ts.Labels {
+				var lb []byte
+				lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+				lb = protowire.AppendString(lb, name)
+				lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+				lb = protowire.AppendString(lb, value)
+				tsb = protowire.AppendTag(tsb, 1, protowire.BytesType)
+				tsb = protowire.AppendBytes(tsb, lb)
+			}
+			for _, s := range ts.Samples {
+				var sb []byte
+				sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+				sb = protowire.AppendFixed64(sb, math.Float64bits(s.Value))
+				sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+				sb = protowire.AppendVarint(sb, uint64(s.TimestampMs))
+				tsb = protowire.AppendTag(tsb, 2, protowire.BytesType)
+				tsb = protowire.AppendBytes(tsb, sb)
+			}
+			qr = protowire.AppendTag(qr, 1, protowire.BytesType)
+			qr = protowire.AppendBytes(qr, tsb)
+		}
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, qr)
+	}
+
+	return b
+}
+
+func newTestBackend(t *testing.T, server *httptest.Server) *RemoteReadBackend {
+	b, err := New(Config{
+		Address:    server.URL,
+		RemoteRead: testRules,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestRender(t *testing.T) {
+	server := newTestServer(t, testSeries)
+	defer server.Close()
+	b := newTestBackend(t, server)
+
+	metrics, err := b.Render(context.Background(), types.NewRenderRequest([]string{"prom.*.cpu.{idle,user}"}, 95, 125))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []types.Metric{
+		{
+			Name:      "prom.host1.cpu.idle",
+			StartTime: 100,
+			StopTime:  130,
+			StepTime:  10,
+			Values:    []float64{2, 0, 4},
+			IsAbsent:  []bool{false, true, false},
+		},
+		{
+			Name:      "prom.host1.cpu.user",
+			StartTime: 100,
+			StopTime:  130,
+			StepTime:  10,
+			Values:    []float64{5, 0, 0},
+			IsAbsent:  []bool{false, true, true},
+		},
+	}
+	if !reflect.DeepEqual(metrics, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, metrics)
+	}
+
+	if !b.Contains([]string{"prom.host1.cpu.idle"}) {
+		t.Error("Expected the rendered metric to be in the path cache")
+	}
+}
+
+func TestRenderMetricNamePlaceholder(t *testing.T) {
+	server := newTestServer(t, testSeries)
+	defer server.Close()
+	b := newTestBackend(t, server)
+
+	metrics, err := b.Render(context.Background(), types.NewRenderRequest([]string{"prom.*.up"}, 95, 105))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(metrics) != 1 || metrics[0].Name != "prom.host2_example_com.up" {
+		t.Errorf("Expected a single metric prom.host2_example_com.up, got %+v", metrics)
+	}
+}
+
+func TestFindThenRenderDottedLabel(t *testing.T) {
+	server := newTestServer(t, testSeries)
+	defer server.Close()
+	b := newTestBackend(t, server)
+
+	matches, err := b.Find(context.Background(), types.NewFindRequest("prom.host2_*.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches.Matches) != 1 || matches.Matches[0].Path != "prom.host2_example_com.up" {
+		t.Fatalf("Expected the single match prom.host2_example_com.up, got %+v", matches.Matches)
+	}
+
+	metrics, err := b.Render(context.Background(), types.NewRenderRequest([]string{matches.Matches[0].Path}, 95, 105))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 1 || metrics[0].Name != matches.Matches[0].Path {
+		t.Errorf("Expected the found metric to be rendered, got %+v", metrics)
+	}
+}
+
+func TestRenderNotFound(t *testing.T) {
+	server := newTestServer(t, testSeries)
+	defer server.Close()
+	b := newTestBackend(t, server)
+
+	for _, target := range []string{"graphite.host1.cpu.idle", "prom.host3.cpu.idle"} {
+		_, err := b.Render(context.Background(), types.NewRenderRequest([]string{target}, 95, 125))
+		if err != types.ErrMetricsNotFound {
+			t.Errorf("Expected ErrMetricsNotFound for %s, got %v", target, err)
+		}
+	}
+}
+
+func TestRenderServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	b := newTestBackend(t, server)
+
+	_, err := b.Render(context.Background(), types.NewRenderRequest([]string{"prom.host1.cpu.idle"}, 95, 125))
+	if code, ok := err.(bnet.ErrHTTPCode); !ok || code != http.StatusInternalServerError {
+		t.Errorf("Expected HTTP 500 error, got %v", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	server := newTestServer(t, testSeries)
+	defer server.Close()
+	b := newTestBackend(t, server)
+
+	tests := []struct {
+		query    string
+		expected []types.Match
+	}{
+		{
+			query: "prom.*",
+			expected: []types.Match{
+				{Path: "prom.host1", IsLeaf: false},
+				{Path: "prom.host2", IsLeaf: false},
+				{Path: "prom.host2_example_com", IsLeaf: false},
+			},
+		},
+		{
+			query: "prom.host1.cpu.*",
+			expected: []types.Match{
+				{Path: "prom.host1.cpu.idle", IsLeaf: true},
+				{Path: "prom.host1.cpu.user", IsLeaf: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			matches, err := b.Find(context.Background(), types.NewFindRequest(test.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(matches.Matches, test.expected) {
+				t.Errorf("Expected %+v, got %+v", test.expected, matches.Matches)
+			}
+		})
+	}
+}
+
+func TestRuleMatch(t *testing.T) {
+	r, err := newRule(testRules.Rules[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		prefix   bool
+		ok       bool
+		expected []matcher
+	}{
+		{
+			path: "prom.host1.cpu.idle",
+			ok:   true,
+			expected: []matcher{
+				{Type: matchEqual, Name: "__name__", Value: "node_cpu_seconds_total"},
+				{Type: matchEqual, Name: "job", Value: "node"},
+				{Type: matchEqual, Name: "instance", Value: "host1"},
+				{Type: matchEqual, Name: "mode", Value: "idle"},
+			},
+		},
+		{
+			path: "pro?.host[0-9].c*.{idle,user}",
+			ok:   true,
+			expected: []matcher{
+				{Type: matchEqual, Name: "__name__", Value: "node_cpu_seconds_total"},
+				{Type: matchEqual, Name: "job", Value: "node"},
+				{Type: matchRegexp, Name: "instance", Value: "host[0-9]"},
+				{Type: matchRegexp, Name: "mode", Value: "(?:idle|user)"},
+			},
+		},
+		{
+			path:   "prom.*",
+			prefix: true,
+			ok:     true,
+			expected: []matcher{
+				{Type: matchEqual, Name: "__name__", Value: "node_cpu_seconds_total"},
+				{Type: matchEqual, Name: "job", Value: "node"},
+				{Type: matchRegexp, Name: "instance", Value: ".+"},
+			},
+		},
+		{
+			path: "prom.10_0_0_1:9100.cpu.i*_*",
+			ok:   true,
+			expected: []matcher{
+				{Type: matchEqual, Name: "__name__", Value: "node_cpu_seconds_total"},
+				{Type: matchEqual, Name: "job", Value: "node"},
+				{Type: matchRegexp, Name: "instance", Value: "10[._]0[._]0[._]1:9100"},
+				{Type: matchRegexp, Name: "mode", Value: "i.*[._].*"},
+			},
+		},
+		{path: "prom.*", ok: false},
+		{path: "prom.host1.mem.free", ok: false},
+		{path: "prom.host1.cpu.idle.extra", prefix: true, ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			matchers, ok := r.match(parseNodes(test.path), test.prefix)
+			if ok != test.ok {
+				t.Fatalf("Expected match %v, got %v", test.ok, ok)
+			}
+			if !reflect.DeepEqual(matchers, test.expected) {
+				t.Errorf("Expected %+v, got %+v", test.expected, matchers)
+			}
+		})
+	}
+}
+
+func TestNewInvalidRules(t *testing.T) {
+	tests := []cfg.RemoteRead{
+		{},
+		{Rules: []cfg.RemoteReadRule{{Pattern: "prom.{instance}"}}},
+		{Rules: []cfg.RemoteReadRule{{Pattern: "prom.*.{instance}", Metric: "up"}}},
+		{Rules: []cfg.RemoteReadRule{{Pattern: "prom.{}", Metric: "up"}}},
+	}
+
+	for _, test := range tests {
+		if _, err := New(Config{Address: "localhost:9090", RemoteRead: test}); err == nil {
+			t.Errorf("Expected an error for %+v", test)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package remoteread
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/pkg/errors"
+)
+
+const metricNameLabel = "__name__"
+
+// rule is a compiled cfg.RemoteReadRule.
+type rule struct {
+	nodes  []string // The literal nodes of the pattern. Empty for placeholders.
+	labels []string // The labels captured by the placeholders. Empty for literals.
+	extra  []matcher
+}
+
+func newRule(c cfg.RemoteReadRule) (rule, error) {
+	if c.Pattern == "" {
+		return rule{}, errors.New("empty pattern")
+	}
+
+	var r rule
+	hasName := c.Metric != ""
+	for _, node := range strings.Split(c.Pattern, ".") {
+		if strings.HasPrefix(node, "{") && strings.HasSuffix(node, "}") {
+			label := node[1 : len(node)-1]
+			if label == "" {
+				return rule{}, errors.Errorf("empty placeholder in pattern %q", c.Pattern)
+			}
+			hasName = hasName || label == metricNameLabel
+			r.nodes = append(r.nodes, "")
+			r.labels = append(r.labels, label)
+		} else {
+			if node == "" || hasGlob(node) {
+				return rule{}, errors.Errorf("invalid node %q in pattern %q", node, c.Pattern)
+			}
+			r.nodes = append(r.nodes, node)
+			r.labels = append(r.labels, "")
+		}
+	}
+	if !hasName {
+		return rule{}, errors.Errorf("pattern %q has neither a metric nor a {%s} placeholder", c.Pattern, metricNameLabel)
+	}
+
+	if c.Metric != "" {
+		r.extra = append(r.extra, matcher{Type: matchEqual, Name: metricNameLabel, Value: c.Metric})
+	}
+	names := make([]string, 0, len(c.Labels))
+	for name := range c.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r.extra = append(r.extra, matcher{Type: matchEqual, Name: name, Value: c.Labels[name]})
+	}
+
+	return r, nil
+}
+
+// node is a node of a requested path, with its glob compiled once for all the rules.
+type node struct {
+	text string
+	glob *regexp.Regexp // nil for the literal nodes
+}
+
+// parseNodes splits the requested path into its nodes.
+func parseNodes(path string) []node {
+	parts := strings.Split(path, ".")
+	nodes := make([]node, len(parts))
+	for i, part := range parts {
+		nodes[i].text = part
+		if hasGlob(part) {
+			// an invalid glob matches nothing
+			re, err := regexp.Compile("^(?:" + globToRegexp(part, false) + ")$")
+			if err != nil {
+				re = regexp.MustCompile(`[^\s\S]`)
+			}
+			nodes[i].glob = re
+		}
+	}
+
+	return nodes
+}
+
+// match returns the label matchers selecting the series for the given path nodes.
+// If prefix is set, the nodes may match only the beginning of the pattern.
+func (r rule) match(nodes []node, prefix bool) ([]matcher, bool) {
+	if len(nodes) > len(r.nodes) || (!prefix && len(nodes) < len(r.nodes)) {
+		return nil, false
+	}
+
+	matchers := append([]matcher{}, r.extra...)
+	for i, n := range nodes {
+		if r.labels[i] == "" {
+			if !n.matches(r.nodes[i]) {
+				return nil, false
+			}
+			continue
+		}
+
+		// the underscores might stand for the dots of the label values, see path,
+		// which the metric names do not have
+		dotted := r.labels[i] != metricNameLabel && strings.Contains(n.text, "_")
+		switch {
+		case n.text == "*":
+			matchers = append(matchers, matcher{Type: matchRegexp, Name: r.labels[i], Value: ".+"})
+		case n.glob == nil && !dotted:
+			matchers = append(matchers, matcher{Type: matchEqual, Name: r.labels[i], Value: n.text})
+		default:
+			matchers = append(matchers, matcher{Type: matchRegexp, Name: r.labels[i], Value: globToRegexp(n.text, dotted)})
+		}
+	}
+
+	return matchers, true
+}
+
+// path builds the graphite path of the first n nodes of the pattern for a series with the given labels.
+// Dots in the label values are replaced to keep the number of nodes intact.
+func (r rule) path(labels map[string]string, n int) (string, bool) {
+	nodes := make([]string, n)
+	for i := 0; i < n; i++ {
+		if r.labels[i] == "" {
+			nodes[i] = r.nodes[i]
+			continue
+		}
+
+		value, ok := labels[r.labels[i]]
+		if !ok || value == "" {
+			return "", false
+		}
+		nodes[i] = strings.Replace(value, ".", "_", -1)
+	}
+
+	return strings.Join(nodes, "."), true
+}
+
+func hasGlob(node string) bool {
+	return strings.ContainsAny(node, "*?[{")
+}
+
+// matches reports whether the literal node of a pattern is matched by the requested node.
+func (n node) matches(literal string) bool {
+	if n.glob == nil {
+		return n.text == literal
+	}
+
+	return n.glob.MatchString(literal)
+}
+
+// globToRegexp converts a graphite glob node to a regular expression in RE2 syntax,
+// which is what Prometheus uses for its regexp matchers.
+// If dotted is set, the underscores match the dots as well.
+func globToRegexp(glob string, dotted bool) string {
+	var sb strings.Builder
+	inBraces := false
+	inBrackets := false
+	for _, c := range glob {
+		switch {
+		case inBrackets:
+			if c == ']' {
+				inBrackets = false
+			}
+			sb.WriteRune(c)
+		case c == '[':
+			inBrackets = true
+			sb.WriteRune(c)
+		case c == '*':
+			sb.WriteString(".*")
+		case c == '?':
+			sb.WriteString(".")
+		case c == '{':
+			inBraces = true
+			sb.WriteString("(?:")
+		case c == '}' && inBraces:
+			inBraces = false
+			sb.WriteString(")")
+		case c == ',' && inBraces:
+			sb.WriteString("|")
+		case c == '_' && dotted:
+			sb.WriteString("[._]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return sb.String()
+}
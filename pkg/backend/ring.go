@@ -0,0 +1,231 @@
+package backend
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+)
+
+const (
+	// The positions of each node on the carbon_ch and fnv1a_ch rings, as in the relays.
+	ringReplicas = 100
+
+	// The characters of the glob patterns, which cannot be hashed to their owners.
+	globChars = "*?[{"
+)
+
+// Ring is the consistent hash ring of a cluster fed by a hashing relay.
+// It tells the nodes of the cluster that own a metric.
+type Ring struct {
+	hash              string
+	replicationFactor int
+	nodes             []cfg.RoutingNode
+
+	// the sorted positions and their nodes of the carbon_ch and fnv1a_ch rings
+	positions []int
+	owners    []int
+
+	// the nodes sorted by instance for the jump_fnv1a_ch hash
+	jumpNodes []int
+}
+
+// NewRing makes the hash ring of a cluster. It returns nil when the config is nil.
+func NewRing(config *cfg.Routing) (*Ring, error) {
+	if config == nil {
+		return nil, nil
+	}
+	if len(config.Nodes) == 0 {
+		return nil, fmt.Errorf("routing with %s without nodes", config.Hash)
+	}
+
+	r := &Ring{
+		hash:              config.Hash,
+		replicationFactor: config.ReplicationFactor,
+		nodes:             config.Nodes,
+	}
+	if r.replicationFactor <= 0 {
+		r.replicationFactor = 1
+	}
+	if r.replicationFactor > len(r.nodes) {
+		return nil, fmt.Errorf("replication factor %d is greater than the %d nodes", r.replicationFactor, len(r.nodes))
+	}
+
+	switch r.hash {
+	case "carbon_ch", "fnv1a_ch":
+		r.buildRing()
+	case "jump_fnv1a_ch":
+		r.jumpNodes = make([]int, len(r.nodes))
+		for i := range r.jumpNodes {
+			r.jumpNodes[i] = i
+		}
+		sort.SliceStable(r.jumpNodes, func(i, j int) bool {
+			return jumpKey(r.nodes[r.jumpNodes[i]]) < jumpKey(r.nodes[r.jumpNodes[j]])
+		})
+	default:
+		return nil, fmt.Errorf("unknown routing hash %q", r.hash)
+	}
+
+	return r, nil
+}
+
+// buildRing places each node at its positions on the ring the way graphite's ConsistentHashRing does,
+// a position already taken is moved to the next free one.
+func (r *Ring) buildRing() {
+	taken := make(map[int]bool)
+	type entry struct {
+		position int
+		node     int
+	}
+	entries := make([]entry, 0, len(r.nodes)*ringReplicas)
+	for n, node := range r.nodes {
+		for i := 0; i < ringReplicas; i++ {
+			position := r.position(r.replicaKey(node, i))
+			for taken[position] {
+				position++
+			}
+			taken[position] = true
+			entries = append(entries, entry{position: position, node: n})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].position < entries[j].position })
+	r.positions = make([]int, len(entries))
+	r.owners = make([]int, len(entries))
+	for i, e := range entries {
+		r.positions[i] = e.position
+		r.owners[i] = e.node
+	}
+}
+
+// replicaKey is the key of the i-th position of the node, which is hashed to place it on the ring.
+func (r *Ring) replicaKey(node cfg.RoutingNode, i int) string {
+	if r.hash == "fnv1a_ch" {
+		if node.Instance != "" {
+			return fmt.Sprintf("%d-%s", i, node.Instance)
+		}
+		return fmt.Sprintf("%d-%s", i, node.Server)
+	}
+
+	// the string of the python tuple (server, instance)
+	if node.Instance != "" {
+		return fmt.Sprintf("('%s', '%s'):%d", node.Server, node.Instance, i)
+	}
+	return fmt.Sprintf("('%s', None):%d", node.Server, i)
+}
+
+// position returns the position of the key on the carbon_ch or fnv1a_ch ring.
+func (r *Ring) position(key string) int {
+	if r.hash == "fnv1a_ch" {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		sum := h.Sum32()
+		return int((sum >> 16) ^ (sum & 0xffff))
+	}
+
+	sum := md5.Sum([]byte(key))
+	return int(binary.BigEndian.Uint16(sum[:2]))
+}
+
+func jumpKey(node cfg.RoutingNode) string {
+	if node.Instance != "" {
+		return node.Instance
+	}
+	return node.Server
+}
+
+// jumpBucket is the jump consistent hash of the key to one of the buckets, as in carbon-c-relay.
+func jumpBucket(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// Owners returns the indexes of the nodes that own the metric.
+func (r *Ring) Owners(metric string) []int {
+	owners := make([]int, 0, r.replicationFactor)
+
+	if r.hash == "jump_fnv1a_ch" {
+		h := fnv.New64a()
+		h.Write([]byte(metric))
+		// the replicas are the next nodes in the sorted list
+		bucket := jumpBucket(h.Sum64(), len(r.jumpNodes))
+		for i := 0; i < r.replicationFactor; i++ {
+			owners = append(owners, r.jumpNodes[(bucket+i)%len(r.jumpNodes)])
+		}
+		return owners
+	}
+
+	// the replicas are the next distinct nodes on the ring
+	position := r.position(metric)
+	i := sort.Search(len(r.positions), func(i int) bool { return r.positions[i] >= position })
+	seen := make(map[int]bool, r.replicationFactor)
+	for n := 0; n < len(r.positions) && len(owners) < r.replicationFactor; n++ {
+		owner := r.owners[(i+n)%len(r.positions)]
+		if !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+
+	return owners
+}
+
+// Backends returns the addresses of the backends of the nodes of the ring.
+func (r *Ring) Backends() []string {
+	if r == nil {
+		return nil
+	}
+	backends := make([]string, len(r.nodes))
+	for i, node := range r.nodes {
+		backends[i] = node.Backend
+	}
+	return backends
+}
+
+// Route returns the route of the backend with the address in the ring, nil when it is not one of its nodes.
+func (r *Ring) Route(address string) *Route {
+	if r == nil {
+		return nil
+	}
+	for i, node := range r.nodes {
+		if node.Backend == address {
+			return &Route{ring: r, node: i}
+		}
+	}
+	return nil
+}
+
+// Route is the place of a backend in the hash ring of its cluster.
+// A nil *Route owns all the metrics.
+type Route struct {
+	ring *Ring
+	node int
+}
+
+// Owns reports whether the backend owns any of the targets.
+// The glob patterns cannot be hashed, the backends own all of them.
+func (r *Route) Owns(targets []string) bool {
+	if r == nil {
+		return true
+	}
+	for _, target := range targets {
+		if strings.ContainsAny(target, globChars) {
+			return true
+		}
+		for _, owner := range r.ring.Owners(target) {
+			if owner == r.node {
+				return true
+			}
+		}
+	}
+	return false
+}
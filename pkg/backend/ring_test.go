@@ -0,0 +1,121 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+)
+
+func routingNodes(instances ...string) []cfg.RoutingNode {
+	servers := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"}
+	nodes := make([]cfg.RoutingNode, 0, len(instances))
+	for i, instance := range instances {
+		nodes = append(nodes, cfg.RoutingNode{
+			Server:   servers[i],
+			Instance: instance,
+			Backend:  "http://" + servers[i] + ":8080",
+		})
+	}
+	return nodes
+}
+
+// The expected owners are computed with graphite's ConsistentHashRing and carbon-c-relay's jump hash.
+func TestRingOwners(t *testing.T) {
+	metrics := []string{"carbon.agents.host1.cpuUsage", "servers.web01.loadavg.01", "a", "foo.bar.baz"}
+	tests := []struct {
+		name     string
+		config   cfg.Routing
+		expected [][]int
+	}{
+		{
+			name:     "carbon_ch",
+			config:   cfg.Routing{Hash: "carbon_ch", ReplicationFactor: 2, Nodes: routingNodes("", "", "", "")},
+			expected: [][]int{{1, 0}, {1, 3}, {1, 3}, {1, 2}},
+		},
+		{
+			name:     "carbon_ch with instances",
+			config:   cfg.Routing{Hash: "carbon_ch", Nodes: routingNodes("a", "b")},
+			expected: [][]int{{1}, {0}, {0}, {1}},
+		},
+		{
+			name:     "fnv1a_ch",
+			config:   cfg.Routing{Hash: "fnv1a_ch", ReplicationFactor: 2, Nodes: routingNodes("a", "b", "c")},
+			expected: [][]int{{0, 1}, {1, 2}, {0, 2}, {0, 2}},
+		},
+		{
+			name:     "jump_fnv1a_ch",
+			config:   cfg.Routing{Hash: "jump_fnv1a_ch", ReplicationFactor: 2, Nodes: routingNodes("e", "d", "c", "b", "a")},
+			expected: [][]int{{4, 3}, {1, 0}, {2, 1}, {4, 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRing(&tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, metric := range metrics {
+				if got := r.Owners(metric); !reflect.DeepEqual(got, tt.expected[i]) {
+					t.Errorf("expected the owners %v of %s, got %v", tt.expected[i], metric, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRingErrors(t *testing.T) {
+	if r, err := NewRing(nil); r != nil || err != nil {
+		t.Errorf("expected no ring without config, got %v and %v", r, err)
+	}
+
+	for _, config := range []cfg.Routing{
+		{Hash: "carbon_ch"},
+		{Hash: "unknown", Nodes: routingNodes("")},
+		{Hash: "carbon_ch", ReplicationFactor: 2, Nodes: routingNodes("")},
+	} {
+		if _, err := NewRing(&config); err == nil {
+			t.Errorf("expected an error for %+v", config)
+		}
+	}
+}
+
+func TestFilterOwners(t *testing.T) {
+	r, err := NewRing(&cfg.Routing{Hash: "carbon_ch", ReplicationFactor: 2, Nodes: routingNodes("", "", "", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var backends []Backend
+	for i, node := range routingNodes("", "", "", "") {
+		backends = append(backends, Backend{
+			BackendImpl: healthTestBackend{addr: node.Backend, cluster: "routed"},
+			route:       r.Route(node.Backend),
+		})
+		if backends[i].route == nil {
+			t.Fatalf("expected a route for %s", node.Backend)
+		}
+	}
+	backends = append(backends, Backend{BackendImpl: healthTestBackend{addr: "other", cluster: "other"}})
+
+	addrs := func(bs []Backend) []string {
+		var addrs []string
+		for _, b := range bs {
+			addr, _, _ := b.BackendInfo()
+			addrs = append(addrs, addr)
+		}
+		return addrs
+	}
+
+	got, _ := Filter(backends, []string{"servers.web01.loadavg.01"})
+	expected := []string{"http://10.0.0.2:8080", "http://10.0.0.4:8080", "other"}
+	if !reflect.DeepEqual(addrs(got), expected) {
+		t.Errorf("expected the backends %v, got %v", expected, addrs(got))
+	}
+
+	got, _ = Filter(backends, []string{"servers.*.loadavg.01"})
+	if len(got) != len(backends) {
+		t.Errorf("expected all the backends for a glob, got %v", addrs(got))
+	}
+}
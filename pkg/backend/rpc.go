@@ -15,21 +15,36 @@ import (
 // replicaMatchMode indicates how data points of the metrics fetched from replicas
 // will be checked and applied on the final metrics. replicaMismatchReportLimit limits
 // the number of mismatched metrics reported in log for each render request.
+// The replicas of the hedged clusters are requested one after the other, see hedgedRender.
 func Renders(ctx context.Context, backends []Backend, request types.RenderRequest,
 	replicaMismatchConfig cfg.RenderReplicaMismatchConfig, lg *zap.Logger) ([]types.Metric, types.MetricRenderStats, []error) {
 	if len(backends) == 0 {
 		return nil, types.MetricRenderStats{}, nil
 	}
 
-	msgCh := make(chan []types.Metric, len(backends))
-	errCh := make(chan error, len(backends))
-	for _, backend := range backends {
+	direct, hedged := splitHedged(backends)
+
+	type hedgedResults struct {
+		msgs [][]types.Metric
+		errs []error
+	}
+	hedgedCh := make(chan hedgedResults, len(hedged))
+	for _, replicas := range hedged {
+		go func(replicas []Backend) {
+			msgs, errs := hedgedRender(ctx, replicas[0].hedger, replicas, request)
+			hedgedCh <- hedgedResults{msgs: msgs, errs: errs}
+		}(replicas)
+	}
+
+	msgCh := make(chan []types.Metric, len(direct))
+	errCh := make(chan error, len(direct))
+	for _, backend := range direct {
 		backend.SendRender(ctx, request, msgCh, errCh)
 	}
 
 	msgs := make([][]types.Metric, 0, len(backends))
 	errs := make([]error, 0, len(backends))
-	for i := 0; i < len(backends); i++ {
+	for i := 0; i < len(direct); i++ {
 		select {
 		case msg := <-msgCh:
 			msgs = append(msgs, msg)
@@ -37,6 +52,11 @@ func Renders(ctx context.Context, backends []Backend, request types.RenderReques
 			errs = append(errs, err)
 		}
 	}
+	for i := 0; i < len(hedged); i++ {
+		r := <-hedgedCh
+		msgs = append(msgs, r.msgs...)
+		errs = append(errs, r.errs...)
+	}
 
 	metrics, stats := types.MergeMetrics(msgs, replicaMismatchConfig, lg)
 	return metrics, stats, errs
@@ -94,12 +114,79 @@ func Finds(ctx context.Context, backends []Backend, request types.FindRequest, d
 	return types.MergeMatches(msgs), errs
 }
 
+// SeriesByTags makes FindSeriesByTags calls to multiple backends.
+func SeriesByTags(ctx context.Context, backends []Backend, request types.TagSeriesRequest) (types.Matches, []error) {
+	if len(backends) == 0 {
+		return types.Matches{}, nil
+	}
+
+	msgCh := make(chan types.Matches, len(backends))
+	errCh := make(chan error, len(backends))
+	for _, backend := range backends {
+		backend.SendFindSeriesByTags(ctx, request, msgCh, errCh)
+	}
+
+	msgs := make([]types.Matches, 0, len(backends))
+	errs := make([]error, 0, len(backends))
+	for i := 0; i < len(backends); i++ {
+		select {
+		case msg := <-msgCh:
+			msgs = append(msgs, msg)
+		case err := <-errCh:
+			errs = append(errs, err)
+		}
+	}
+
+	return types.MergeMatches(msgs), errs
+}
+
+// Tags makes Tags calls to multiple backends.
+func Tags(ctx context.Context, backends []Backend, request types.TagsRequest) ([]types.TagValue, []error) {
+	if len(backends) == 0 {
+		return nil, nil
+	}
+
+	msgCh := make(chan []types.TagValue, len(backends))
+	errCh := make(chan error, len(backends))
+	for _, backend := range backends {
+		backend.SendTags(ctx, request, msgCh, errCh)
+	}
+
+	msgs := make([][]types.TagValue, 0, len(backends))
+	errs := make([]error, 0, len(backends))
+	for i := 0; i < len(backends); i++ {
+		select {
+		case msg := <-msgCh:
+			msgs = append(msgs, msg)
+		case err := <-errCh:
+			errs = append(errs, err)
+		}
+	}
+
+	return types.MergeTagValues(msgs, request.Limit), errs
+}
+
 // Filter filters the given backends by whether they Contain() the given targets.
+// The backends of the routed clusters that do not own the targets are left out first,
+// and the unhealthy backends last, unless they are all the backends of their cluster.
 func Filter(backends []Backend, targets []string) ([]Backend, bool) {
-	if bs := filter(backends, targets); len(bs) > 0 {
-		return bs, true
+	backends = filterOwners(backends, targets)
+	bs, ok := filter(backends, targets), true
+	if len(bs) == 0 {
+		bs, ok = backends, false
+	}
+	return FilterHealthy(bs), ok
+}
+
+func filterOwners(backends []Backend, targets []string) []Backend {
+	bs := make([]Backend, 0, len(backends))
+	for _, b := range backends {
+		if b.route.Owns(targets) {
+			bs = append(bs, b)
+		}
 	}
-	return backends, false
+
+	return bs
 }
 
 func filter(backends []Backend, targets []string) []Backend {
@@ -112,3 +199,31 @@ func filter(backends []Backend, targets []string) []Backend {
 
 	return bs
 }
+
+// FilterHealthy leaves out the unavailable backends, but never the last ones of a cluster:
+// when all the backends of a cluster are unavailable, they are all kept.
+// It filters the requests that have no targets to route by, such as the tag requests.
+func FilterHealthy(backends []Backend) []Backend {
+	available := make([]bool, len(backends))
+	clusterAvailable := make(map[string]bool)
+	ejected := false
+	for i, b := range backends {
+		_, cluster, _ := b.BackendInfo()
+		available[i] = b.health.Available()
+		clusterAvailable[cluster] = clusterAvailable[cluster] || available[i]
+		ejected = ejected || !available[i]
+	}
+	if !ejected {
+		return backends
+	}
+
+	bs := make([]Backend, 0, len(backends))
+	for i, b := range backends {
+		_, cluster, _ := b.BackendInfo()
+		if available[i] || !clusterAvailable[cluster] {
+			bs = append(bs, b)
+		}
+	}
+
+	return bs
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is the cache backed by a single Redis instance, a Sentinel-managed master or a Redis cluster.
+// The gets time out the same way as in MemcachedCache.
+type RedisCache struct {
+	prefix    string
+	client    redis.UniversalClient
+	timeoutMs uint64
+
+	reqCount     *prometheus.CounterVec
+	timeoutCount prometheus.Counter
+}
+
+// RedisConfig selects the Redis setup. With a master name the servers are the Sentinels,
+// with several servers and no master name they are the nodes of a cluster.
+type RedisConfig struct {
+	Servers    []string
+	MasterName string
+	Password   string
+	DB         int
+}
+
+// NewRedis creates a Redis cache. The reqCount metric is partitioned by the operation and the status.
+func NewRedis(prefix string, timeoutMs uint64, config RedisConfig,
+	reqCount *prometheus.CounterVec, timeoutCount prometheus.Counter) BytesCache {
+	return &RedisCache{
+		prefix:    prefix,
+		timeoutMs: timeoutMs,
+		client: redis.NewUniversalClient(&redis.UniversalOptions{
+			Addrs:      config.Servers,
+			MasterName: config.MasterName,
+			Password:   config.Password,
+			DB:         config.DB,
+			// the query timeout is applied through the context of the gets
+			ContextTimeoutEnabled: true,
+		}),
+		reqCount:     reqCount,
+		timeoutCount: timeoutCount,
+	}
+}
+
+func (r *RedisCache) Get(k string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.timeoutMs)*time.Millisecond)
+	defer cancel()
+
+	v, err := r.client.Get(ctx, r.prefix+getCacheHashKey(k)).Bytes()
+	if err != nil {
+		switch {
+		case errors.Is(err, redis.Nil):
+			r.reqCount.With(prometheus.Labels{"operation": "get", "status": "not_found"}).Inc()
+			return nil, ErrNotFound
+		case errors.Is(err, context.DeadlineExceeded):
+			r.timeoutCount.Inc()
+			r.reqCount.With(prometheus.Labels{"operation": "get", "status": "timeout"}).Inc()
+			return nil, ErrTimeout
+		}
+		r.reqCount.With(prometheus.Labels{"operation": "get", "status": "error"}).Inc()
+		return nil, err
+	}
+
+	r.reqCount.With(prometheus.Labels{"operation": "get", "status": "ok"}).Inc()
+	return v, nil
+}
+
+// Set is not bound by the query timeout, as the callers run it in the background.
+func (r *RedisCache) Set(k string, v []byte, expire int32) error {
+	err := r.client.Set(context.Background(), r.prefix+getCacheHashKey(k), v, time.Duration(expire)*time.Second).Err()
+	if err != nil {
+		r.reqCount.With(prometheus.Labels{"operation": "set", "status": "error"}).Inc()
+		return err
+	}
+
+	r.reqCount.With(prometheus.Labels{"operation": "set", "status": "ok"}).Inc()
+	return nil
+}
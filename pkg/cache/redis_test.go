@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestRedis(t *testing.T, timeoutMs uint64, servers ...string) (*RedisCache, *prometheus.CounterVec, prometheus.Counter) {
+	reqs := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "reqs"}, []string{"operation", "status"})
+	timeouts := prometheus.NewCounter(prometheus.CounterOpts{Name: "timeouts"})
+
+	c := NewRedis("capi", timeoutMs, RedisConfig{Servers: servers}, reqs, timeouts).(*RedisCache)
+	t.Cleanup(func() { c.client.Close() })
+
+	return c, reqs, timeouts
+}
+
+func TestRedisGetSet(t *testing.T) {
+	s := miniredis.RunT(t)
+	c, reqs, _ := newTestRedis(t, 1000, s.Addr())
+
+	if _, err := c.Get("foo"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := c.Set("foo", []byte("bar"), 60); err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Get("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(v, []byte("bar")) {
+		t.Errorf("got %q, expected %q", v, "bar")
+	}
+
+	if !s.Exists("capi" + getCacheHashKey("foo")) {
+		t.Error("expected the key to be hashed and prefixed")
+	}
+
+	s.FastForward(61 * time.Second)
+	if _, err := c.Get("foo"); err != ErrNotFound {
+		t.Errorf("expected the key to expire, got %v", err)
+	}
+
+	if n := testutil.ToFloat64(reqs.WithLabelValues("get", "ok")); n != 1 {
+		t.Errorf("expected 1 successful get, got %v", n)
+	}
+	if n := testutil.ToFloat64(reqs.WithLabelValues("get", "not_found")); n != 2 {
+		t.Errorf("expected 2 not-found gets, got %v", n)
+	}
+	if n := testutil.ToFloat64(reqs.WithLabelValues("set", "ok")); n != 1 {
+		t.Errorf("expected 1 set, got %v", n)
+	}
+}
+
+func TestRedisGetTimeout(t *testing.T) {
+	// the server accepts the connections but never replies
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	c, _, timeouts := newTestRedis(t, 50, l.Addr().String())
+
+	start := time.Now()
+	if _, err := c.Get("foo"); err != ErrTimeout {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("expected the get to time out after 50ms, it took %v", d)
+	}
+	if n := testutil.ToFloat64(timeouts); n != 1 {
+		t.Errorf("expected 1 timeout, got %v", n)
+	}
+}
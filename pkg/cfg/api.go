@@ -68,6 +68,7 @@ func DefaultAPIConfig() API {
 			Prefix:                "capi",
 			MemcachedTimeoutMs:    1000,
 			MemcachedMaxIdleConns: 50,
+			StreamedBodyLimitKB:   1024,
 		},
 		// This is an intentionally large number as an intermediate refactored state.
 		// This effectively turns off the queue size limitation.
@@ -145,6 +146,10 @@ type API struct {
 	// Config to ensure we return version needed for providing integrated graphite docs in grafana
 	// without supporting tags
 	GraphiteVersionForGrafana string `yaml:"graphiteVersionForGrafana"`
+	// Makes the rendered series keep the xFilesFactor of their storage schema, as reported by the
+	// carbonapi_v3_pb backends, for their consolidation, summarize and the moving functions.
+	// By default the series have the xFilesFactor 0 of graphite-web unless setXFilesFactor sets one.
+	StorageXFilesFactor bool `yaml:"storageXFilesFactor"`
 
 	// The size of the requests queue propagated to backends.
 	// During this stage of refactoring it is a placeholder and should not fill-up.
@@ -168,7 +173,7 @@ type API struct {
 
 // CacheConfig configs the cache
 type CacheConfig struct {
-	// possible values are: null, mem, memcache, replicatedMemcache
+	// possible values are: null, mem, memcache, replicatedMemcache, redis
 	Type string `yaml:"type"`
 	// Cache limit in megabytes
 	Size             int      `yaml:"size_mb"`
@@ -180,6 +185,22 @@ type CacheConfig struct {
 	Prefix                string `yaml:"prefix"`
 	MemcachedTimeoutMs    int    `yaml:"memcachedTimeoutMs"`
 	MemcachedMaxIdleConns int    `yaml:"memcachedMaxIdleConns"`
+	// Redis servers, or Sentinels if the master name is set.
+	// Several servers without a master name are the nodes of a Redis cluster.
+	RedisServers    []string `yaml:"redisServers"`
+	RedisMasterName string   `yaml:"redisMasterName"`
+	RedisPassword   string   `yaml:"redisPassword"`
+	RedisDB         int      `yaml:"redisDB"`
+	// The json, csv and raw render responses are streamed to the client. They are cached
+	// only if the body is not larger than this, in kilobytes.
+	StreamedBodyLimitKB int `yaml:"streamedBodyLimitKb"`
+	// The fetched series of single metrics are cached for this long, in seconds. 0 disables the metric cache.
+	MetricCacheTimeoutSec int32 `yaml:"metricCacheTimeoutSec"`
+	// Metric and delta cache limit in megabytes, for the mem cache type.
+	MetricCacheSize int `yaml:"metricCacheSize_mb"`
+	// The last fetched window of each metric is remembered for this long, in seconds, so that
+	// the refreshes of relative ranges fetch only the new tail. 0 disables the delta cache.
+	DeltaCacheTimeoutSec int32 `yaml:"deltaCacheTimeoutSec"`
 }
 
 type preAPI struct {
@@ -61,6 +61,22 @@ func DefaultCommonConfig() Common {
 		// at least for now.
 		BackendQueueSize: 100000,
 
+		BackendHealth: BackendHealth{
+			Window:          10 * time.Second,
+			MinRequests:     20,
+			MaxFailureRatio: 0.5,
+			EjectionTime:    30 * time.Second,
+			MaxEjectionTime: 5 * time.Minute,
+		},
+		RenderBatch: RenderBatch{
+			MaxURLLength: 8000,
+		},
+		AdaptiveLimit: AdaptiveLimit{
+			MinLimit:  1,
+			Tolerance: DefaultAdaptiveLimitTolerance,
+			Backoff:   DefaultAdaptiveLimitBackoff,
+		},
+
 		ExpireDelaySec:       int32(10 * time.Minute / time.Second),
 		InternalRoutingCache: int32(5 * time.Minute / time.Second),
 
@@ -191,6 +207,16 @@ type Common struct {
 	BackendQueueSize             int `yaml:"backendQueueSize"`
 	BackendMaxConcurrentRequests int `yaml:"backendMaxConcurrentRequests"`
 
+	// BackendHealth configures the ejection of unhealthy backends from the requests.
+	BackendHealth BackendHealth `yaml:"backendHealth"`
+
+	// RenderBatch bounds the render requests to a backend that are batched into a single one.
+	RenderBatch RenderBatch `yaml:"renderBatch"`
+
+	// AdaptiveLimit configures how the concurrency limits of the backends, and of the upstream
+	// requests of carbonapi, adapt to the latency below their configured maximum, when enabled.
+	AdaptiveLimit AdaptiveLimit `yaml:"adaptiveLimit"`
+
 	ExpireDelaySec           int32    `yaml:"expireDelaySec"`
 	InternalRoutingCache     int32    `yaml:"internalRoutingCache"`
 	TLDCacheExtraPrefixes    []string `yaml:"tldCacheExtraPrefixes"`
@@ -285,6 +311,45 @@ func (common Common) GetBackends() []ProtocolBackend {
 	return backends
 }
 
+// HedgeOfBackend returns the hedge config of the cluster of a given backend address, nil when it has none
+func (common Common) HedgeOfBackend(address string) *Hedge {
+	if cluster := common.clusterOfBackend(address); cluster != nil {
+		return cluster.Hedge
+	}
+	return nil
+}
+
+// RoutingOfBackend returns the routing config of the cluster of a given backend address, nil when it has none
+func (common Common) RoutingOfBackend(address string) *Routing {
+	if cluster := common.clusterOfBackend(address); cluster != nil {
+		return cluster.Routing
+	}
+	return nil
+}
+
+func (common Common) clusterOfBackend(address string) *Cluster {
+	var clusters []Cluster
+	for _, dc := range common.BackendsByDC {
+		clusters = append(clusters, dc.Clusters...)
+	}
+	clusters = append(clusters, common.BackendsByCluster...)
+
+	for i, cluster := range clusters {
+		for _, backend := range cluster.Backends {
+			if backend == address {
+				return &clusters[i]
+			}
+		}
+		for _, backend := range cluster.ProtocolBackends {
+			if backend.Http == address || backend.Grpc == address {
+				return &clusters[i]
+			}
+		}
+	}
+
+	return nil
+}
+
 // InfoOfBackend returns the dc and cluster of a given backend address from common configuration
 func (common Common) InfoOfBackend(address string) (string, string, error) {
 	for _, dc := range common.BackendsByDC {
@@ -371,9 +436,103 @@ type Timeouts struct {
 	Connect      time.Duration `yaml:"connect"`
 }
 
+// BackendHealth configures the tracking of the backends health.
+// A backend that fails too many of its requests in a window is ejected from the requests
+// for a while, after which a single probe request decides whether it is back.
+type BackendHealth struct {
+	// Enabled turns the ejection of the unhealthy backends on.
+	Enabled bool `yaml:"enabled"`
+
+	// Window is the period over which the outcomes of the requests are counted.
+	Window time.Duration `yaml:"window"`
+
+	// MinRequests is the number of requests in a window below which a backend is not judged.
+	MinRequests int `yaml:"minRequests"`
+
+	// MaxFailureRatio is the ratio of failed requests in a window above which a backend is ejected.
+	MaxFailureRatio float64 `yaml:"maxFailureRatio"`
+
+	// SlowRequest is the duration above which a successful request counts as failed.
+	// Zero, the default, only counts the errors and timeouts.
+	SlowRequest time.Duration `yaml:"slowRequest"`
+
+	// EjectionTime is how long a backend is ejected for, doubled on each consecutive ejection
+	// up to MaxEjectionTime.
+	EjectionTime    time.Duration `yaml:"ejectionTime"`
+	MaxEjectionTime time.Duration `yaml:"maxEjectionTime"`
+}
+
+// RenderBatch configures the batching of the render requests queued for a backend.
+// The requests of the same render with the same time range are sent as one multi-target request.
+type RenderBatch struct {
+	// MaxTargets is the maximum number of targets in a batch, 1 or less, the default, disables the batching.
+	MaxTargets int `yaml:"maxTargets"`
+
+	// MaxURLLength is the maximum length of the URL of a batched request, zero is no limit.
+	MaxURLLength int `yaml:"maxURLLength"`
+}
+
+// The defaults of the adaptive concurrency limits.
+const (
+	DefaultAdaptiveLimitTolerance = 2
+	DefaultAdaptiveLimitBackoff   = 0.9
+)
+
+// AdaptiveLimit configures the adaptive concurrency limits.
+// A limit starts at its maximum, shrinks when the requests slow down or time out, and grows back
+// when they are served in time.
+type AdaptiveLimit struct {
+	// Enabled makes the limits adapt. When disabled, the default, they are fixed at their maximum.
+	Enabled bool `yaml:"enabled"`
+
+	// MinLimit is the limit below which the concurrency never shrinks.
+	MinLimit int `yaml:"minLimit"`
+
+	// Tolerance is the ratio of the short term to the long term average latency above which
+	// the requests are slow.
+	Tolerance float64 `yaml:"tolerance"`
+
+	// Backoff is the factor the limit shrinks by on a slow request.
+	Backoff float64 `yaml:"backoff"`
+}
+
 type ProtocolBackend struct {
 	Http string `yaml:"http"`
 	Grpc string `yaml:"grpc"`
+
+	// Protocol is the format of the HTTP render and find requests, either
+	// carbonapi_v2_pb (default) or carbonapi_v3_pb. The latter sends all the
+	// targets and maxDataPoints in a single request, as graphite-clickhouse expects.
+	Protocol string `yaml:"protocol"`
+
+	// RemoteRead makes the backend speak the Prometheus remote-read protocol
+	// at the Http address instead of the graphite protocol.
+	RemoteRead *RemoteRead `yaml:"remoteRead"`
+}
+
+// RemoteRead configures a Prometheus remote-read backend.
+type RemoteRead struct {
+	// Path of the remote-read endpoint. Defaults to /api/v1/read.
+	Path string `yaml:"path"`
+	// Step is the resolution the raw samples are consolidated to. Defaults to 1 minute.
+	Step time.Duration `yaml:"step"`
+	// FindLookback is how far back find requests look for series. Defaults to 1 hour.
+	FindLookback time.Duration `yaml:"findLookback"`
+	// Rules map graphite paths to Prometheus series. The first matching rule wins.
+	Rules []RemoteReadRule `yaml:"rules"`
+}
+
+// RemoteReadRule maps the graphite paths matching Pattern to a Prometheus series.
+//
+// Pattern is a dot-separated path where every node is either a literal or a
+// {label} placeholder that captures the node as the value of the label.
+// For example, the pattern "prom.{instance}.cpu.{mode}" with the metric
+// "node_cpu_seconds_total" maps "prom.host1.cpu.idle" to
+// node_cpu_seconds_total{instance="host1",mode="idle"}.
+type RemoteReadRule struct {
+	Pattern string            `yaml:"pattern"`
+	Metric  string            `yaml:"metric"` // Optional if the pattern captures __name__.
+	Labels  map[string]string `yaml:"labels"` // Extra label matchers added to every query.
 }
 
 // Cluster is a definition for set of backends
@@ -382,6 +541,53 @@ type Cluster struct {
 	Backends []string `yaml:"backends"`
 	// New field for backward-compatibility
 	ProtocolBackends []ProtocolBackend `yaml:"protocolBackends"`
+
+	// Hedge enables the hedged render requests to the backends of the cluster,
+	// which are then expected to be replicas of each other.
+	Hedge *Hedge `yaml:"hedge"`
+
+	// Routing sends the requests for a metric only to the backends of the cluster that own it,
+	// as the relay feeding them hashes the metrics.
+	Routing *Routing `yaml:"routing"`
+}
+
+// Routing configures the consistent hashing of the metrics to the backends of a cluster.
+// It has to reproduce the config of the relay (carbon-c-relay, carbon-relay-ng or carbon-relay)
+// that feeds the cluster.
+type Routing struct {
+	// Hash is the hash ring of the relay: carbon_ch, fnv1a_ch or jump_fnv1a_ch.
+	Hash string `yaml:"hash"`
+
+	// ReplicationFactor is the number of backends each metric is sent to. Defaults to 1.
+	ReplicationFactor int `yaml:"replicationFactor"`
+
+	// Nodes are the destinations of the relay, in the order of its config.
+	Nodes []RoutingNode `yaml:"nodes"`
+}
+
+// RoutingNode is a destination of the relay.
+type RoutingNode struct {
+	// Server and Instance are the host, without the port, and the optional instance
+	// of the destination as they are in the relay config.
+	Server   string `yaml:"server"`
+	Instance string `yaml:"instance"`
+
+	// Backend is the http address of the backend of the destination.
+	Backend string `yaml:"backend"`
+}
+
+// Hedge configures the hedged render requests to a cluster.
+// A render is sent to one replica, and to the next one only if it has not answered
+// within a percentile of the recent render latencies of the cluster.
+type Hedge struct {
+	// Percentile is the percentile of the latencies after which the next replica is requested.
+	// Defaults to 95.
+	Percentile float64 `yaml:"percentile"`
+
+	// MinDelay and MaxDelay bound the delay before the next replica is requested.
+	// MaxDelay is also the delay while too few latencies are known. It defaults to 1s.
+	MinDelay time.Duration `yaml:"minDelay"`
+	MaxDelay time.Duration `yaml:"maxDelay"`
 }
 
 // DC is a definition for data-cemter with set of clusters
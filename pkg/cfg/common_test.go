@@ -641,3 +641,36 @@ func eqCommon(a, b Common) bool {
 	return toComparableCommon(a) == toComparableCommon(b) &&
 		reflect.DeepEqual(a.GetBackends(), b.GetBackends())
 }
+
+func TestHedgeOfBackend(t *testing.T) {
+	var input = `
+backendsByDC:
+    - name: "dc1"
+      clusters:
+          - name: "hedged"
+            hedge:
+                percentile: 99
+                maxDelay: "500ms"
+            protocolBackends:
+              - http: "http://10.0.0.1:8080"
+                grpc: "10.0.0.1:7004"
+          - name: "plain"
+            backends:
+              - "http://10.0.0.2:8080"
+`
+
+	got, err := ParseCommon(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Hedge{Percentile: 99, MaxDelay: 500 * time.Millisecond}
+	for _, address := range []string{"http://10.0.0.1:8080", "10.0.0.1:7004"} {
+		if hedge := got.HedgeOfBackend(address); !reflect.DeepEqual(hedge, expected) {
+			t.Errorf("expected the hedge config %+v of %s, got %+v", expected, address, hedge)
+		}
+	}
+	if hedge := got.HedgeOfBackend("http://10.0.0.2:8080"); hedge != nil {
+		t.Errorf("expected no hedge config, got %+v", hedge)
+	}
+}
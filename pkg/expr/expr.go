@@ -12,6 +12,7 @@ import (
 	"github.com/bookingcom/carbonapi/pkg/expr/types"
 	"github.com/bookingcom/carbonapi/pkg/parser"
 	dataTypes "github.com/bookingcom/carbonapi/pkg/types"
+	"github.com/bookingcom/carbonapi/pkg/util"
 )
 
 type evaluator struct{}
@@ -41,7 +42,7 @@ func EvalExpr(ctx context.Context, e parser.Expr, from, until int32, values map[
 	}
 
 	if e.IsName() {
-		val := values[parser.MetricRequest{Metric: e.Target(), From: from, Until: until}]
+		val := values[parser.MetricRequest{Metric: e.Target(), From: from, Until: until, MaxDataPoints: util.GetMaxDataPoints(ctx)}]
 		if val == nil {
 			return nil, parser.ErrSeriesDoesNotExist
 		}
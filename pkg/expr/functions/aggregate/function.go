@@ -0,0 +1,86 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type aggregate struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &aggregate{}
+	for _, n := range []string{"aggregate"} {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// aggregate(seriesList, func, xFilesFactor=None)
+func (f *aggregate) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := e.GetStringArg(1)
+	if err != nil {
+		return nil, err
+	}
+	// sumSeries and friends are accepted as well
+	callback = strings.TrimSuffix(callback, "Series")
+	aggFunc, ok := types.GetAggregateFunction(callback)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported aggregation function %s", parser.ErrInvalidArgumentValue, callback)
+	}
+
+	xFilesFactor, err := e.GetFloatNamedOrPosArgDefault("xFilesFactor", 2, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%sSeries(%s)", callback, e.Args()[0].ToString())
+	return helper.AggregateSeries(name, args, false, false, float32(xFilesFactor), helper.SeriesAggregation(aggFunc, len(args)))
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *aggregate) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"aggregate": {
+			Description: "Aggregate series using the specified function.\n\nExample:\n\n.. code-block:: none\n\n  &target=aggregate(host.cpu-[0-7].cpu-{user,system}.value, \"sum\")\n\nThis would be the equivalent of\n\n.. code-block:: none\n\n  &target=sumSeries(host.cpu-[0-7].cpu-{user,system}.value)\n\nThis function can be used with aggregation functions ``average``, ``median``, ``sum``, ``min``,\n``max``, ``diff``, ``stddev``, ``count``, ``range``, ``multiply`` & ``last``.",
+			Function:    "aggregate(seriesList, func, xFilesFactor=None)",
+			Group:       "Combine",
+			Module:      "graphite.render.functions",
+			Name:        "aggregate",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "func",
+					Required: true,
+					Options:  types.AggregateFunctionNames(),
+					Type:     types.AggFunc,
+				},
+				{
+					Name: "xFilesFactor",
+					Type: types.Float,
+				},
+			},
+		},
+	}
+}
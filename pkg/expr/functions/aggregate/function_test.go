@@ -0,0 +1,160 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	metrics := func() map[parser.MetricRequest][]*types.MetricData {
+		return map[parser.MetricRequest][]*types.MetricData{
+			{"metric[123]", 0, 1, 0}: {
+				types.MakeMetricData("metric1", []float64{1, math.NaN(), 2, 3, 4, 5}, 1, now32),
+				types.MakeMetricData("metric2", []float64{2, math.NaN(), 3, math.NaN(), 5, 6}, 1, now32),
+				types.MakeMetricData("metric3", []float64{3, math.NaN(), 4, 5, 6, math.NaN()}, 1, now32),
+			},
+		}
+	}
+
+	tests := []th.EvalTestItem{
+		{
+			"aggregate(metric[123],'average')",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("averageSeries(metric[123])",
+				[]float64{2, math.NaN(), 3, 4, 5, 5.5}, 1, now32)},
+		},
+		{
+			"aggregate(metric[123],'sumSeries')",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("sumSeries(metric[123])",
+				[]float64{6, math.NaN(), 9, 8, 15, 11}, 1, now32)},
+		},
+		{
+			"aggregate(metric[123],'avg_zero')",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("avg_zeroSeries(metric[123])",
+				[]float64{2, math.NaN(), 3, 8.0 / 3, 5, 11.0 / 3}, 1, now32)},
+		},
+		{
+			"aggregate(metric[123],'median')",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("medianSeries(metric[123])",
+				[]float64{2, math.NaN(), 3, 4, 5, 5.5}, 1, now32)},
+		},
+		{
+			"aggregate(metric[123],'diff')",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("diffSeries(metric[123])",
+				[]float64{-4, math.NaN(), -5, -2, -7, -1}, 1, now32)},
+		},
+		{
+			"aggregate(metric[123],'range')",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("rangeSeries(metric[123])",
+				[]float64{2, math.NaN(), 2, 2, 2, 1}, 1, now32)},
+		},
+		{
+			"aggregate(metric[123],'multiply')",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("multiplySeries(metric[123])",
+				[]float64{6, math.NaN(), 24, 15, 120, 30}, 1, now32)},
+		},
+		{
+			"aggregate(metric[123],'count')",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("countSeries(metric[123])",
+				[]float64{3, math.NaN(), 3, 2, 3, 2}, 1, now32)},
+		},
+		{
+			"aggregate(metric[123],'stddev')",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("stddevSeries(metric[123])",
+				[]float64{math.Sqrt(2.0 / 3), math.NaN(), math.Sqrt(2.0 / 3), 1, math.Sqrt(2.0 / 3), 0.5}, 1, now32)},
+		},
+		{
+			"aggregate(metric[123],'last',0.7)",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("lastSeries(metric[123])",
+				[]float64{3, math.NaN(), 4, math.NaN(), 6, math.NaN()}, 1, now32)},
+		},
+		{
+			"aggregate(metric[123],'max',xFilesFactor=0.5)",
+			metrics(),
+			[]*types.MetricData{types.MakeMetricData("maxSeries(metric[123])",
+				[]float64{3, math.NaN(), 4, 5, 6, 6}, 1, now32)},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		testName := tt.Target
+		t.Run(testName, func(t *testing.T) {
+			th.TestEvalExpr(t, &tt)
+		})
+	}
+}
+
+func TestAggregateUnknownFunction(t *testing.T) {
+	exp, _, err := parser.ParseExpr("aggregate(metric1,'foo')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[parser.MetricRequest][]*types.MetricData{
+		{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 2, 3}, 1, 0)},
+	}
+
+	_, err = metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1, values, th.NoopGetTargetData)
+	if !errors.Is(err, parser.ErrInvalidArgumentValue) {
+		t.Errorf("got the error %v, want %v", err, parser.ErrInvalidArgumentValue)
+	}
+}
+
+func TestAggregateSeriesXFilesFactor(t *testing.T) {
+	exp, _, err := parser.ParseExpr("aggregate(metric[12],'sum')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metric1 := types.MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN()}, 1, 0)
+	metric1.XFilesFactor = 0.6
+	metric2 := types.MakeMetricData("metric2", []float64{2, 3, math.NaN()}, 1, 0)
+	values := map[parser.MetricRequest][]*types.MetricData{
+		{"metric[12]", 0, 1, 0}: {metric1, metric2},
+	}
+
+	got, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1, values, th.NoopGetTargetData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the xFilesFactor of the series is not a default for the aggregation, as in graphite-web
+	want := types.MakeMetricData("sumSeries(metric[12])", []float64{3, 3, math.NaN()}, 1, 0)
+	if len(got) != 1 || !th.NearlyEqualMetrics(got[0], want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got[0].XFilesFactor != 0 {
+		t.Errorf("got the xFilesFactor %v, want 0", got[0].XFilesFactor)
+	}
+}
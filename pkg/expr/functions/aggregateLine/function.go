@@ -0,0 +1,121 @@
+package aggregateLine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	dataTypes "github.com/bookingcom/carbonapi/pkg/types"
+)
+
+type aggregateLine struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &aggregateLine{}
+	for _, n := range []string{"aggregateLine"} {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// aggregateLine(seriesList, func='average', keepStep=False)
+func (f *aggregateLine) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := e.GetStringNamedOrPosArgDefault("func", 1, "average")
+	if err != nil {
+		return nil, err
+	}
+	aggFunc, ok := types.GetAggregateFunction(callback)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported aggregation function %s", parser.ErrInvalidArgumentValue, callback)
+	}
+
+	keepStep, err := e.GetBoolNamedOrPosArgDefault("keepStep", 2, false)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*types.MetricData, 0, len(args))
+	for _, a := range args {
+		value, absent := aggFunc(a.Values, a.IsAbsent)
+
+		var name string
+		if absent {
+			value = 0
+			name = fmt.Sprintf("aggregateLine(%s, None)", a.Name)
+		} else {
+			name = fmt.Sprintf("aggregateLine(%s, %g)", a.Name, value)
+		}
+
+		if keepStep {
+			r := *a
+			r.Name = name
+			r.Values = make([]float64, len(a.Values))
+			r.IsAbsent = make([]bool, len(a.Values))
+			for i := range r.Values {
+				r.Values[i] = value
+				r.IsAbsent[i] = absent
+			}
+			results = append(results, &r)
+			continue
+		}
+
+		results = append(results, &types.MetricData{
+			Metric: dataTypes.Metric{
+				Name:      name,
+				StartTime: from,
+				StopTime:  until,
+				StepTime:  (until - from) / 2,
+				Values:    []float64{value, value, value},
+				IsAbsent:  []bool{absent, absent, absent},
+			},
+		})
+	}
+
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *aggregateLine) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"aggregateLine": {
+			Description: "Takes a metric or wildcard seriesList and draws a horizontal line\nbased on the function applied to each series.\n\nIf the optional keepStep parameter is set to True, the result will\nhave the same time period and step as the source series.\n\nNote: By default, the graphite renderer consolidates data points by\naveraging data points over time. If you are using the 'min' or 'max'\nfunction for aggregateLine, this can cause an unusual gap in the\nline drawn by this function and the data itself. To fix this, you\nshould use the consolidateBy() function with the same function\nargument you are using for aggregateLine. This will ensure that the\nproper data points are retained and the graph should line up\ncorrectly.\n\nExample:\n\n.. code-block:: none\n\n  &target=aggregateLine(server01.connections.total, 'avg')\n  &target=aggregateLine(server*.connections.total, 'avg')",
+			Function:    "aggregateLine(seriesList, func='average', keepStep=False)",
+			Group:       "Calculate",
+			Module:      "graphite.render.functions",
+			Name:        "aggregateLine",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Default: types.NewSuggestion("average"),
+					Name:    "func",
+					Options: types.AggregateFunctionNames(),
+					Type:    types.AggFunc,
+				},
+				{
+					Default: types.NewSuggestion(false),
+					Name:    "keepStep",
+					Type:    types.Boolean,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package aggregateLine
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestAggregateLine(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+	from, until := now32, now32+4
+
+	metric1 := func(values ...float64) map[parser.MetricRequest][]*types.MetricData {
+		return map[parser.MetricRequest][]*types.MetricData{
+			{Metric: "metric1", From: from, Until: until}: {types.MakeMetricData("metric1", values, 1, now32)},
+		}
+	}
+
+	tests := []th.EvalTestItem{
+		{
+			"aggregateLine(metric1)",
+			metric1(1, math.NaN(), 2, 3, 4),
+			[]*types.MetricData{types.MakeMetricData("aggregateLine(metric1, 2.5)", []float64{2.5, 2.5, 2.5}, 2, from)},
+		},
+		{
+			"aggregateLine(metric1,'max',keepStep=true)",
+			metric1(1, math.NaN(), 2, 3, 4),
+			[]*types.MetricData{types.MakeMetricData("aggregateLine(metric1, 4)", []float64{4, 4, 4, 4, 4}, 1, now32)},
+		},
+		{
+			"aggregateLine(metric1,'sum')",
+			metric1(math.NaN(), math.NaN()),
+			[]*types.MetricData{types.MakeMetricData("aggregateLine(metric1, None)", []float64{math.NaN(), math.NaN(), math.NaN()}, 2, from)},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.Target, func(t *testing.T) {
+			exp, _, err := parser.ParseExpr(tt.Target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, from, until, tt.M, th.NoopGetTargetData)
+			if err != nil {
+				t.Fatalf("failed to eval %s: %+v", tt.Target, err)
+			}
+			if len(g) != len(tt.Want) {
+				t.Fatalf("%s returned a different number of metrics, actual %v, Want %v", tt.Target, len(g), len(tt.Want))
+			}
+
+			for i, want := range tt.Want {
+				actual := g[i]
+				if actual.Name != want.Name {
+					t.Errorf("bad Name for %s metric %d: got %s, Want %s", tt.Target, i, actual.Name, want.Name)
+				}
+				if actual.StartTime != want.StartTime || actual.StepTime != want.StepTime {
+					t.Errorf("bad time range for %s metric %d: got %d/%d, Want %d/%d", tt.Target, i,
+						actual.StartTime, actual.StepTime, want.StartTime, want.StepTime)
+				}
+				if !th.NearlyEqualMetrics(actual, want) {
+					t.Errorf("different values for %s metric %s: got %v, Want %v", tt.Target, actual.Name, actual.Values, want.Values)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package aggregateWithWildcards
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type aggregateWithWildcards struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &aggregateWithWildcards{}
+	for _, n := range []string{"aggregateWithWildcards"} {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// aggregateWithWildcards(seriesList, func, *positions)
+func (f *aggregateWithWildcards) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := e.GetStringArg(1)
+	if err != nil {
+		return nil, err
+	}
+	callback = strings.TrimSuffix(callback, "Series")
+	aggFunc, ok := types.GetAggregateFunction(callback)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported aggregation function %s", parser.ErrInvalidArgumentValue, callback)
+	}
+
+	var fields []int
+	if len(e.Args()) > 2 {
+		fields, err = e.GetIntArgs(2)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nodeList := []string{}
+	groups := make(map[string][]*types.MetricData)
+
+	for _, a := range args {
+		metric := helper.ExtractMetric(a.Name)
+		nodes := strings.Split(metric, ".")
+		var s []string
+		for i, n := range nodes {
+			if !helper.Contains(fields, i) {
+				s = append(s, n)
+			}
+		}
+
+		node := strings.Join(s, ".")
+
+		if len(groups[node]) == 0 {
+			nodeList = append(nodeList, node)
+		}
+
+		groups[node] = append(groups[node], a)
+	}
+
+	var results []*types.MetricData
+	for _, node := range nodeList {
+		r, err := helper.AggregateSeries(node, groups[node], false, false, 0, helper.SeriesAggregation(aggFunc, len(groups[node])))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *aggregateWithWildcards) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"aggregateWithWildcards": {
+			Description: "Call aggregator after inserting wildcards at the given position(s).\n\nExample:\n\n.. code-block:: none\n\n  &target=aggregateWithWildcards(host.cpu-[0-7}.cpu-{user,system}.value, \"sum\", 1)\n\nThis would be the equivalent of\n\n.. code-block:: none\n\n  &target=sumSeries(host.cpu-[0-7}.cpu-user.value)&target=sumSeries(host.cpu-[0-7}.cpu-system.value)\n  # or\n  &target=aggregate(host.cpu-[0-7}.cpu-user.value,\"sum\")&target=aggregate(host.cpu-[0-7}.cpu-system.value,\"sum\")\n\nThis function can be used with all aggregation functions supported by\n:py:func:`aggregate <aggregate>`: ``average``, ``median``, ``sum``, ``min``, ``max``, ``diff``,\n``stddev``, ``range`` & ``multiply``.\n\nThis complements :py:func:`groupByNodes <groupByNodes>` which takes a list of nodes that must match in each group.",
+			Function:    "aggregateWithWildcards(seriesList, func, *positions)",
+			Group:       "Combine",
+			Module:      "graphite.render.functions",
+			Name:        "aggregateWithWildcards",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "func",
+					Required: true,
+					Options:  types.AggregateFunctionNames(),
+					Type:     types.AggFunc,
+				},
+				{
+					Multiple: true,
+					Name:     "positions",
+					Type:     types.Node,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package aggregateWithWildcards
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+// This return is multireturn
+func TestAggregateWithWildcards(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	metrics := func() map[parser.MetricRequest][]*types.MetricData {
+		return map[parser.MetricRequest][]*types.MetricData{
+			{"metric1.foo.*.*", 0, 1, 0}: {
+				types.MakeMetricData("metric1.foo.bar1.baz", []float64{1, 2, 3, 4, 5}, 1, now32),
+				types.MakeMetricData("metric1.foo.bar1.qux", []float64{6, 7, 8, 9, 10}, 1, now32),
+				types.MakeMetricData("metric1.foo.bar2.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
+				types.MakeMetricData("metric1.foo.bar2.qux", []float64{7, 8, 9, 10, 11}, 1, now32),
+			},
+		}
+	}
+
+	tests := []th.MultiReturnEvalTestItem{
+		{
+			"aggregateWithWildcards(metric1.foo.*.*,'avg',1,2)",
+			metrics(),
+			"aggregateWithWildcards",
+			map[string][]*types.MetricData{
+				"metric1.baz": {types.MakeMetricData("metric1.baz", []float64{6, 7, 8, 9, 10}, 1, now32)},
+				"metric1.qux": {types.MakeMetricData("metric1.qux", []float64{6.5, 7.5, 8.5, 9.5, 10.5}, 1, now32)},
+			},
+		},
+		{
+			"aggregateWithWildcards(metric1.foo.*.*,'max',3)",
+			metrics(),
+			"aggregateWithWildcards",
+			map[string][]*types.MetricData{
+				"metric1.foo.bar1": {types.MakeMetricData("metric1.foo.bar1", []float64{6, 7, 8, 9, 10}, 1, now32)},
+				"metric1.foo.bar2": {types.MakeMetricData("metric1.foo.bar2", []float64{11, 12, 13, 14, 15}, 1, now32)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		testName := tt.Target
+		t.Run(testName, func(t *testing.T) {
+			th.TestMultiReturnEvalExpr(t, &tt)
+		})
+	}
+}
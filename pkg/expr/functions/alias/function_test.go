@@ -30,7 +30,7 @@ func TestAlias(t *testing.T) {
 		{
 			"alias(metric1,\"renamed\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 5}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 5}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("renamed",
 				[]float64{1, 2, 3, 4, 5}, 1, now32)},
@@ -0,0 +1,101 @@
+package aliasByTags
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type aliasByTags struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &aliasByTags{}
+	for _, n := range []string{"aliasByTags"} {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// aliasByTags(seriesList, *tags)
+func (f *aliasByTags) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(e.Args()) < 2 {
+		return nil, parser.ErrMissingArgument
+	}
+	nodesOrTags := e.Args()[1:]
+	for _, nt := range nodesOrTags {
+		if !nt.IsConst() && !nt.IsString() {
+			return nil, parser.ErrBadType
+		}
+	}
+
+	var results []*types.MetricData
+	for _, a := range args {
+		tags := helper.ExtractTags(a.Name)
+		nodes := strings.Split(tags["name"], ".")
+
+		var name []string
+		for _, nt := range nodesOrTags {
+			if nt.IsString() {
+				name = append(name, tags[nt.StringValue()])
+				continue
+			}
+
+			f := int(nt.FloatValue())
+			if f < 0 {
+				f += len(nodes)
+			}
+			if f >= len(nodes) || f < 0 {
+				continue
+			}
+			name = append(name, nodes[f])
+		}
+
+		r := *a
+		r.Name = strings.Join(name, ".")
+		results = append(results, &r)
+	}
+
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *aliasByTags) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"aliasByTags": {
+			Description: "Takes a seriesList and applies an alias derived from one or more tags and/or nodes\n\n.. code-block:: none\n\n  &target=seriesByTag(\"name=cpu\")|aliasByTags(\"server\",\"name\")\n\nThis is an alias for :py:func:`aliasByNode <aliasByNode>`.",
+			Function:    "aliasByTags(seriesList, *tags)",
+			Group:       "Alias",
+			Module:      "graphite.render.functions",
+			Name:        "aliasByTags",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Multiple: true,
+					Name:     "tags",
+					Required: true,
+					Type:     types.NodeOrTag,
+				},
+			},
+		},
+	}
+}
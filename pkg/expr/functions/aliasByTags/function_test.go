@@ -0,0 +1,77 @@
+package aliasByTags
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/seriesByTag"
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+	for _, m := range seriesByTag.New("") {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+	evaluator := th.EvaluatorFromFuncWithMetadata(metadata.FunctionMD.Functions)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+}
+
+func TestAliasByTags(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	tests := []th.EvalTestItem{
+		{
+			"aliasByTags(seriesByTag('name=cpu.load'),'dc','host')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"seriesByTag('name=cpu.load')", 0, 1, 0}: {
+					types.MakeMetricData("cpu.load;dc=ams;host=a", []float64{1, 2, 3}, 1, now32),
+					types.MakeMetricData("cpu.load;dc=lhr", []float64{4, 5, 6}, 1, now32),
+				},
+			},
+			[]*types.MetricData{
+				types.MakeMetricData("ams.a", []float64{1, 2, 3}, 1, now32),
+				types.MakeMetricData("lhr.", []float64{4, 5, 6}, 1, now32),
+			},
+		},
+		{
+			"aliasByTags(seriesByTag('name=cpu.load'),'dc',-1,'name')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"seriesByTag('name=cpu.load')", 0, 1, 0}: {
+					types.MakeMetricData("cpu.load;dc=ams", []float64{1, 2, 3}, 1, now32),
+				},
+			},
+			[]*types.MetricData{
+				types.MakeMetricData("ams.load.cpu.load", []float64{1, 2, 3}, 1, now32),
+			},
+		},
+		{
+			"aliasByTags(metric.foo.bar,1)",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric.foo.bar", 0, 1, 0}: {
+					types.MakeMetricData("metric.foo.bar", []float64{1, 2, 3}, 1, now32),
+				},
+			},
+			[]*types.MetricData{
+				types.MakeMetricData("foo", []float64{1, 2, 3}, 1, now32),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		testName := tt.Target
+		t.Run(testName, func(t *testing.T) {
+			th.TestEvalExpr(t, &tt)
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package aliasQuery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+// config is read from the file given in the functionsConfig section of the carbonapi config.
+type config struct {
+	// MaxQueries bounds the distinct sub-queries a single aliasQuery call evaluates.
+	MaxQueries int `yaml:"maxQueries"`
+	// Concurrency bounds the sub-queries fetched at the same time.
+	Concurrency int `yaml:"concurrency"`
+}
+
+func defaultConfig() config {
+	return config{
+		MaxQueries:  100,
+		Concurrency: 10,
+	}
+}
+
+type aliasQuery struct {
+	interfaces.FunctionBase
+
+	config config
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+// New returns the aliasQuery function, configured by the file when there is one.
+func New(configFile string) ([]interfaces.FunctionMetadata, error) {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &aliasQuery{config: defaultConfig()}
+	if configFile != "" {
+		b, err := os.ReadFile(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the aliasQuery config file: %w", err)
+		}
+		if err := yaml.Unmarshal(b, &f.config); err != nil {
+			return nil, fmt.Errorf("failed to parse the aliasQuery config at %s: %w", configFile, err)
+		}
+		if f.config.MaxQueries < 1 {
+			return nil, fmt.Errorf("the aliasQuery maxQueries at %s must be positive, got %d", configFile, f.config.MaxQueries)
+		}
+		if f.config.Concurrency < 1 {
+			f.config.Concurrency = 1
+		}
+	}
+	functions := []string{"aliasQuery"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res, nil
+}
+
+// aliasQuery(seriesList, search, replace, newName)
+func (f *aliasQuery) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	search, err := e.GetStringArg(1)
+	if err != nil {
+		return nil, err
+	}
+
+	replace, err := e.GetStringArg(2)
+	if err != nil {
+		return nil, err
+	}
+
+	newName, err := e.GetStringArg(3)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(search)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %v", parser.ErrInvalidArgumentValue, search, err)
+	}
+
+	replace = helper.Backref.ReplaceAllString(replace, "$${$1}")
+
+	// the series often share their sub-query, which is only evaluated once
+	queries := make([]string, len(args))
+	var distinct []string
+	current := make(map[string]float64)
+	for i, a := range args {
+		queries[i] = re.ReplaceAllString(a.Name, replace)
+		if _, ok := current[queries[i]]; !ok {
+			current[queries[i]] = 0
+			distinct = append(distinct, queries[i])
+		}
+	}
+	if len(distinct) > f.config.MaxQueries {
+		return nil, fmt.Errorf("%w: %d queries to alias by, more than %d", parser.ErrInvalidArgumentValue, len(distinct), f.config.MaxQueries)
+	}
+
+	// the sub-queries of a chunk are fetched concurrently by a single call
+	for start := 0; start < len(distinct); start += f.config.Concurrency {
+		end := start + f.config.Concurrency
+		if end > len(distinct) {
+			end = len(distinct)
+		}
+
+		exp, _, err := parser.ParseExpr("group(" + strings.Join(distinct[start:end], ",") + ")")
+		if err != nil {
+			return nil, err
+		}
+		if err, _ := getTargetData(ctx, exp, from, until, values); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, query := range distinct {
+		current[query], err = lastValue(ctx, query, from, until, values, getTargetData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]*types.MetricData, 0, len(args))
+	for i, a := range args {
+		r := *a
+		r.Name = formatName(newName, current[queries[i]])
+		results = append(results, &r)
+	}
+
+	return results, nil
+}
+
+// lastValue evaluates the fetched query and returns the last present value of its first series.
+func lastValue(ctx context.Context, query string, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) (float64, error) {
+	exp, _, err := parser.ParseExpr(query)
+	if err != nil {
+		return 0, err
+	}
+
+	series, err := helper.GetSeriesArg(ctx, exp, from, until, values, getTargetData)
+	if err != nil {
+		return 0, err
+	}
+	if len(series) == 0 {
+		return 0, fmt.Errorf("%w: no series found with query %s", parser.ErrSeriesDoesNotExist, query)
+	}
+
+	s := series[0]
+	for i := len(s.Values) - 1; i >= 0; i-- {
+		if !s.IsAbsent[i] {
+			return s.Values[i], nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: cannot get last value of series %s", parser.ErrSeriesDoesNotExist, s.Name)
+}
+
+var formatVerb = regexp.MustCompile(`%[-+ #0]*[0-9]*(?:\.[0-9]+)?[diouxXeEfFgGrs%]`)
+
+// formatName formats the value into the name the way python's % operator does,
+// as graphite-web's newName is a python format string.
+func formatName(name string, v float64) string {
+	return formatVerb.ReplaceAllStringFunc(name, func(verb string) string {
+		spec, conv := verb[:len(verb)-1], verb[len(verb)-1]
+
+		switch conv {
+		case '%':
+			return "%"
+		case 'd', 'i', 'u':
+			return fmt.Sprintf(spec+"d", int64(v))
+		case 'o', 'x', 'X':
+			return fmt.Sprintf(spec+string(conv), int64(v))
+		case 'e', 'E', 'f', 'F', 'g', 'G':
+			return fmt.Sprintf(verb, v)
+		}
+
+		// python's str() of a float always has a fractional part
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.ContainsAny(s, ".NI") {
+			s += ".0"
+		}
+		return fmt.Sprintf(spec+"s", s)
+	})
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *aliasQuery) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"aliasQuery": {
+			Description: "Performs a query to alias the metrics in seriesList.\n\n.. code-block:: none\n\n  &target=aliasQuery(channel.power.*,\"channel\\.power\\.([0-9]+)\",\"channel.frequency.\\1\", \"Channel %d MHz\")\n\nThe series in seriesList will be aliased by first translating the series names using\nthe search & replace parameters, then using the last value of the resulting series\nto construct the alias using sprintf-style syntax.",
+			Function:    "aliasQuery(seriesList, search, replace, newName)",
+			Group:       "Alias",
+			Module:      "graphite.render.functions",
+			Name:        "aliasQuery",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "search",
+					Required: true,
+					Type:     types.String,
+				},
+				{
+					Name:     "replace",
+					Required: true,
+					Type:     types.String,
+				},
+				{
+					Name:     "newName",
+					Required: true,
+					Type:     types.String,
+				},
+			},
+		},
+	}
+}
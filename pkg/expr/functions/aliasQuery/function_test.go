@@ -0,0 +1,223 @@
+package aliasQuery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md, err := New("")
+	if err != nil {
+		panic(err)
+	}
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestAliasQuery(t *testing.T) {
+	values := map[parser.MetricRequest][]*types.MetricData{
+		{Metric: "channel.power.*", From: 0, Until: 1}: {
+			types.MakeMetricData("channel.power.1", []float64{1, 2, 3}, 1, 0),
+			types.MakeMetricData("channel.power.2", []float64{4, 5, 6}, 1, 0),
+			types.MakeMetricData("channel.power.3", []float64{7, 8, 9}, 1, 0),
+			types.MakeMetricData("channel.power.2", []float64{4, 5, 6}, 1, 0),
+		},
+		{Metric: "channel.frequency.1", From: 0, Until: 1}: {types.MakeMetricData("channel.frequency.1", []float64{90, 100, math.NaN()}, 1, 0)},
+		{Metric: "channel.frequency.2", From: 0, Until: 1}: {types.MakeMetricData("channel.frequency.2", []float64{2400.5, 2400.5, 2400.5}, 1, 0)},
+		{Metric: "channel.frequency.3", From: 0, Until: 1}: {types.MakeMetricData("channel.frequency.3", []float64{100, 100, 100}, 1, 0)},
+	}
+
+	// the distinct sub-queries are fetched once each, together
+	var fetched [][]string
+	getTargetData := func(ctx context.Context, exp parser.Expr, from, until int32, metricMap map[parser.MetricRequest][]*types.MetricData) (error, int) {
+		fetched = append(fetched, metricNames(exp))
+		return nil, 0
+	}
+
+	exp, _, err := parser.ParseExpr(`aliasQuery(channel.power.*,"channel\.power\.([0-9]+)","channel.frequency.\1","Channel %d MHz")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1, values, getTargetData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Channel 100 MHz", "Channel 2400 MHz", "Channel 100 MHz", "Channel 2400 MHz"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d series, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i] {
+			t.Errorf("got the name %s, want %s", got[i].Name, want[i])
+		}
+	}
+
+	wantFetched := [][]string{{"channel.frequency.1", "channel.frequency.2", "channel.frequency.3"}}
+	if fmt.Sprint(fetched) != fmt.Sprint(wantFetched) {
+		t.Errorf("got the sub-queries %v, want %v", fetched, wantFetched)
+	}
+}
+
+func TestAliasQueryErrors(t *testing.T) {
+	many := make([]*types.MetricData, defaultConfig().MaxQueries+1)
+	for i := range many {
+		many[i] = types.MakeMetricData(fmt.Sprintf("host.%d", i), []float64{1}, 1, 0)
+	}
+
+	tests := []struct {
+		target string
+		values map[parser.MetricRequest][]*types.MetricData
+		want   error
+	}{
+		{
+			`aliasQuery(host.1,"host","inventory","%d")`,
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "host.1", From: 0, Until: 1}: {types.MakeMetricData("host.1", []float64{1}, 1, 0)},
+			},
+			parser.ErrSeriesDoesNotExist,
+		},
+		{
+			`aliasQuery(host.1,"host","inventory","%d")`,
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "host.1", From: 0, Until: 1}:      {types.MakeMetricData("host.1", []float64{1}, 1, 0)},
+				{Metric: "inventory.1", From: 0, Until: 1}: {types.MakeMetricData("inventory.1", []float64{math.NaN()}, 1, 0)},
+			},
+			parser.ErrSeriesDoesNotExist,
+		},
+		{
+			`aliasQuery(host.*,"host","inventory","%d")`,
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "host.*", From: 0, Until: 1}: many,
+			},
+			parser.ErrInvalidArgumentValue,
+		},
+	}
+
+	for _, tt := range tests {
+		exp, _, err := parser.ParseExpr(tt.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1, tt.values, th.NoopGetTargetData)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got the error %v, want %v", tt.target, err, tt.want)
+		}
+	}
+}
+
+func TestAliasQueryConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "aliasQuery.yaml")
+	if err := os.WriteFile(configFile, []byte("maxQueries: 3\nconcurrency: 2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	md, err := New(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := md[0].F
+
+	series := func(n int) map[parser.MetricRequest][]*types.MetricData {
+		hosts := parser.MetricRequest{Metric: "host.*", From: 0, Until: 1}
+		values := map[parser.MetricRequest][]*types.MetricData{}
+		for i := 0; i < n; i++ {
+			inventory := fmt.Sprintf("inventory.%d", i)
+			values[hosts] = append(values[hosts], types.MakeMetricData(fmt.Sprintf("host.%d", i), []float64{1}, 1, 0))
+			values[parser.MetricRequest{Metric: inventory, From: 0, Until: 1}] = []*types.MetricData{types.MakeMetricData(inventory, []float64{float64(i)}, 1, 0)}
+		}
+		return values
+	}
+
+	exp, _, err := parser.ParseExpr(`aliasQuery(host.*,"host","inventory","%d")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fetched [][]string
+	getTargetData := func(ctx context.Context, exp parser.Expr, from, until int32, metricMap map[parser.MetricRequest][]*types.MetricData) (error, int) {
+		fetched = append(fetched, metricNames(exp))
+		return nil, 0
+	}
+
+	// the sub-queries are fetched in chunks of the configured concurrency
+	if _, err := f.Do(context.Background(), exp, 0, 1, series(3), getTargetData); err != nil {
+		t.Fatal(err)
+	}
+	wantFetched := [][]string{{"inventory.0", "inventory.1"}, {"inventory.2"}}
+	if fmt.Sprint(fetched) != fmt.Sprint(wantFetched) {
+		t.Errorf("got the sub-queries %v, want %v", fetched, wantFetched)
+	}
+
+	if _, err := f.Do(context.Background(), exp, 0, 1, series(4), th.NoopGetTargetData); !errors.Is(err, parser.ErrInvalidArgumentValue) {
+		t.Errorf("got the error %v over the configured limit, want %v", err, parser.ErrInvalidArgumentValue)
+	}
+}
+
+func TestAliasQueryInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"zero maxQueries": "maxQueries: 0\n",
+		"not yaml":        "maxQueries: [\n",
+	}
+
+	for name, config := range tests {
+		configFile := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(configFile, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New(configFile); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := New(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
+
+func metricNames(exp parser.Expr) []string {
+	var names []string
+	for _, m := range exp.Metrics() {
+		names = append(names, m.Metric)
+	}
+	return names
+}
+
+func TestFormatName(t *testing.T) {
+	tests := []struct {
+		name string
+		v    float64
+		want string
+	}{
+		{"Channel %d MHz", 2400.5, "Channel 2400 MHz"},
+		{"%s", 5, "5.0"},
+		{"%s", 2.25, "2.25"},
+		{"%.1f%%", 99.04, "99.0%"},
+		{"%5.2f", 3.14159, " 3.14"},
+		{"%x", 255, "ff"},
+		{"no verbs", 1, "no verbs"},
+	}
+
+	for _, tt := range tests {
+		if got := formatName(tt.name, tt.v); got != tt.want {
+			t.Errorf("formatName(%q, %v) = %q, want %q", tt.name, tt.v, got, tt.want)
+		}
+	}
+}
@@ -30,7 +30,7 @@ func TestAliasByNode(t *testing.T) {
 		{
 			"aliasSub(metric1.foo.bar.baz, \"foo\", \"replaced\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.bar.baz", 0, 1}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
+				{"metric1.foo.bar.baz", 0, 1, 0}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("metric1.replaced.bar.baz",
 				[]float64{1, 2, 3, 4, 5}, 1, now32)},
@@ -38,7 +38,7 @@ func TestAliasByNode(t *testing.T) {
 		{
 			"aliasSub(metric1.TCP100,\"^.*TCP(\\d+)\",\"$1\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.TCP100", 0, 1}: {types.MakeMetricData("metric1.TCP100", []float64{1, 2, 3, 4, 5}, 1, now32)},
+				{"metric1.TCP100", 0, 1, 0}: {types.MakeMetricData("metric1.TCP100", []float64{1, 2, 3, 4, 5}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("100",
 				[]float64{1, 2, 3, 4, 5}, 1, now32)},
@@ -46,7 +46,7 @@ func TestAliasByNode(t *testing.T) {
 		{
 			"aliasSub(metric1.TCP100,\"^.*TCP(\\d+)\", \"\\1\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.TCP100", 0, 1}: {types.MakeMetricData("metric1.TCP100", []float64{1, 2, 3, 4, 5}, 1, now32)},
+				{"metric1.TCP100", 0, 1, 0}: {types.MakeMetricData("metric1.TCP100", []float64{1, 2, 3, 4, 5}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("100",
 				[]float64{1, 2, 3, 4, 5}, 1, now32)},
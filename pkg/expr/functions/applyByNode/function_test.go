@@ -41,22 +41,22 @@ func TestApplyByNode(t *testing.T) {
 		{
 			"applyByNode(servers.s*.disk.bytes_free, 1, 'divideSeries(%.disk.bytes_used,sumSeries(%.disk.bytes_*)))', '%.disk.pct_used')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"servers.s*.disk.bytes_free", 0, 1}: {
+				{"servers.s*.disk.bytes_free", 0, 1, 0}: {
 					types.MakeMetricData("servers.s1.disk.bytes_free", []float64{90, 80, 70}, 1, now32),
 					types.MakeMetricData("servers.s2.disk.bytes_free", []float64{99, 97, 98}, 1, now32),
 				},
-				{"servers.s1.disk.bytes_*", 0, 1}: {
+				{"servers.s1.disk.bytes_*", 0, 1, 0}: {
 					types.MakeMetricData("servers.s1.disk.bytes_free", []float64{90, 80, 70}, 1, now32),
 					types.MakeMetricData("servers.s1.disk.bytes_used", []float64{10, 20, 30}, 1, now32),
 				},
-				{"servers.s2.disk.bytes_*", 0, 1}: {
+				{"servers.s2.disk.bytes_*", 0, 1, 0}: {
 					types.MakeMetricData("servers.s2.disk.bytes_free", []float64{99, 98, 97}, 1, now32),
 					types.MakeMetricData("servers.s2.disk.bytes_used", []float64{1, 2, 3}, 1, now32),
 				},
-				{"servers.s1.disk.bytes_used", 0, 1}: {
+				{"servers.s1.disk.bytes_used", 0, 1, 0}: {
 					types.MakeMetricData("servers.s1.disk.bytes_used", []float64{10, 20, 30}, 1, now32),
 				},
-				{"servers.s2.disk.bytes_used", 0, 1}: {
+				{"servers.s2.disk.bytes_used", 0, 1, 0}: {
 					types.MakeMetricData("servers.s2.disk.bytes_used", []float64{1, 2, 3}, 1, now32),
 				},
 			},
@@ -32,20 +32,29 @@ func TestAsPercent(t *testing.T) {
 		{
 			"asPercent(metric1,metric2)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, now32)},
-				{"metric2", 0, 1}: {types.MakeMetricData("metric2", []float64{2, math.NaN(), 3, math.NaN(), 0, 6}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, now32)},
+				{"metric2", 0, 1, 0}: {types.MakeMetricData("metric2", []float64{2, math.NaN(), 3, math.NaN(), 0, 6}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("asPercent(metric1,metric2)",
 				[]float64{50, math.NaN(), math.NaN(), math.NaN(), math.NaN(), 200}, 1, now32)},
 		},
+		{
+			"pct(metric1,metric2)",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 3, 12}, 1, now32)},
+				{"metric2", 0, 1, 0}: {types.MakeMetricData("metric2", []float64{2, math.NaN(), 6}, 1, now32)},
+			},
+			[]*types.MetricData{types.MakeMetricData("asPercent(metric1,metric2)",
+				[]float64{50, math.NaN(), 200}, 1, now32)},
+		},
 		{
 			"asPercent(metricA*,metricB*)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metricA*", 0, 1}: {
+				{"metricA*", 0, 1, 0}: {
 					types.MakeMetricData("metricA1", []float64{1, 20, 10}, 1, now32),
 					types.MakeMetricData("metricA2", []float64{1, 10, 20}, 1, now32),
 				},
-				{"metricB*", 0, 1}: {
+				{"metricB*", 0, 1, 0}: {
 					types.MakeMetricData("metricB1", []float64{4, 4, 8}, 1, now32),
 					types.MakeMetricData("metricB2", []float64{4, 16, 2}, 1, now32),
 				},
@@ -58,11 +67,11 @@ func TestAsPercent(t *testing.T) {
 		{
 			"asPercent(Server{1,2}.memory.used,Server{1,3}.memory.total)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"Server{1,2}.memory.used", 0, 1}: {
+				{"Server{1,2}.memory.used", 0, 1, 0}: {
 					types.MakeMetricData("Server1.memory.used", []float64{1, 20, 10}, 1, now32),
 					types.MakeMetricData("Server2.memory.used", []float64{1, 10, 20}, 1, now32),
 				},
-				{"Server{1,3}.memory.total", 0, 1}: {
+				{"Server{1,3}.memory.total", 0, 1, 0}: {
 					types.MakeMetricData("Server1.memory.total", []float64{4, 4, 8}, 1, now32),
 					types.MakeMetricData("Server3.memory.total", []float64{4, 16, 2}, 1, now32),
 				},
@@ -75,11 +84,11 @@ func TestAsPercent(t *testing.T) {
 		{
 			"asPercent(Server{1,2}.memory.used,Server{1,3}.memory.total,0)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"Server{1,2}.memory.used", 0, 1}: {
+				{"Server{1,2}.memory.used", 0, 1, 0}: {
 					types.MakeMetricData("Server1.memory.used", []float64{1, 20, 10}, 1, now32),
 					types.MakeMetricData("Server2.memory.used", []float64{1, 10, 20}, 1, now32),
 				},
-				{"Server{1,3}.memory.total", 0, 1}: {
+				{"Server{1,3}.memory.total", 0, 1, 0}: {
 					types.MakeMetricData("Server1.memory.total", []float64{4, 4, 8}, 1, now32),
 					types.MakeMetricData("Server3.memory.total", []float64{4, 16, 2}, 1, now32),
 				},
@@ -93,8 +102,8 @@ func TestAsPercent(t *testing.T) {
 		{
 			"asPercent(not_found,Server{1,3}.memory.total)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"not_found", 0, 1}: {},
-				{"Server{1,3}.memory.total", 0, 1}: {
+				{"not_found", 0, 1, 0}: {},
+				{"Server{1,3}.memory.total", 0, 1, 0}: {
 					types.MakeMetricData("Server1.memory.total", []float64{4, 4, 8}, 1, now32),
 					types.MakeMetricData("Server3.memory.total", []float64{4, 16, 2}, 1, now32),
 				},
@@ -104,7 +113,7 @@ func TestAsPercent(t *testing.T) {
 		{
 			"asPercent(test-db*)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"test-db*", 0, 1}: {
+				{"test-db*", 0, 1, 0}: {
 					types.MakeMetricData("test-db1", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, 1, now32),
 					types.MakeMetricData("test-db2", []float64{math.NaN(), 2, math.NaN(), 4, math.NaN(), 6, math.NaN(), 8, math.NaN(), 10, math.NaN(), 12, math.NaN(), 14, math.NaN(), 16, math.NaN(), 18, math.NaN(), 20}, 1, now32),
 					types.MakeMetricData("test-db3", []float64{1, 2, math.NaN(), math.NaN(), math.NaN(), 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, math.NaN(), math.NaN(), math.NaN()}, 1, now32),
@@ -122,7 +131,7 @@ func TestAsPercent(t *testing.T) {
 		{
 			"asPercent(test-db*, 10)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"test-db*", 0, 1}: {
+				{"test-db*", 0, 1, 0}: {
 					types.MakeMetricData("test-db1", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, 1, now32),
 					types.MakeMetricData("test-db2", []float64{math.NaN(), 2, math.NaN(), 4, math.NaN(), 6, math.NaN(), 8, math.NaN(), 10, math.NaN(), 12, math.NaN(), 14, math.NaN(), 16, math.NaN(), 18, math.NaN(), 20}, 1, now32),
 					types.MakeMetricData("test-db3", []float64{1, 2, math.NaN(), math.NaN(), math.NaN(), 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, math.NaN(), math.NaN(), math.NaN()}, 1, now32),
@@ -140,14 +149,14 @@ func TestAsPercent(t *testing.T) {
 		{
 			"asPercent(test-db*, single)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"test-db*", 0, 1}: {
+				{"test-db*", 0, 1, 0}: {
 					types.MakeMetricData("test-db1", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, 1, now32),
 					types.MakeMetricData("test-db2", []float64{math.NaN(), 2, math.NaN(), 4, math.NaN(), 6, math.NaN(), 8, math.NaN(), 10, math.NaN(), 12, math.NaN(), 14, math.NaN(), 16, math.NaN(), 18, math.NaN(), 20}, 1, now32),
 					types.MakeMetricData("test-db3", []float64{1, 2, math.NaN(), math.NaN(), math.NaN(), 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, math.NaN(), math.NaN(), math.NaN()}, 1, now32),
 					types.MakeMetricData("test-db4", []float64{1, 2, 3, 4, math.NaN(), 6, math.NaN(), math.NaN(), 9, 10, 11, math.NaN(), 13, math.NaN(), math.NaN(), math.NaN(), math.NaN(), 18, 19, 20}, 1, now32),
 					types.MakeMetricData("test-db5", []float64{1, 2, math.NaN(), math.NaN(), math.NaN(), 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, math.NaN(), math.NaN()}, 1, now32),
 				},
-				{"single", 0, 1}: {
+				{"single", 0, 1, 0}: {
 					types.MakeMetricData("test-db1", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, 1, now32),
 				},
 			},
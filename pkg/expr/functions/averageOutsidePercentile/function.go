@@ -0,0 +1,98 @@
+package averageOutsidePercentile
+
+import (
+	"context"
+	"math"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type averageOutsidePercentile struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &averageOutsidePercentile{}
+	functions := []string{"averageOutsidePercentile"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// averageOutsidePercentile(seriesList, n)
+func (f *averageOutsidePercentile) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	percent, err := e.GetFloatArg(1)
+	if err != nil {
+		return nil, err
+	}
+	if percent < 50 {
+		percent = 100 - percent
+	}
+
+	averages := make([]float64, 0, len(args))
+	for _, a := range args {
+		averages = append(averages, helper.AvgValue(a.Values, a.IsAbsent))
+	}
+
+	var present []float64
+	for _, avg := range averages {
+		if !math.IsNaN(avg) {
+			present = append(present, avg)
+		}
+	}
+	low, absent := helper.Percentile(append([]float64(nil), present...), 100-percent, false)
+	if absent {
+		// there is no band without any values
+		return args, nil
+	}
+	high, _ := helper.Percentile(present, percent, false)
+
+	var results []*types.MetricData
+	for i, a := range args {
+		// the series without values have NaN average, which is never in the band
+		if !(low < averages[i] && averages[i] < high) {
+			results = append(results, a)
+		}
+	}
+
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *averageOutsidePercentile) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"averageOutsidePercentile": {
+			Description: "Removes series lying inside an average percentile interval",
+			Function:    "averageOutsidePercentile(seriesList, n)",
+			Group:       "Filter Series",
+			Module:      "graphite.render.functions",
+			Name:        "averageOutsidePercentile",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "n",
+					Required: true,
+					Type:     types.Integer,
+				},
+			},
+		},
+	}
+}
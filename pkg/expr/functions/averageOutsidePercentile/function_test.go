@@ -0,0 +1,60 @@
+package averageOutsidePercentile
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestAverageOutsidePercentile(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	tests := []th.EvalTestItem{
+		{
+			"averageOutsidePercentile(metric*,25)",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric*", 0, 1, 0}: {
+					types.MakeMetricData("metric1", []float64{1, 1, 1}, 1, now32),
+					types.MakeMetricData("metric2", []float64{2, math.NaN(), 2}, 1, now32),
+					types.MakeMetricData("metric3", []float64{2, 3, 4}, 1, now32),
+					types.MakeMetricData("metric4", []float64{4, 4, 4}, 1, now32),
+					types.MakeMetricData("metric5", []float64{5, 5, 5}, 1, now32),
+					types.MakeMetricData("metric6", []float64{math.NaN(), math.NaN(), math.NaN()}, 1, now32),
+				},
+			},
+			[]*types.MetricData{
+				types.MakeMetricData("metric1", []float64{1, 1, 1}, 1, now32),
+				types.MakeMetricData("metric2", []float64{2, math.NaN(), 2}, 1, now32),
+				types.MakeMetricData("metric4", []float64{4, 4, 4}, 1, now32),
+				types.MakeMetricData("metric5", []float64{5, 5, 5}, 1, now32),
+				types.MakeMetricData("metric6", []float64{math.NaN(), math.NaN(), math.NaN()}, 1, now32),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		testName := tt.Target
+		t.Run(testName, func(t *testing.T) {
+			th.TestEvalExpr(t, &tt)
+		})
+	}
+}
@@ -36,7 +36,7 @@ func (f *averageSeries) Do(ctx context.Context, e parser.Expr, from, until int32
 
 	e.SetTarget("averageSeries")
 	name := fmt.Sprintf("%s(%s)", e.Target(), e.RawArgs())
-	return helper.AggregateSeries(name, args, false, false, func(values []float64) (float64, bool) {
+	return helper.AggregateSeries(name, args, false, false, 0, func(values []float64) (float64, bool) {
 		sum := 0.0
 		for _, value := range values {
 			sum += value
@@ -32,7 +32,7 @@ func TestBelow(t *testing.T) {
 		{
 			"currentAbove(metric1,7)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metricA", []float64{0, 0, 0, 0, 0, 0}, 1, now32),
 					types.MakeMetricData("metricB", []float64{3, 4, 5, 6, 7, 8}, 1, now32),
 					types.MakeMetricData("metricC", []float64{4, 4, 5, 5, 6, 6}, 1, now32),
@@ -44,7 +44,7 @@ func TestBelow(t *testing.T) {
 		{
 			"currentBelow(metric1,0)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metricA", []float64{0, 0, 0, 0, 0, math.NaN()}, 1, now32),
 					types.MakeMetricData("metricB", []float64{3, 4, 5, 6, 7, 8}, 1, now32),
 					types.MakeMetricData("metricC", []float64{0, 4, 4, 5, 5, 6}, 1, now32),
@@ -56,7 +56,7 @@ func TestBelow(t *testing.T) {
 		{
 			"averageAbove(metric1,5)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metricA", []float64{0, 0, 0, 0, 0, 0}, 1, now32),
 					types.MakeMetricData("metricB", []float64{3, 4, 5, 6, 7, 8}, 1, now32),
 					types.MakeMetricData("metricC", []float64{4, 4, 5, 5, 6, 6}, 1, now32),
@@ -70,7 +70,7 @@ func TestBelow(t *testing.T) {
 		{
 			"averageBelow(metric1,0)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metricA", []float64{0, 0, 0, 0, 0, 0}, 1, now32),
 					types.MakeMetricData("metricB", []float64{3, 4, 5, 6, 7, 8}, 1, now32),
 					types.MakeMetricData("metricC", []float64{0, 4, 4, 5, 5, 6}, 1, now32),
@@ -82,7 +82,7 @@ func TestBelow(t *testing.T) {
 		{
 			"maximumAbove(metric1,6)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metricA", []float64{0, 0, 0, 0, 0, 0}, 1, now32),
 					types.MakeMetricData("metricB", []float64{3, 4, 5, 6, 7, 8}, 1, now32),
 					types.MakeMetricData("metricC", []float64{4, 4, 5, 5, 6, 6}, 1, now32),
@@ -94,7 +94,7 @@ func TestBelow(t *testing.T) {
 		{
 			"maximumBelow(metric1,5)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metricA", []float64{0, 0, 0, 0, 0, 0}, 1, now32),
 					types.MakeMetricData("metricB", []float64{3, 4, 5, 6, 7, 8}, 1, now32),
 					types.MakeMetricData("metricC", []float64{4, 4, 5, 5, 6, 6}, 1, now32),
@@ -106,7 +106,7 @@ func TestBelow(t *testing.T) {
 		{
 			"minimumAbove(metric1,1)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metricA", []float64{0, 0, 0, 0, 0, 0}, 1, now32),
 					types.MakeMetricData("metricB", []float64{1, 4, 5, 6, 7, 8}, 1, now32),
 					types.MakeMetricData("metricC", []float64{2, 4, 4, 5, 5, 6}, 1, now32),
@@ -118,7 +118,7 @@ func TestBelow(t *testing.T) {
 		{
 			"minimumBelow(metric1,-2)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metricA", []float64{0, 0, 0, 0, 0, 0}, 1, now32),
 					types.MakeMetricData("metricB", []float64{-1, 4, 5, 6, 7, 8}, 1, now32),
 					types.MakeMetricData("metricC", []float64{-2, 4, 4, 5, 5, 6}, 1, now32),
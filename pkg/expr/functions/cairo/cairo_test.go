@@ -1,13 +1,14 @@
-//go:build cairo
-// +build cairo
-
 package cairo
 
 import (
+	"context"
+	"errors"
 	"go.uber.org/zap"
 	"testing"
 	"time"
 
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/holtWintersConfidenceBands"
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
 	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
 	"github.com/bookingcom/carbonapi/pkg/expr/types"
 	"github.com/bookingcom/carbonapi/pkg/parser"
@@ -16,10 +17,13 @@ import (
 
 func init() {
 	md := New("")
-	metadata.SetEvaluator(th.EvaluatorFromFunc(md[0].F))
+	md = append(md, holtWintersConfidenceBands.New("")...)
 	for _, m := range md {
 		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
 	}
+	evaluator := th.EvaluatorFromFuncWithMetadata(metadata.FunctionMD.Functions)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
 }
 
 func TestEvalExpressionGraph(t *testing.T) {
@@ -81,3 +85,129 @@ func TestEvalExpressionGraph(t *testing.T) {
 		th.TestEvalExpr(t, &tt)
 	}
 }
+
+func TestVerticalLine(t *testing.T) {
+	var from, until int32 = 1500000000, 1500001000
+
+	tests := []struct {
+		target  string
+		want    *types.MetricData
+		color   string
+		wantErr bool
+	}{
+		{
+			target: "verticalLine(\"1500000500\")",
+			want:   types.MakeMetricData("1500000500", []float64{1, 1}, 1, 1500000500),
+		},
+		{
+			target: "verticalLine(\"1500000500\",\"deploy\",\"red\")",
+			want:   types.MakeMetricData("deploy", []float64{1, 1}, 1, 1500000500),
+			color:  "red",
+		},
+		{
+			target: "verticalLine(\"1500000500\",color=\"blue\",label=\"deploy\")",
+			want:   types.MakeMetricData("deploy", []float64{1, 1}, 1, 1500000500),
+			color:  "blue",
+		},
+		{
+			target:  "verticalLine(\"1499999000\")",
+			wantErr: true,
+		},
+		{
+			target:  "verticalLine(\"1500002000\")",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			exp, _, err := parser.ParseExpr(tt.target)
+			if err != nil {
+				t.Fatalf("failed to parse %s: %v", tt.target, err)
+			}
+
+			got, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, from, until, map[parser.MetricRequest][]*types.MetricData{}, th.NoopGetTargetData)
+			if tt.wantErr {
+				if !errors.Is(err, parser.ErrInvalidArgumentValue) {
+					t.Fatalf("expected ErrInvalidArgumentValue, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("expected 1 series, got %d", len(got))
+			}
+
+			g := got[0]
+			if g.Name != tt.want.Name || g.StartTime != tt.want.StartTime || g.StepTime != tt.want.StepTime {
+				t.Errorf("got %s start=%d step=%d, want %s start=%d step=%d", g.Name, g.StartTime, g.StepTime, tt.want.Name, tt.want.StartTime, tt.want.StepTime)
+			}
+			if !g.DrawAsInfinite {
+				t.Error("expected DrawAsInfinite to be set")
+			}
+			if g.Color != tt.color {
+				t.Errorf("got color %q, want %q", g.Color, tt.color)
+			}
+			if !th.NearlyEqualMetrics(g, tt.want) {
+				t.Errorf("got values %v, want %v", g.Values, tt.want.Values)
+			}
+		})
+	}
+}
+
+func TestHoltWintersConfidenceArea(t *testing.T) {
+	var from, until int32 = 7 * 86400, 7*86400 + 600
+
+	values := make([]float64, 7*86400/60+10)
+	for i := range values {
+		values[i] = float64(i % 10)
+	}
+	fetched := map[parser.MetricRequest][]*types.MetricData{
+		{Metric: "foo.bar", From: from - 7*86400, Until: until}: {types.MakeMetricData("foo.bar", values, 60, 0)},
+	}
+
+	eval := func(target string) []*types.MetricData {
+		exp, _, err := parser.ParseExpr(target)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", target, err)
+		}
+		res, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, from, until, fetched, th.NoopGetTargetData)
+		if err != nil {
+			t.Fatalf("failed to evaluate %s: %v", target, err)
+		}
+		return res
+	}
+
+	bands := eval("holtWintersConfidenceBands(foo.bar)")
+	got := eval("holtWintersConfidenceArea(foo.bar)")
+
+	if len(bands) != 2 || len(got) != 2 {
+		t.Fatalf("expected 2 series, got %d bands and %d areas", len(bands), len(got))
+	}
+
+	lower, upper := got[0], got[1]
+	for _, r := range got {
+		if r.Name != "holtWintersConfidenceArea(foo.bar)" {
+			t.Errorf("unexpected name %s", r.Name)
+		}
+		if !r.Stacked {
+			t.Errorf("expected %s to be stacked", r.Name)
+		}
+	}
+	if !lower.Invisible || upper.Invisible {
+		t.Errorf("expected only the lower band to be invisible")
+	}
+	if !th.NearlyEqual(lower.Values, lower.IsAbsent, bands[0].Values) {
+		t.Errorf("lower band: got %v, want %v", lower.Values, bands[0].Values)
+	}
+
+	want := make([]float64, len(bands[1].Values))
+	for i := range want {
+		want[i] = bands[1].Values[i] - bands[0].Values[i]
+	}
+	if !th.NearlyEqual(upper.Values, upper.IsAbsent, want) {
+		t.Errorf("upper band: got %v, want %v", upper.Values, want)
+	}
+}
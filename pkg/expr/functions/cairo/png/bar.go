@@ -0,0 +1,199 @@
+package png
+
+import (
+	"math"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+)
+
+// The bars share the Y axis code of the line graphs: the scale of the values is set up by setupYAxis,
+// and the horizontal bars map it to the X axis.
+
+const (
+	barPadding = 5
+	// barFill is the part of the slot of a bar filled by the bar
+	barFill = 0.8
+)
+
+type bar struct {
+	name  string
+	value float64
+	color string
+}
+
+func drawBars(cr *cairoSurfaceContext, params *Params, results []*types.MetricData, emptyText string) error {
+	setSeriesColors(params, results)
+
+	var bars []bar
+	for _, res := range results {
+		value, ok := summarizeSeries(params.pieMode, res)
+		if !ok || math.IsInf(value, 0) {
+			continue
+		}
+		bars = append(bars, bar{name: res.Name, value: value, color: res.Color})
+	}
+
+	if len(bars) == 0 {
+		drawNoData(cr, params, emptyText)
+		return nil
+	}
+
+	if params.graphOnly {
+		params.hideLegend = true
+		params.hideGrid = true
+		params.hideAxes = true
+		params.area.xmin = 0
+		params.area.xmax = params.width
+		params.area.ymin = 0
+		params.area.ymax = params.height
+	} else {
+		drawTitles(cr, params, params.barOrientation == BarOrientationVertical)
+	}
+
+	setFont(cr, params, params.fontSize)
+	if !params.hideLegend {
+		drawLegend(cr, params, results)
+	}
+
+	if !params.hideAxes {
+		// room for the labels of the X axis
+		params.area.ymax -= params.fontExtents.Ascent * 2
+	}
+
+	// the bars start at zero, unless the scale is logarithmic
+	values := make([]float64, 0, len(bars)+1)
+	for _, b := range bars {
+		values = append(values, b.value)
+	}
+	if params.logBase == 0 {
+		values = append(values, 0)
+	}
+	scale := []*types.MetricData{types.MakeMetricData("", values, 1, 0)}
+
+	if params.barOrientation == BarOrientationHorizontal {
+		return drawHorizontalBars(cr, params, bars, scale)
+	}
+
+	return drawVerticalBars(cr, params, bars, scale)
+}
+
+// barBase returns the value the bars start from.
+func barBase(params *Params) float64 {
+	if params.logBase != 0 {
+		return params.yBottom
+	}
+
+	return 0
+}
+
+func drawVerticalBars(cr *cairoSurfaceContext, params *Params, bars []bar, scale []*types.MetricData) error {
+	if params.yAxisSide == YAxisSideRight {
+		params.margin = int(params.width)
+	}
+
+	if err := setupYAxis(cr, params, scale); err != nil {
+		return err
+	}
+
+	if !params.hideAxes {
+		setColor(cr, params.fgColor)
+		if !params.hideYAxis {
+			drawYAxis(cr, params)
+		}
+		if !params.hideGrid {
+			drawHorizontalGridLines(cr, params)
+		}
+	}
+
+	base := getYCoord(params, barBase(params), YCoordSideNone)
+	slot := (params.area.xmax - params.area.xmin) / float64(len(bars))
+	for i, b := range bars {
+		y := getYCoord(params, b.value, YCoordSideNone)
+		if math.IsNaN(y) {
+			continue
+		}
+		y = math.Max(params.area.ymin, math.Min(params.area.ymax, y))
+
+		x := params.area.xmin + slot*float64(i) + slot*(1-barFill)/2
+		setColor(cr, string2RGBA(b.color))
+		drawRectangle(cr, x, math.Min(y, base), slot*barFill, math.Abs(base-y), true)
+	}
+
+	return nil
+}
+
+// barX maps the value to the X axis of the horizontal bars.
+func barX(params *Params, value float64) float64 {
+	y := getYCoord(params, value, YCoordSideNone)
+	if math.IsNaN(y) {
+		return params.area.xmin
+	}
+	ratio := (params.area.ymax - y) / (params.area.ymax - params.area.ymin)
+	ratio = math.Max(0, math.Min(1, ratio))
+
+	return params.area.xmin + ratio*(params.area.xmax-params.area.xmin)
+}
+
+func drawHorizontalBars(cr *cairoSurfaceContext, params *Params, bars []bar, scale []*types.MetricData) error {
+	// the labels of the values go under the bars, not to the left
+	hideYAxis := params.hideYAxis
+	params.hideYAxis = true
+	err := setupYAxis(cr, params, scale)
+	params.hideYAxis = hideYAxis
+	if err != nil {
+		return err
+	}
+
+	if !params.hideAxes && !params.hideYAxis {
+		// the names of the bars go to the left
+		var nameWidth float64
+		for _, b := range bars {
+			nameWidth = math.Max(nameWidth, getTextExtents(cr, b.name).XAdvance)
+		}
+		params.area.xmin = math.Min(params.area.xmin+nameWidth+barPadding, params.area.xmax-barPadding)
+	}
+	if !params.hideAxes && !params.hideXAxis && len(params.yLabels) > 0 {
+		// the last label is centered at the end of the axis
+		lastWidth := getTextExtents(cr, params.yLabels[len(params.yLabels)-1]).XAdvance
+		xMax := params.width - float64(params.margin) - lastWidth/2
+		params.area.xmax = math.Max(params.area.xmin+barPadding, math.Min(params.area.xmax, xMax))
+	}
+
+	slot := (params.area.ymax - params.area.ymin) / float64(len(bars))
+
+	if !params.hideAxes {
+		setColor(cr, params.fgColor)
+		if !params.hideYAxis {
+			for i, b := range bars {
+				y := params.area.ymin + slot*(float64(i)+0.5)
+				drawText(cr, b.name, params.area.xmin-barPadding, y, HAlignRight, VAlignCenter, 0)
+			}
+		}
+		if !params.hideXAxis {
+			y := params.area.ymax + params.fontExtents.Ascent/2
+			for i, value := range params.yLabelValues {
+				drawText(cr, params.yLabels[i], barX(params, value), y, HAlignCenter, VAlignTop, 0)
+			}
+		}
+		if !params.hideGrid {
+			cr.context.SetLineWidth(0.4)
+			setColor(cr, string2RGBA(params.majorGridLineColor))
+			for _, value := range params.yLabelValues {
+				x := barX(params, value)
+				cr.context.MoveTo(x, params.area.ymin)
+				cr.context.LineTo(x, params.area.ymax)
+				cr.context.Stroke()
+			}
+		}
+	}
+
+	base := barX(params, barBase(params))
+	for i, b := range bars {
+		x := barX(params, b.value)
+		y := params.area.ymin + slot*float64(i) + slot*(1-barFill)/2
+		setColor(cr, string2RGBA(b.color))
+		drawRectangle(cr, math.Min(x, base), y, math.Abs(x-base), slot*barFill, true)
+	}
+
+	return nil
+}
@@ -1,28 +1,23 @@
-//go:build cairo
-// +build cairo
-
 package png
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"image/color"
 	"math"
 	"net/http"
-	"os"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/bookingcom/carbonapi/pkg/date"
 	"github.com/bookingcom/carbonapi/pkg/expr/helper"
 	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
 	"github.com/bookingcom/carbonapi/pkg/expr/types"
 	"github.com/bookingcom/carbonapi/pkg/parser"
 	dataTypes "github.com/bookingcom/carbonapi/pkg/types"
-	"github.com/pkg/errors"
+	"github.com/bookingcom/carbonapi/pkg/util"
 
-	"github.com/evmar/gocairo/cairo"
 	"github.com/tebeka/strftime"
 )
 
@@ -364,19 +359,19 @@ var xAxisConfigs = []xAxisStruct{
 // create any visible effects.
 const floatEpsilon = 0.00000000001
 
-func getCairoFontItalic(s FontSlant) cairo.FontSlant {
+func getCairoFontItalic(s FontSlant) FontSlant {
 	if s == FontSlantItalic {
-		return cairo.FontSlantItalic
+		return FontSlantItalic
 	}
-	return cairo.FontSlantNormal
+	return FontSlantNormal
 }
 
-func getCairoFontWeight(weight FontWeight) cairo.FontWeight {
+func getCairoFontWeight(weight FontWeight) FontWeight {
 	if weight == FontWeightBold {
-		return cairo.FontWeightBold
+		return FontWeightBold
 	}
 
-	return cairo.FontWeightNormal
+	return FontWeightNormal
 }
 
 type Area struct {
@@ -398,8 +393,8 @@ type Params struct {
 	minorLine  color.RGBA
 	fontName   string
 	fontSize   float64
-	fontBold   cairo.FontWeight
-	fontItalic cairo.FontSlant
+	fontBold   FontWeight
+	fontItalic FontSlant
 
 	graphOnly   bool
 	hideLegend  bool
@@ -425,6 +420,13 @@ type Params struct {
 	connectedLimit int
 	hasStack       bool
 
+	graphType        GraphType
+	pieLabels        PieLabels
+	valueLabels      ValueLabels
+	valueLabelsMin   float64
+	valueLabelsColor string
+	barOrientation   BarOrientation
+
 	yMin   float64
 	yMax   float64
 	xMin   float64
@@ -489,7 +491,7 @@ type Params struct {
 
 	area        Area
 	isPng       bool // TODO: png and svg use the same code
-	fontExtents cairo.FontExtents
+	fontExtents FontExtents
 
 	uniqueLegend   bool
 	secondYAxis    bool
@@ -666,6 +668,56 @@ func Description() map[string]types.FunctionDescription {
 			Function:    "threshold(value, label=None, color=None)",
 			Group:       "Graph",
 		},
+		"verticalLine": {
+			Name: "verticalLine",
+			Params: []types.FunctionParam{
+				{
+					Name:     "ts",
+					Required: true,
+					Type:     types.Date,
+				},
+				{
+					Name: "label",
+					Type: types.String,
+				},
+				{
+					Name: "color",
+					Type: types.String,
+				},
+			},
+			Module:      "graphite.render.functions",
+			Description: "Takes a timestamp string ts.\n\nDraws a vertical line at the designated timestamp with optional\n'label' and 'color'. Supported timestamp formats include both\nrelative (e.g. -3h) and absolute (e.g. 16:00_20110501) strings,\nsuch as those used with ``from`` and ``until`` parameters. When\nset, the 'label' will appear in the graph legend.\n\nNote: Any timestamps defined outside the requested range will\nraise a 'ValueError' exception.\n\nExample:\n\n.. code-block:: none\n\n  &target=verticalLine(\"12:3420131108\",\"event\",\"blue\")\n  &target=verticalLine(\"16:00_20110501\",\"event\")\n  &target=verticalLine(\"-5mins\")",
+			Function:    "verticalLine(ts, label=None, color=None)",
+			Group:       "Graph",
+		},
+		"holtWintersConfidenceArea": {
+			Name: "holtWintersConfidenceArea",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Default: types.NewSuggestion(3),
+					Name:    "delta",
+					Type:    types.Integer,
+				},
+				{
+					Default: types.NewSuggestion("7d"),
+					Name:    "bootstrapInterval",
+					Suggestions: types.NewSuggestions(
+						"7d",
+						"30d",
+					),
+					Type: types.Interval,
+				},
+			},
+			Module:      "graphite.render.functions",
+			Description: "Performs a Holt-Winters forecast using the series as input data and plots the\narea between the upper and lower bands of the predicted forecast deviations.",
+			Function:    "holtWintersConfidenceArea(seriesList, delta=3, bootstrapInterval='7d')",
+			Group:       "Graph",
+		},
 	}
 }
 
@@ -729,33 +781,27 @@ func EvalExprGraph(ctx context.Context, e parser.Expr, from, until int32, values
 
 		name := fmt.Sprintf("%s(%s)", e.Target(), e.RawArgs())
 
-		lower := *arg[0]
-		lower.Stacked = true
-		lower.StackName = types.DefaultStackName
-		lower.Invisible = true
-		lower.Name = name
-
-		upper := *arg[1]
-		upper.Stacked = true
-		upper.StackName = types.DefaultStackName
-		upper.Name = name
+		return areaBetween(name, arg[0], arg[1]), nil
 
-		vals := make([]float64, len(upper.Values))
-		absent := make([]bool, len(upper.Values))
-
-		for i, v := range upper.Values {
-			if upper.IsAbsent[i] || lower.IsAbsent[i] {
-				absent[i] = true
-				continue
-			}
-
-			vals[i] = v - lower.Values[i]
+	case "holtWintersConfidenceArea": // holtWintersConfidenceArea(seriesList, delta=3, bootstrapInterval='7d')
+		bandsExpr, _, err := parser.ParseExpr(fmt.Sprintf("holtWintersConfidenceBands(%s)", e.RawArgs()))
+		if err != nil {
+			return nil, err
+		}
+		bands, err := helper.GetSeriesArg(ctx, bandsExpr, from, until, values, getTargetData)
+		if err != nil {
+			return nil, err
 		}
 
-		upper.Values = vals
-		upper.IsAbsent = absent
+		// the bands come in pairs of the lower and the upper one for every series
+		var results []*types.MetricData
+		for i := 0; i+1 < len(bands); i += 2 {
+			series := strings.TrimSuffix(strings.TrimPrefix(bands[i].Name, "holtWintersConfidenceLower("), ")")
+			name := fmt.Sprintf("%s(%s)", e.Target(), series)
+			results = append(results, areaBetween(name, bands[i], bands[i+1])...)
+		}
 
-		return []*types.MetricData{&lower, &upper}, nil
+		return results, nil
 
 	case "alpha": // alpha(seriesList, theAlpha)
 		arg, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
@@ -869,11 +915,82 @@ func EvalExprGraph(ctx context.Context, e parser.Expr, from, until int32, values
 
 		return []*types.MetricData{&p}, nil
 
+	case "verticalLine": // verticalLine(ts, label=None, color=None)
+		ts, err := e.GetStringArg(0)
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := date.DateParamToEpoch(ts, "", 0, util.GetTimeZone(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("%w: verticalLine timestamp %s: %v", parser.ErrInvalidArgumentValue, ts, err)
+		}
+		if t < from {
+			return nil, fmt.Errorf("%w: verticalLine timestamp %d exists before start of range", parser.ErrInvalidArgumentValue, t)
+		}
+		if t > until {
+			return nil, fmt.Errorf("%w: verticalLine timestamp %d exists after end of range", parser.ErrInvalidArgumentValue, t)
+		}
+
+		name, err := e.GetStringNamedOrPosArgDefault("label", 1, ts)
+		if err != nil {
+			return nil, err
+		}
+
+		color, err := e.GetStringNamedOrPosArgDefault("color", 2, "")
+		if err != nil {
+			return nil, err
+		}
+
+		p := types.MetricData{
+			Metric: dataTypes.Metric{
+				Name:      name,
+				StartTime: t,
+				StopTime:  t,
+				StepTime:  1,
+				Values:    []float64{1, 1},
+				IsAbsent:  []bool{false, false},
+			},
+			GraphOptions: types.GraphOptions{Color: color, DrawAsInfinite: true},
+		}
+
+		return []*types.MetricData{&p}, nil
 	}
 
 	return nil, fmt.Errorf("%w: %s", helper.ErrUnknownFunction, e.Target())
 }
 
+// areaBetween makes the upper series fill the area above the lower one, which is stacked but invisible.
+func areaBetween(name string, lowerSeries, upperSeries *types.MetricData) []*types.MetricData {
+	lower := *lowerSeries
+	lower.Stacked = true
+	lower.StackName = types.DefaultStackName
+	lower.Invisible = true
+	lower.Name = name
+
+	upper := *upperSeries
+	upper.Stacked = true
+	upper.StackName = types.DefaultStackName
+	upper.Name = name
+
+	vals := make([]float64, len(upper.Values))
+	absent := make([]bool, len(upper.Values))
+
+	for i, v := range upper.Values {
+		if upper.IsAbsent[i] || i >= len(lower.Values) || lower.IsAbsent[i] {
+			absent[i] = true
+			continue
+		}
+
+		vals[i] = v - lower.Values[i]
+	}
+
+	upper.Values = vals
+	upper.IsAbsent = absent
+
+	return []*types.MetricData{&lower, &upper}
+}
+
 func MarshalSVG(params PictureParams, results []*types.MetricData) ([]byte, error) {
 	return marshalCairo(params, results, cairoSVG, "")
 }
@@ -897,6 +1014,14 @@ func MarshalPNGRequestErr(r *http.Request, errStr string, templateName string) (
 
 func marshalCairo(p PictureParams, results []*types.MetricData, backend cairoBackend,
 	emptyText string) ([]byte, error) {
+	return marshalGraph(p, results, backend, emptyText, newSurface)
+}
+
+// surfaceFactory creates the surface the graph is drawn on.
+type surfaceFactory func(backend cairoBackend, params *Params) (graphSurface, error)
+
+func marshalGraph(p PictureParams, results []*types.MetricData, backend cairoBackend,
+	emptyText string, createSurface surfaceFactory) ([]byte, error) {
 	var params = Params{
 		pixelRatio:     p.PixelRatio,
 		width:          p.Width,
@@ -925,6 +1050,13 @@ func marshalCairo(p PictureParams, results []*types.MetricData, backend cairoBac
 		pieMode:        p.PieMode,
 		lineWidth:      p.LineWidth,
 
+		graphType:        p.GraphType,
+		pieLabels:        p.PieLabels,
+		valueLabels:      p.ValueLabels,
+		valueLabelsMin:   p.ValueLabelsMin,
+		valueLabelsColor: p.ValueLabelsColor,
+		barOrientation:   p.BarOrientation,
+
 		rightWidth:  p.RightWidth,
 		rightDashed: p.RightDashed,
 		rightColor:  p.RightColor,
@@ -975,66 +1107,30 @@ func marshalCairo(p PictureParams, results []*types.MetricData, backend cairoBac
 	params.area.ymin = margin
 	params.area.ymax = params.height - margin
 
-	var surface *cairo.Surface
-	var tmpfile *os.File
-	switch backend {
-	case cairoSVG:
-		var err error
-		tmpfile, err = os.CreateTemp("/dev/shm", "cairosvg")
-		if err != nil {
-			return nil, err
-		}
-		defer os.Remove(tmpfile.Name())
-		s := svgSurfaceCreate(tmpfile.Name(), params.width, params.height, params.pixelRatio)
-		surface = s.Surface
-	case cairoPNG:
-		s, err := imageSurfaceCreate(cairo.FormatARGB32, params.width, params.height, params.pixelRatio)
-		if err != nil {
-			return nil, errors.Wrap(err, "could not create image surface via cairo")
-		}
-		surface = s.Surface
+	surface, err := createSurface(backend, &params)
+	if err != nil {
+		return nil, err
 	}
-	cr := createContext(surface, params.pixelRatio)
-
-	// Setting font parameters
-
-	fontOpts := cairo.FontOptionsCreate()
-	fontOpts.SetAntialias(cairo.AntialiasNone)
-	cr.context.SetFontOptions(fontOpts)
+	cr := surface.context()
 
 	setColor(cr, params.bgColor)
 	drawRectangle(cr, 0, 0, params.width, params.height, true)
 
-	err := drawGraph(cr, &params, results, emptyText)
+	switch params.graphType {
+	case GraphTypePie:
+		err = drawPie(cr, &params, results, emptyText)
+	case GraphTypeBar:
+		err = drawBars(cr, &params, results, emptyText)
+	default:
+		err = drawGraph(cr, &params, results, emptyText)
+	}
 	if err != nil {
+		// the surface is finished anyway to release it
+		_, _ = surface.finish()
 		return nil, err
 	}
 
-	surface.Flush()
-
-	var b []byte
-
-	switch backend {
-	case cairoPNG:
-		var buf bytes.Buffer
-		err := surface.WriteToPNG(&buf)
-		if err != nil {
-			return nil, err
-		}
-		surface.Finish()
-		b = buf.Bytes()
-	case cairoSVG:
-		surface.Finish()
-		b, _ = os.ReadFile(tmpfile.Name())
-		// NOTE(dgryski): This is the dumbest thing ever, but needed
-		// for compatibility.  I'm not doing the rest of the svg
-		// munging that graphite does.
-		// We could speed this up with Index(`pt"`) and overwriting the
-		// `t` twice
-		b = bytes.Replace(b, []byte(`pt"`), []byte(`px"`), 2)
-	}
-
-	return b, nil
+	return surface.finish()
 }
 
 func drawGraph(cr *cairoSurfaceContext, params *Params,
@@ -1069,21 +1165,7 @@ func drawGraph(cr *cairoSurfaceContext, params *Params,
 	params.timeRange = params.endTime - params.startTime
 
 	if params.timeRange <= 0 {
-		x := params.width / 2.0
-		y := params.height / 2.0
-		setColor(cr, string2RGBA("red"))
-		fontSize := 1.5 * math.Log(params.width*params.height)
-		setFont(cr, params, fontSize)
-		if emptyText == "" {
-			emptyText = "No Data"
-		} else {
-			if len(emptyText) > 23 {
-				emptyText = emptyText[:20] + "..."
-			}
-		}
-
-		drawText(cr, emptyText, x, y, HAlignCenter, VAlignTop, 0)
-
+		drawNoData(cr, params, emptyText)
 		return nil
 	}
 
@@ -1298,6 +1380,24 @@ func drawGraph(cr *cairoSurfaceContext, params *Params,
 	return nil
 }
 
+// drawNoData draws the text in the middle of an empty graph.
+func drawNoData(cr *cairoSurfaceContext, params *Params, emptyText string) {
+	x := params.width / 2.0
+	y := params.height / 2.0
+	setColor(cr, string2RGBA("red"))
+	fontSize := 1.5 * math.Log(params.width*params.height)
+	setFont(cr, params, fontSize)
+	if emptyText == "" {
+		emptyText = "No Data"
+	} else {
+		if len(emptyText) > 23 {
+			emptyText = emptyText[:20] + "..."
+		}
+	}
+
+	drawText(cr, emptyText, x, y, HAlignCenter, VAlignTop, 0)
+}
+
 func consolidateDataPoints(params *Params, results []*types.MetricData) []*types.MetricData {
 	numberOfPixels := params.area.xmax - params.area.xmin - (params.lineWidth + 1)
 	params.graphWidth = numberOfPixels
@@ -1820,24 +1920,24 @@ func setupYAxis(cr *cairoSurfaceContext, params *Params, results []*types.Metric
 	return nil
 }
 
-func getFontExtents(cr *cairoSurfaceContext) cairo.FontExtents {
+func getFontExtents(cr *cairoSurfaceContext) FontExtents {
 	// TODO(dgryski): allow font options
 	/*
 	   if fontOptions:
 	     self.setFont(**fontOptions)
 	*/
-	var F cairo.FontExtents
+	var F FontExtents
 	cr.context.FontExtents(&F)
 	return F
 }
 
-func getTextExtents(cr *cairoSurfaceContext, text string) cairo.TextExtents {
+func getTextExtents(cr *cairoSurfaceContext, text string) TextExtents {
 	// TODO(dgryski): allow font options
 	/*
 	   if fontOptions:
 	     self.setFont(**fontOptions)
 	*/
-	var T cairo.TextExtents
+	var T TextExtents
 	cr.context.TextExtents(text, &T)
 	return T
 }
@@ -2062,12 +2162,61 @@ func drawXAxis(cr *cairoSurfaceContext, params *Params) {
 }
 
 func drawGridLines(cr *cairoSurfaceContext, params *Params) {
-	// Horizontal grid lines
-	leftside := params.area.xmin
-	rightside := params.area.xmax
 	top := params.area.ymin
 	bottom := params.area.ymax
 
+	drawHorizontalGridLines(cr, params)
+
+	// Vertical grid lines
+
+	// First we do the minor grid lines (majors will paint over them)
+	cr.context.SetLineWidth(0.25)
+	setColor(cr, string2RGBA(params.minorGridLineColor))
+	dt, xMinorDelta := findXTimes(params.startTime, params.xConf.minorGridUnit, params.xConf.minorGridStep)
+
+	for dt < params.endTime {
+		x := params.area.xmin + float64(dt-params.startTime)*params.xScaleFactor
+
+		if x < params.area.xmax {
+			cr.context.MoveTo(x, bottom)
+			cr.context.LineTo(x, top)
+			cr.context.Stroke()
+		}
+
+		dt += xMinorDelta
+	}
+
+	// Now we do the major grid lines
+	cr.context.SetLineWidth(0.33)
+	setColor(cr, string2RGBA(params.majorGridLineColor))
+	dt, xMajorDelta := findXTimes(params.startTime, params.xConf.majorGridUnit, float64(params.xConf.majorGridStep))
+
+	for dt < params.endTime {
+		x := params.area.xmin + float64(dt-params.startTime)*params.xScaleFactor
+
+		if x < params.area.xmax {
+			cr.context.MoveTo(x, bottom)
+			cr.context.LineTo(x, top)
+			cr.context.Stroke()
+		}
+
+		dt += xMajorDelta
+	}
+
+	// Draw side borders for our graph area
+	cr.context.SetLineWidth(0.5)
+	cr.context.MoveTo(params.area.xmax, bottom)
+	cr.context.LineTo(params.area.xmax, top)
+	cr.context.MoveTo(params.area.xmin, bottom)
+	cr.context.LineTo(params.area.xmin, top)
+	cr.context.Stroke()
+}
+
+// drawHorizontalGridLines draws the major and the minor grid lines of the Y labels.
+func drawHorizontalGridLines(cr *cairoSurfaceContext, params *Params) {
+	leftside := params.area.xmin
+	rightside := params.area.xmax
+
 	var labels []float64
 	if params.secondYAxis {
 		labels = params.yLabelValuesL
@@ -2147,74 +2296,30 @@ func drawGridLines(cr *cairoSurfaceContext, params *Params) {
 		}
 
 	}
-
-	// Vertical grid lines
-
-	// First we do the minor grid lines (majors will paint over them)
-	cr.context.SetLineWidth(0.25)
-	setColor(cr, string2RGBA(params.minorGridLineColor))
-	dt, xMinorDelta := findXTimes(params.startTime, params.xConf.minorGridUnit, params.xConf.minorGridStep)
-
-	for dt < params.endTime {
-		x := params.area.xmin + float64(dt-params.startTime)*params.xScaleFactor
-
-		if x < params.area.xmax {
-			cr.context.MoveTo(x, bottom)
-			cr.context.LineTo(x, top)
-			cr.context.Stroke()
-		}
-
-		dt += xMinorDelta
-	}
-
-	// Now we do the major grid lines
-	cr.context.SetLineWidth(0.33)
-	setColor(cr, string2RGBA(params.majorGridLineColor))
-	dt, xMajorDelta := findXTimes(params.startTime, params.xConf.majorGridUnit, float64(params.xConf.majorGridStep))
-
-	for dt < params.endTime {
-		x := params.area.xmin + float64(dt-params.startTime)*params.xScaleFactor
-
-		if x < params.area.xmax {
-			cr.context.MoveTo(x, bottom)
-			cr.context.LineTo(x, top)
-			cr.context.Stroke()
-		}
-
-		dt += xMajorDelta
-	}
-
-	// Draw side borders for our graph area
-	cr.context.SetLineWidth(0.5)
-	cr.context.MoveTo(params.area.xmax, bottom)
-	cr.context.LineTo(params.area.xmax, top)
-	cr.context.MoveTo(params.area.xmin, bottom)
-	cr.context.LineTo(params.area.xmin, top)
-	cr.context.Stroke()
 }
 
-func str2linecap(s string) cairo.LineCap {
+func str2linecap(s string) LineCap {
 	switch s {
 	case "butt":
-		return cairo.LineCapButt
+		return LineCapButt
 	case "round":
-		return cairo.LineCapRound
+		return LineCapRound
 	case "square":
-		return cairo.LineCapSquare
+		return LineCapSquare
 	}
-	return cairo.LineCapButt
+	return LineCapButt
 }
 
-func str2linejoin(s string) cairo.LineJoin {
+func str2linejoin(s string) LineJoin {
 	switch s {
 	case "miter":
-		return cairo.LineJoinMiter
+		return LineJoinMiter
 	case "round":
-		return cairo.LineJoinRound
+		return LineJoinRound
 	case "bevel":
-		return cairo.LineJoinBevel
+		return LineJoinBevel
 	}
-	return cairo.LineJoinMiter
+	return LineJoinMiter
 }
 
 func getYCoord(params *Params, value float64, side YCoordSide) (y float64) {
@@ -2507,7 +2612,7 @@ func drawLegend(cr *cairoSurfaceContext, params *Params, results []*types.Metric
 
 	rightSideLabels := false
 	testSizeName := longestName + " " + longestName
-	var textExtents cairo.TextExtents
+	var textExtents TextExtents
 	cr.context.TextExtents(testSizeName, &textExtents)
 	testWidth := textExtents.XAdvance + 2*(params.fontExtents.Height+padding)
 	if testWidth+50 < params.width {
@@ -2643,9 +2748,9 @@ func radians(angle float64) float64 {
 
 func drawText(cr *cairoSurfaceContext, text string, x, y float64, align HAlign, valign VAlign, rotate float64) {
 	var hAlign, vAlign float64
-	var textExtents cairo.TextExtents
-	var fontExtents cairo.FontExtents
-	var origMatrix cairo.Matrix
+	var textExtents TextExtents
+	var fontExtents FontExtents
+	var origMatrix Matrix
 	cr.context.TextExtents(text, &textExtents)
 	cr.context.FontExtents(&fontExtents)
 
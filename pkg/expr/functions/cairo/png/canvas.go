@@ -0,0 +1,538 @@
+package png
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// canvas is the pure-Go implementation of cairoContext.
+// The paths are kept in device coordinates, i.e. with the pixel ratio and the matrix applied,
+// and are handed over to the output on fill, stroke and clip.
+type canvas struct {
+	out canvasOutput
+	pr  float64 // pixel ratio
+
+	state canvasState
+	saved []canvasState
+
+	path   canvasPath
+	cur    point
+	hasCur bool
+
+	buf sfnt.Buffer
+}
+
+type point struct {
+	x, y float64
+}
+
+type subpath struct {
+	points []point
+	closed bool
+}
+
+// canvasPath is the path in device coordinates.
+type canvasPath []subpath
+
+type canvasState struct {
+	red, green, blue, alpha float64
+
+	lineWidth  float64
+	dashes     []float64
+	dashOffset float64
+	lineCap    LineCap
+	lineJoin   LineJoin
+
+	matrix Matrix
+
+	font     *sfnt.Font
+	fontSize float64
+
+	// clip is specific to the output, nil means no clipping
+	clip interface{}
+}
+
+// canvasOutput draws the paths. The line width and the dashes of the state are in device units
+// when passed to stroke.
+type canvasOutput interface {
+	fill(path canvasPath, st *canvasState)
+	stroke(path canvasPath, st *canvasState)
+	// clip returns the intersection of the current clip of the state with the path
+	clip(path canvasPath, st *canvasState) interface{}
+	finish() ([]byte, error)
+}
+
+func newCanvas(out canvasOutput, pixelRatio float64) *canvas {
+	if isDefaultRatio(pixelRatio) {
+		pixelRatio = 1
+	}
+
+	return &canvas{
+		out: out,
+		pr:  pixelRatio,
+		state: canvasState{
+			alpha:     1,
+			lineWidth: 2,
+			lineCap:   LineCapButt,
+			lineJoin:  LineJoinMiter,
+			matrix:    Matrix{Xx: 1, Yy: 1},
+			font:      selectFont("", FontSlantNormal, FontWeightNormal),
+			fontSize:  10,
+		},
+	}
+}
+
+// toDevice transforms the point in user coordinates.
+func (c *canvas) toDevice(x, y float64) point {
+	m := &c.state.matrix
+	return point{
+		x: c.pr * (m.Xx*x + m.Xy*y + m.X0),
+		y: c.pr * (m.Yx*x + m.Yy*y + m.Y0),
+	}
+}
+
+// toDeviceDistance transforms the vector in user coordinates.
+func (c *canvas) toDeviceDistance(dx, dy float64) point {
+	m := &c.state.matrix
+	return point{
+		x: c.pr * (m.Xx*dx + m.Xy*dy),
+		y: c.pr * (m.Yx*dx + m.Yy*dy),
+	}
+}
+
+// deviceScale is the factor the lengths are scaled by, e.g. the line width.
+func (c *canvas) deviceScale() float64 {
+	m := &c.state.matrix
+	return c.pr * math.Sqrt(math.Abs(m.Xx*m.Yy-m.Xy*m.Yx))
+}
+
+func (c *canvas) moveToDevice(p point) {
+	c.path = append(c.path, subpath{points: []point{p}})
+	c.cur = p
+	c.hasCur = true
+}
+
+func (c *canvas) lineToDevice(p point) {
+	if !c.hasCur {
+		c.moveToDevice(p)
+		return
+	}
+	if len(c.path) == 0 || c.path[len(c.path)-1].closed {
+		// a closed subpath is continued by a new one from its start
+		c.path = append(c.path, subpath{points: []point{c.cur}})
+	}
+	last := &c.path[len(c.path)-1]
+	last.points = append(last.points, p)
+	c.cur = p
+}
+
+func (c *canvas) clearPath() {
+	c.path = nil
+	c.hasCur = false
+}
+
+func (c *canvas) Rectangle(x, y, width, height float64) {
+	c.MoveTo(x, y)
+	c.LineTo(x+width, y)
+	c.LineTo(x+width, y+height)
+	c.LineTo(x, y+height)
+	c.ClosePath()
+}
+
+func (c *canvas) GetLineWidth() float64 {
+	return c.state.lineWidth
+}
+
+func (c *canvas) LineTo(x, y float64) {
+	c.lineToDevice(c.toDevice(x, y))
+}
+
+func (c *canvas) MoveTo(x, y float64) {
+	c.moveToDevice(c.toDevice(x, y))
+}
+
+func (c *canvas) RelMoveTo(dx, dy float64) {
+	d := c.toDeviceDistance(dx, dy)
+	c.moveToDevice(point{x: c.cur.x + d.x, y: c.cur.y + d.y})
+}
+
+// Arc adds a circular arc in the direction of increasing angles. As in cairo, a line from
+// the current point to the start of the arc is added too.
+func (c *canvas) Arc(xc, yc, radius, angle1, angle2 float64) {
+	for angle2 < angle1 {
+		angle2 += 2 * math.Pi
+	}
+
+	// segments of about two device pixels
+	steps := int(math.Max(4, math.Ceil((angle2-angle1)*radius*c.deviceScale()/2)))
+	for i := 0; i <= steps; i++ {
+		angle := angle1 + (angle2-angle1)*float64(i)/float64(steps)
+		sin, cos := math.Sincos(angle)
+		c.LineTo(xc+radius*cos, yc+radius*sin)
+	}
+}
+
+func (c *canvas) ClosePath() {
+	if len(c.path) == 0 {
+		return
+	}
+	last := &c.path[len(c.path)-1]
+	last.closed = true
+	c.cur = last.points[0]
+}
+
+func (c *canvas) SetLineWidth(width float64) {
+	c.state.lineWidth = width
+}
+
+func (c *canvas) SetDash(dashes []float64, offset float64) {
+	c.state.dashes = append([]float64(nil), dashes...)
+	c.state.dashOffset = offset
+}
+
+func (c *canvas) SetLineCap(lineCap LineCap) {
+	c.state.lineCap = lineCap
+}
+
+func (c *canvas) SetLineJoin(lineJoin LineJoin) {
+	c.state.lineJoin = lineJoin
+}
+
+func (c *canvas) SetSourceRGBA(red, green, blue, alpha float64) {
+	c.state.red, c.state.green, c.state.blue, c.state.alpha = red, green, blue, alpha
+}
+
+// deviceState returns the state with the line width and the dashes in device units.
+func (c *canvas) deviceState() *canvasState {
+	st := c.state
+	scale := c.deviceScale()
+	st.lineWidth *= scale
+	st.dashOffset *= scale
+	st.dashes = make([]float64, len(c.state.dashes))
+	for i, d := range c.state.dashes {
+		st.dashes[i] = d * scale
+	}
+
+	return &st
+}
+
+func (c *canvas) Stroke() {
+	if len(c.path) != 0 && c.state.alpha > 0 {
+		c.out.stroke(c.path, c.deviceState())
+	}
+	c.clearPath()
+}
+
+func (c *canvas) FillPreserve() {
+	if len(c.path) != 0 && c.state.alpha > 0 {
+		c.out.fill(c.path, &c.state)
+	}
+}
+
+func (c *canvas) Fill() {
+	c.FillPreserve()
+	c.clearPath()
+}
+
+func (c *canvas) Clip() {
+	c.state.clip = c.out.clip(c.path, &c.state)
+	c.clearPath()
+}
+
+func (c *canvas) Save() {
+	c.saved = append(c.saved, c.state)
+}
+
+func (c *canvas) Restore() {
+	if len(c.saved) == 0 {
+		return
+	}
+	c.state = c.saved[len(c.saved)-1]
+	c.saved = c.saved[:len(c.saved)-1]
+}
+
+func (c *canvas) SetMatrix(matrix *Matrix) {
+	c.state.matrix = *matrix
+}
+
+func (c *canvas) GetMatrix(matrix *Matrix) {
+	*matrix = c.state.matrix
+}
+
+// Rotate rotates the user space, the rotation is applied to the points before the current matrix.
+func (c *canvas) Rotate(angle float64) {
+	sin, cos := math.Sincos(angle)
+	m := c.state.matrix
+	c.state.matrix = Matrix{
+		Xx: m.Xx*cos + m.Xy*sin,
+		Yx: m.Yx*cos + m.Yy*sin,
+		Xy: -m.Xx*sin + m.Xy*cos,
+		Yy: -m.Yx*sin + m.Yy*cos,
+		X0: m.X0,
+		Y0: m.Y0,
+	}
+}
+
+func (c *canvas) CopyPath() savedPath {
+	p := make(canvasPath, len(c.path))
+	for i, sp := range c.path {
+		p[i] = subpath{points: append([]point(nil), sp.points...), closed: sp.closed}
+	}
+
+	return p
+}
+
+// AppendPath appends the copied path. The current point is moved to its end,
+// so the last subpath can be continued.
+func (c *canvas) AppendPath(path savedPath) {
+	p, ok := path.(canvasPath)
+	if !ok {
+		return
+	}
+	for _, sp := range p {
+		if len(sp.points) == 0 {
+			continue
+		}
+		c.path = append(c.path, subpath{points: append([]point(nil), sp.points...), closed: sp.closed})
+		c.cur = sp.points[len(sp.points)-1]
+		if sp.closed {
+			c.cur = sp.points[0]
+		}
+		c.hasCur = true
+	}
+}
+
+func (c *canvas) SelectFontFace(family string, slant FontSlant, weight FontWeight) {
+	c.state.font = selectFont(family, slant, weight)
+}
+
+func (c *canvas) SetFontSize(size float64) {
+	c.state.fontSize = size
+}
+
+func (c *canvas) ppem() fixed.Int26_6 {
+	return fixed.Int26_6(math.Round(c.state.fontSize * 64))
+}
+
+func (c *canvas) FontExtents(extents *FontExtents) {
+	*extents = FontExtents{}
+	f := c.state.font
+	m, err := f.Metrics(&c.buf, c.ppem(), font.HintingNone)
+	if err != nil {
+		return
+	}
+	bounds, err := f.Bounds(&c.buf, c.ppem(), font.HintingNone)
+	if err != nil {
+		return
+	}
+
+	extents.Ascent = fromFixed(m.Ascent)
+	extents.Descent = fromFixed(m.Descent)
+	extents.Height = fromFixed(m.Height)
+	extents.MaxXAdvance = fromFixed(bounds.Max.X - bounds.Min.X)
+}
+
+// glyphs calls fn with the index and the pen position of each glyph of the text,
+// and returns the advance of the whole text.
+func (c *canvas) glyphs(text string, fn func(idx sfnt.GlyphIndex, pen fixed.Int26_6)) fixed.Int26_6 {
+	f := c.state.font
+	ppem := c.ppem()
+
+	var pen fixed.Int26_6
+	var prev sfnt.GlyphIndex
+	for i, r := range text {
+		idx, err := f.GlyphIndex(&c.buf, r)
+		if err != nil {
+			continue
+		}
+		if i > 0 {
+			if k, err := f.Kern(&c.buf, prev, idx, ppem, font.HintingNone); err == nil {
+				pen += k
+			}
+		}
+		fn(idx, pen)
+
+		advance, err := f.GlyphAdvance(&c.buf, idx, ppem, font.HintingNone)
+		if err == nil {
+			pen += advance
+		}
+		prev = idx
+	}
+
+	return pen
+}
+
+func (c *canvas) TextExtents(utf8 string, extents *TextExtents) {
+	f := c.state.font
+	ppem := c.ppem()
+
+	var ink fixed.Rectangle26_6
+	hasInk := false
+	advance := c.glyphs(utf8, func(idx sfnt.GlyphIndex, pen fixed.Int26_6) {
+		bounds, _, err := f.GlyphBounds(&c.buf, idx, ppem, font.HintingNone)
+		if err != nil || bounds.Empty() {
+			return
+		}
+		bounds = bounds.Add(fixed.Point26_6{X: pen})
+		if hasInk {
+			ink = ink.Union(bounds)
+		} else {
+			ink = bounds
+			hasInk = true
+		}
+	})
+
+	*extents = TextExtents{
+		XBearing: fromFixed(ink.Min.X),
+		YBearing: fromFixed(ink.Min.Y),
+		Width:    fromFixed(ink.Max.X - ink.Min.X),
+		Height:   fromFixed(ink.Max.Y - ink.Min.Y),
+		XAdvance: fromFixed(advance),
+	}
+}
+
+// TextPath adds the outlines of the glyphs to the path. The text starts at the current point,
+// which is moved to its end.
+func (c *canvas) TextPath(utf8 string) {
+	f := c.state.font
+	ppem := c.ppem()
+	origin := c.cur
+
+	glyphPoint := func(pen fixed.Int26_6, p fixed.Point26_6) point {
+		d := c.toDeviceDistance(fromFixed(pen+p.X), fromFixed(p.Y))
+		return point{x: origin.x + d.x, y: origin.y + d.y}
+	}
+
+	advance := c.glyphs(utf8, func(idx sfnt.GlyphIndex, pen fixed.Int26_6) {
+		segments, err := f.LoadGlyph(&c.buf, idx, ppem, nil)
+		if err != nil {
+			return
+		}
+
+		var last point
+		for _, s := range segments {
+			switch s.Op {
+			case sfnt.SegmentOpMoveTo:
+				c.ClosePath()
+				last = glyphPoint(pen, s.Args[0])
+				c.moveToDevice(last)
+			case sfnt.SegmentOpLineTo:
+				last = glyphPoint(pen, s.Args[0])
+				c.lineToDevice(last)
+			case sfnt.SegmentOpQuadTo:
+				p1, p2 := glyphPoint(pen, s.Args[0]), glyphPoint(pen, s.Args[1])
+				for _, p := range flattenQuad(last, p1, p2) {
+					c.lineToDevice(p)
+				}
+				last = p2
+			case sfnt.SegmentOpCubeTo:
+				p1, p2, p3 := glyphPoint(pen, s.Args[0]), glyphPoint(pen, s.Args[1]), glyphPoint(pen, s.Args[2])
+				for _, p := range flattenCube(last, p1, p2, p3) {
+					c.lineToDevice(p)
+				}
+				last = p3
+			}
+		}
+		c.ClosePath()
+	})
+
+	d := c.toDeviceDistance(fromFixed(advance), 0)
+	c.cur = point{x: origin.x + d.x, y: origin.y + d.y}
+	c.hasCur = true
+}
+
+const curveSteps = 8
+
+func flattenQuad(p0, p1, p2 point) []point {
+	res := make([]point, 0, curveSteps)
+	for i := 1; i <= curveSteps; i++ {
+		t := float64(i) / curveSteps
+		u := 1 - t
+		res = append(res, point{
+			x: u*u*p0.x + 2*u*t*p1.x + t*t*p2.x,
+			y: u*u*p0.y + 2*u*t*p1.y + t*t*p2.y,
+		})
+	}
+
+	return res
+}
+
+func flattenCube(p0, p1, p2, p3 point) []point {
+	res := make([]point, 0, curveSteps)
+	for i := 1; i <= curveSteps; i++ {
+		t := float64(i) / curveSteps
+		u := 1 - t
+		res = append(res, point{
+			x: u*u*u*p0.x + 3*u*u*t*p1.x + 3*u*t*t*p2.x + t*t*t*p3.x,
+			y: u*u*u*p0.y + 3*u*u*t*p1.y + 3*u*t*t*p2.y + t*t*t*p3.y,
+		})
+	}
+
+	return res
+}
+
+func fromFixed(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+var (
+	fontsOnce sync.Once
+	fonts     map[string]*sfnt.Font
+)
+
+// selectFont picks one of the Go fonts. The monospace families map to Go Mono, everything else
+// to the proportional Go font.
+func selectFont(family string, slant FontSlant, weight FontWeight) *sfnt.Font {
+	fontsOnce.Do(func() {
+		fonts = make(map[string]*sfnt.Font)
+		for name, ttf := range map[string][]byte{
+			"sans":            goregular.TTF,
+			"sans-bold":       gobold.TTF,
+			"sans-italic":     goitalic.TTF,
+			"sans-bolditalic": gobolditalic.TTF,
+			"mono":            gomono.TTF,
+			"mono-bold":       gomonobold.TTF,
+			"mono-italic":     gomonoitalic.TTF,
+			"mono-bolditalic": gomonobolditalic.TTF,
+		} {
+			f, err := sfnt.Parse(ttf)
+			if err != nil {
+				panic(fmt.Sprintf("failed to parse the built-in font %s: %v", name, err))
+			}
+			fonts[name] = f
+		}
+	})
+
+	name := "sans"
+	family = strings.ToLower(family)
+	if strings.Contains(family, "mono") || strings.Contains(family, "courier") {
+		name = "mono"
+	}
+
+	style := ""
+	if weight == FontWeightBold {
+		style = "bold"
+	}
+	if slant == FontSlantItalic || slant == FontSlantOblique {
+		style += "italic"
+	}
+	if style != "" {
+		name += "-" + style
+	}
+
+	return fonts[name]
+}
@@ -0,0 +1,29 @@
+//go:build cairo
+// +build cairo
+
+package png
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCanvasMatchesCairo compares the golden images of the pure-Go canvas with the cairo output.
+// The fonts and the antialiasing differ, so the images are only compared in blocks.
+func TestCanvasMatchesCairo(t *testing.T) {
+	for _, tc := range graphTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := renderTestGraph(t, tc, cairoPNG, newSurface)
+
+			golden := filepath.Join("testdata", tc.name+".png")
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read the golden image: %v", err)
+			}
+			if d := imageDifference(decodePNG(t, got), decodePNG(t, want), 8); d > 0.05 {
+				t.Errorf("the cairo image differs from %s by %f", golden, d)
+			}
+		})
+	}
+}
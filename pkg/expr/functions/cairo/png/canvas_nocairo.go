@@ -0,0 +1,9 @@
+//go:build !cairo
+// +build !cairo
+
+package png
+
+// newSurface creates the surface of the backend. Without cairo the graphs are drawn by the pure-Go canvas.
+func newSurface(backend cairoBackend, params *Params) (graphSurface, error) {
+	return newCanvasSurface(backend, params)
+}
@@ -0,0 +1,147 @@
+package png
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"golang.org/x/image/vector"
+)
+
+// rasterOutput draws the paths into an RGBA image and encodes it as PNG.
+type rasterOutput struct {
+	img *image.RGBA
+	z   vector.Rasterizer
+}
+
+func newRasterOutput(width, height, pixelRatio float64) (*rasterOutput, error) {
+	w := int(width)
+	h := int(height)
+	if !isDefaultRatio(pixelRatio) {
+		w = int(pixelRatio * width)
+		h = int(pixelRatio * height)
+	}
+	// the same limits as in cairo
+	if w > math.MaxInt16 || h > math.MaxInt16 {
+		return nil, fmt.Errorf("requested image width (%d) or height (%d) exceeds img size limit of %d", w, h, math.MaxInt16)
+	}
+	if w < 0 || h < 0 {
+		return nil, fmt.Errorf("requested image width (%d) or height (%d) is less than zero", w, h)
+	}
+
+	return &rasterOutput{img: image.NewRGBA(image.Rect(0, 0, w, h))}, nil
+}
+
+// polygonBounds returns the pixels touched by the polygons, limited to the image.
+func (o *rasterOutput) polygonBounds(polygons [][]point) image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, poly := range polygons {
+		for _, p := range poly {
+			minX, maxX = math.Min(minX, p.x), math.Max(maxX, p.x)
+			minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+		}
+	}
+	if minX > maxX || math.IsNaN(minX) || math.IsNaN(minY) {
+		return image.Rectangle{}
+	}
+
+	r := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1)
+	return r.Intersect(o.img.Bounds())
+}
+
+// mask rasterizes the polygons with the non-zero winding rule into the coverage of the rectangle.
+func (o *rasterOutput) mask(polygons [][]point, r image.Rectangle) *image.Alpha {
+	m := image.NewAlpha(image.Rect(0, 0, r.Dx(), r.Dy()))
+	o.z.Reset(r.Dx(), r.Dy())
+	dx, dy := float64(r.Min.X), float64(r.Min.Y)
+	for _, poly := range polygons {
+		if len(poly) < 3 {
+			continue
+		}
+		o.z.MoveTo(float32(poly[0].x-dx), float32(poly[0].y-dy))
+		for _, p := range poly[1:] {
+			o.z.LineTo(float32(p.x-dx), float32(p.y-dy))
+		}
+		o.z.ClosePath()
+	}
+	o.z.Draw(m, m.Bounds(), image.Opaque, image.Point{})
+
+	return m
+}
+
+func (o *rasterOutput) draw(polygons [][]point, st *canvasState) {
+	r := o.polygonBounds(polygons)
+	if r.Empty() {
+		return
+	}
+	m := o.mask(polygons, r)
+
+	if clip, ok := st.clip.(*image.Alpha); ok {
+		for y := 0; y < r.Dy(); y++ {
+			row := m.Pix[y*m.Stride : y*m.Stride+r.Dx()]
+			clipRow := clip.Pix[clip.PixOffset(r.Min.X, r.Min.Y+y):]
+			for x := range row {
+				row[x] = uint8(uint16(row[x]) * uint16(clipRow[x]) / 255)
+			}
+		}
+	}
+
+	src := image.NewUniform(color.NRGBA{
+		R: colorComponent(st.red),
+		G: colorComponent(st.green),
+		B: colorComponent(st.blue),
+		A: colorComponent(st.alpha),
+	})
+	draw.DrawMask(o.img, r, src, image.Point{}, m, image.Point{}, draw.Over)
+}
+
+func colorComponent(v float64) uint8 {
+	return uint8(math.Round(255 * math.Max(0, math.Min(1, v))))
+}
+
+func (o *rasterOutput) fill(path canvasPath, st *canvasState) {
+	polygons := make([][]point, 0, len(path))
+	for _, sp := range path {
+		polygons = append(polygons, sp.points)
+	}
+	o.draw(polygons, st)
+}
+
+func (o *rasterOutput) stroke(path canvasPath, st *canvasState) {
+	o.draw(strokePolygons(path, st), st)
+}
+
+func (o *rasterOutput) clip(path canvasPath, st *canvasState) interface{} {
+	polygons := make([][]point, 0, len(path))
+	for _, sp := range path {
+		polygons = append(polygons, sp.points)
+	}
+
+	b := o.img.Bounds()
+	clip := image.NewAlpha(b)
+	if r := o.polygonBounds(polygons); !r.Empty() {
+		draw.Draw(clip, r, o.mask(polygons, r), image.Point{}, draw.Src)
+	}
+
+	if prev, ok := st.clip.(*image.Alpha); ok {
+		for i := range clip.Pix {
+			clip.Pix[i] = uint8(uint16(clip.Pix[i]) * uint16(prev.Pix[i]) / 255)
+		}
+	}
+
+	return clip
+}
+
+func (o *rasterOutput) finish() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, o.img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
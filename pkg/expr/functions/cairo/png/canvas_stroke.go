@@ -0,0 +1,261 @@
+package png
+
+import (
+	"math"
+)
+
+// The strokes are rasterized as the union of polygons: a quadrilateral per segment, plus the joins
+// and the caps. All of them are oriented the same way, so that their coverage adds up with
+// the non-zero winding rule instead of cancelling out.
+
+const miterLimit = 10
+
+// strokePolygons outlines the path with the line width, dashes, caps and joins of the state.
+func strokePolygons(path canvasPath, st *canvasState) [][]point {
+	hw := st.lineWidth / 2
+	if hw <= 0 {
+		return nil
+	}
+
+	var polygons [][]point
+	add := func(poly []point) {
+		if len(poly) >= 3 {
+			polygons = append(polygons, orient(poly))
+		}
+	}
+
+	for _, sp := range path {
+		for _, line := range dashLine(sp, st.dashes, st.dashOffset) {
+			strokeLine(line.points, line.closed, hw, st.lineCap, st.lineJoin, add)
+		}
+	}
+
+	return polygons
+}
+
+// dashLine splits the subpath into the dashes, which are open polylines.
+func dashLine(sp subpath, dashes []float64, offset float64) []subpath {
+	points := dedup(sp.points)
+	if !hasDashes(dashes) || len(points) < 2 {
+		return []subpath{{points: points, closed: sp.closed}}
+	}
+	if sp.closed {
+		points = append(points, points[0])
+	}
+
+	total := 0.0
+	for _, d := range dashes {
+		total += d
+	}
+	if len(dashes)%2 == 1 {
+		// an odd dash array is repeated to make the on and off parts alternate
+		total *= 2
+	}
+	dash := func(i int) float64 { return dashes[i%len(dashes)] }
+
+	// find the dash the offset falls into
+	i := 0
+	remaining := dash(0)
+	offset = math.Mod(offset, total)
+	if offset < 0 {
+		offset += total
+	}
+	for offset > 0 {
+		if offset < remaining {
+			remaining -= offset
+			break
+		}
+		offset -= remaining
+		i++
+		remaining = dash(i)
+	}
+
+	var res []subpath
+	on := i%2 == 0
+	var current []point
+	if on {
+		current = []point{points[0]}
+	}
+	for j := 1; j < len(points); j++ {
+		a, b := points[j-1], points[j]
+		length := math.Hypot(b.x-a.x, b.y-a.y)
+		pos := 0.0
+		for length-pos > remaining {
+			pos += remaining
+			t := pos / length
+			p := point{x: a.x + t*(b.x-a.x), y: a.y + t*(b.y-a.y)}
+			if on {
+				current = append(current, p)
+				res = append(res, subpath{points: current})
+				current = nil
+			} else {
+				current = []point{p}
+			}
+			on = !on
+			i++
+			remaining = dash(i)
+		}
+		remaining -= length - pos
+		if on {
+			current = append(current, b)
+		}
+	}
+	if on && len(current) > 1 {
+		res = append(res, subpath{points: current})
+	}
+
+	return res
+}
+
+func hasDashes(dashes []float64) bool {
+	for _, d := range dashes {
+		if d > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func dedup(points []point) []point {
+	res := make([]point, 0, len(points))
+	for _, p := range points {
+		if len(res) > 0 && res[len(res)-1] == p {
+			continue
+		}
+		res = append(res, p)
+	}
+	if len(res) > 1 && res[0] == res[len(res)-1] {
+		// the closing segment is implicit
+		res = res[:len(res)-1]
+	}
+
+	return res
+}
+
+func strokeLine(points []point, closed bool, hw float64, lineCap LineCap, lineJoin LineJoin, add func([]point)) {
+	if len(points) == 0 {
+		return
+	}
+	if len(points) == 1 {
+		// a degenerate line is only visible with the round and the square caps
+		p := points[0]
+		switch lineCap {
+		case LineCapRound:
+			add(circle(p, hw))
+		case LineCapSquare:
+			add([]point{{p.x - hw, p.y - hw}, {p.x + hw, p.y - hw}, {p.x + hw, p.y + hw}, {p.x - hw, p.y + hw}})
+		}
+		return
+	}
+
+	n := len(points)
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+
+	for i := 0; i < segments; i++ {
+		a, b := points[i], points[(i+1)%n]
+		nx, ny := normal(a, b, hw)
+		add([]point{{a.x + nx, a.y + ny}, {b.x + nx, b.y + ny}, {b.x - nx, b.y - ny}, {a.x - nx, a.y - ny}})
+	}
+
+	for i := 0; i < n; i++ {
+		if !closed && (i == 0 || i == n-1) {
+			continue
+		}
+		prev, p, next := points[(i+n-1)%n], points[i], points[(i+1)%n]
+		addJoin(prev, p, next, hw, lineJoin, add)
+	}
+
+	if !closed {
+		addCap(points[1], points[0], hw, lineCap, add)
+		addCap(points[n-2], points[n-1], hw, lineCap, add)
+	}
+}
+
+// normal returns the normal of the segment with the length of hw.
+func normal(a, b point, hw float64) (float64, float64) {
+	dx, dy := b.x-a.x, b.y-a.y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return 0, 0
+	}
+
+	return -dy / l * hw, dx / l * hw
+}
+
+func addJoin(prev, p, next point, hw float64, lineJoin LineJoin, add func([]point)) {
+	if lineJoin == LineJoinRound {
+		add(circle(p, hw))
+		return
+	}
+
+	n1x, n1y := normal(prev, p, hw)
+	n2x, n2y := normal(p, next, hw)
+	// both sides are added, the inner one is covered by the segments anyway
+	for _, side := range []float64{1, -1} {
+		a := point{p.x + side*n1x, p.y + side*n1y}
+		b := point{p.x + side*n2x, p.y + side*n2y}
+		if lineJoin == LineJoinMiter {
+			if m, ok := miterPoint(a, point{p.x - prev.x, p.y - prev.y}, b, point{next.x - p.x, next.y - p.y}); ok &&
+				math.Hypot(m.x-p.x, m.y-p.y) <= miterLimit*hw {
+				add([]point{p, a, m, b})
+				continue
+			}
+		}
+		add([]point{p, a, b})
+	}
+}
+
+// miterPoint intersects the line through a with the direction da and the line through b with the direction db.
+func miterPoint(a, da, b, db point) (point, bool) {
+	cross := da.x*db.y - da.y*db.x
+	if math.Abs(cross) < 1e-9 {
+		return point{}, false
+	}
+	t := ((b.x-a.x)*db.y - (b.y-a.y)*db.x) / cross
+
+	return point{a.x + t*da.x, a.y + t*da.y}, true
+}
+
+// addCap adds the cap at the end b of the segment from a.
+func addCap(a, b point, hw float64, lineCap LineCap, add func([]point)) {
+	switch lineCap {
+	case LineCapRound:
+		add(circle(b, hw))
+	case LineCapSquare:
+		nx, ny := normal(a, b, hw)
+		// the direction of the segment, rotated back from the normal
+		dx, dy := ny, -nx
+		add([]point{{b.x + nx, b.y + ny}, {b.x + nx + dx, b.y + ny + dy}, {b.x - nx + dx, b.y - ny + dy}, {b.x - nx, b.y - ny}})
+	}
+}
+
+func circle(c point, r float64) []point {
+	steps := int(math.Max(8, math.Ceil(r*4)))
+	res := make([]point, steps)
+	for i := range res {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(steps))
+		res[i] = point{c.x + r*cos, c.y + r*sin}
+	}
+
+	return res
+}
+
+// orient reverses the polygon if needed, so that all the polygons have the same orientation.
+func orient(poly []point) []point {
+	area := 0.0
+	for i := range poly {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		area += a.x*b.y - b.x*a.y
+	}
+	if area < 0 {
+		for i, j := 0, len(poly)-1; i < j; i, j = i+1, j-1 {
+			poly[i], poly[j] = poly[j], poly[i]
+		}
+	}
+
+	return poly
+}
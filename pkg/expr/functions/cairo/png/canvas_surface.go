@@ -0,0 +1,34 @@
+package png
+
+// canvasSurface draws the graph with the pure-Go canvas.
+type canvasSurface struct {
+	out canvasOutput
+	cr  *cairoSurfaceContext
+}
+
+func newCanvasSurface(backend cairoBackend, params *Params) (graphSurface, error) {
+	var out canvasOutput
+	switch backend {
+	case cairoSVG:
+		out = newSVGOutput(params.width, params.height, params.pixelRatio)
+	default:
+		raster, err := newRasterOutput(params.width, params.height, params.pixelRatio)
+		if err != nil {
+			return nil, err
+		}
+		out = raster
+	}
+
+	return &canvasSurface{
+		out: out,
+		cr:  &cairoSurfaceContext{context: newCanvas(out, params.pixelRatio)},
+	}, nil
+}
+
+func (s *canvasSurface) context() *cairoSurfaceContext {
+	return s.cr
+}
+
+func (s *canvasSurface) finish() ([]byte, error) {
+	return s.out.finish()
+}
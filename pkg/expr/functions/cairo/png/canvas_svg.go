@@ -0,0 +1,144 @@
+package png
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// svgOutput writes the paths as SVG path elements.
+type svgOutput struct {
+	width, height float64
+
+	buf   bytes.Buffer
+	defs  bytes.Buffer
+	clips int
+}
+
+// svgClip is the id of a clipPath element.
+type svgClip string
+
+func newSVGOutput(width, height, pixelRatio float64) *svgOutput {
+	if !isDefaultRatio(pixelRatio) {
+		width *= pixelRatio
+		height *= pixelRatio
+	}
+
+	return &svgOutput{width: width, height: height}
+}
+
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func svgPathData(path canvasPath, minPoints int) string {
+	var sb strings.Builder
+	for _, sp := range path {
+		if len(sp.points) < minPoints {
+			continue
+		}
+		for i, p := range sp.points {
+			if i == 0 {
+				sb.WriteString("M")
+			} else {
+				sb.WriteString(" L")
+			}
+			sb.WriteString(strconv.FormatFloat(p.x, 'f', 2, 64))
+			sb.WriteString(" ")
+			sb.WriteString(strconv.FormatFloat(p.y, 'f', 2, 64))
+		}
+		if sp.closed {
+			sb.WriteString(" Z")
+		}
+		sb.WriteString(" ")
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+func svgColor(st *canvasState) string {
+	return fmt.Sprintf("rgb(%d,%d,%d)", colorComponent(st.red), colorComponent(st.green), colorComponent(st.blue))
+}
+
+func svgClipAttr(st *canvasState) string {
+	if id, ok := st.clip.(svgClip); ok {
+		return fmt.Sprintf(` clip-path="url(#%s)"`, id)
+	}
+
+	return ""
+}
+
+func (o *svgOutput) fill(path canvasPath, st *canvasState) {
+	d := svgPathData(path, 3)
+	if d == "" {
+		return
+	}
+
+	fmt.Fprintf(&o.buf, `<path d="%s" fill="%s"`, d, svgColor(st))
+	if st.alpha < 1 {
+		fmt.Fprintf(&o.buf, ` fill-opacity="%s"`, svgNumber(st.alpha))
+	}
+	fmt.Fprintf(&o.buf, `%s/>`+"\n", svgClipAttr(st))
+}
+
+func (o *svgOutput) stroke(path canvasPath, st *canvasState) {
+	d := svgPathData(path, 2)
+	if d == "" {
+		return
+	}
+
+	fmt.Fprintf(&o.buf, `<path d="%s" fill="none" stroke="%s" stroke-width="%s"`, d, svgColor(st), svgNumber(st.lineWidth))
+	if st.alpha < 1 {
+		fmt.Fprintf(&o.buf, ` stroke-opacity="%s"`, svgNumber(st.alpha))
+	}
+	switch st.lineCap {
+	case LineCapRound:
+		o.buf.WriteString(` stroke-linecap="round"`)
+	case LineCapSquare:
+		o.buf.WriteString(` stroke-linecap="square"`)
+	}
+	switch st.lineJoin {
+	case LineJoinRound:
+		o.buf.WriteString(` stroke-linejoin="round"`)
+	case LineJoinBevel:
+		o.buf.WriteString(` stroke-linejoin="bevel"`)
+	}
+	if hasDashes(st.dashes) {
+		dashes := make([]string, len(st.dashes))
+		for i, v := range st.dashes {
+			dashes[i] = svgNumber(v)
+		}
+		fmt.Fprintf(&o.buf, ` stroke-dasharray="%s"`, strings.Join(dashes, ","))
+		if st.dashOffset != 0 {
+			fmt.Fprintf(&o.buf, ` stroke-dashoffset="%s"`, svgNumber(st.dashOffset))
+		}
+	}
+	fmt.Fprintf(&o.buf, `%s/>`+"\n", svgClipAttr(st))
+}
+
+// clip defines a new clipPath, which is nested into the previous one by its own clip-path attribute.
+func (o *svgOutput) clip(path canvasPath, st *canvasState) interface{} {
+	o.clips++
+	id := svgClip(fmt.Sprintf("clip%d", o.clips))
+	fmt.Fprintf(&o.defs, `<clipPath id="%s"%s><path d="%s"/></clipPath>`+"\n", id, svgClipAttr(st), svgPathData(path, 3))
+
+	return id
+}
+
+func (o *svgOutput) finish() ([]byte, error) {
+	var b bytes.Buffer
+	b.Grow(o.buf.Len() + o.defs.Len() + 256)
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%spx" height="%spx" viewBox="0 0 %s %s" version="1.1">`+"\n",
+		svgNumber(o.width), svgNumber(o.height), svgNumber(o.width), svgNumber(o.height))
+	if o.defs.Len() > 0 {
+		b.WriteString("<defs>\n")
+		b.Write(o.defs.Bytes())
+		b.WriteString("</defs>\n")
+	}
+	b.Write(o.buf.Bytes())
+	b.WriteString("</svg>\n")
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,269 @@
+package png
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"image"
+	"image/draw"
+	stdpng "image/png"
+	"io"
+	"math"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+)
+
+var update = flag.Bool("update", false, "update the golden images in testdata")
+
+type graphTestCase struct {
+	name     string
+	query    string
+	template string
+	results  func() []*types.MetricData
+}
+
+func sineAndLine() []*types.MetricData {
+	sine := make([]float64, 60)
+	line := make([]float64, 60)
+	for i := range sine {
+		sine[i] = 50 + 40*math.Sin(float64(i)/6)
+		line[i] = float64(i)
+	}
+	line[20] = math.NaN()
+
+	return []*types.MetricData{
+		types.MakeMetricData("foo.bar.sine", sine, 60, 1600000000),
+		types.MakeMetricData("foo.bar.line", line, 60, 1600000000),
+	}
+}
+
+func secondYAxis() []*types.MetricData {
+	res := sineAndLine()
+	for i := range res[1].Values {
+		res[1].Values[i] *= 1000
+	}
+	res[1].SecondYAxis = true
+
+	return res
+}
+
+func topN() []*types.MetricData {
+	var res []*types.MetricData
+	for i, name := range []string{"servers.web01.requests", "servers.web02.requests", "servers.db01.requests", "servers.cache01.requests"} {
+		values := make([]float64, 30)
+		for j := range values {
+			values[j] = float64((4-i)*100 + j)
+		}
+		res = append(res, types.MakeMetricData(name, values, 60, 1600000000))
+	}
+
+	return res
+}
+
+func init() {
+	light := DefaultParams
+	light.BgColor = "white"
+	light.FgColor = "black"
+	light.FontName = "Monospace"
+	light.FontBold = FontWeightBold
+	SetTemplate("test-light", light)
+}
+
+var graphTestCases = []graphTestCase{
+	{name: "lines", query: "width=400&height=200", results: sineAndLine},
+	{name: "title", query: "width=400&height=200&title=Hello&vtitle=values&lineMode=staircase&lineWidth=2", results: sineAndLine},
+	{name: "stacked", query: "width=400&height=200&areaMode=stacked&hideLegend=true", results: sineAndLine},
+	{name: "all-areas", query: "width=400&height=200&areaMode=all&areaAlpha=0.5&hideGrid=true", results: sineAndLine},
+	{name: "second-y-axis", query: "width=400&height=200&vtitleRight=thousands", results: secondYAxis},
+	{name: "template", query: "width=400&height=250&majorGridLineColor=red&minorY=2", template: "test-light", results: sineAndLine},
+	{name: "pixel-ratio", query: "width=300&height=150&pixelRatio=2", results: sineAndLine},
+	{name: "pie", query: "width=400&height=250&graphType=pie&title=Requests", results: topN},
+	{name: "pie-rotated", query: "width=400&height=250&graphType=pie&pieLabels=rotated&valueLabels=number&pieMode=maximum&valueLabelsColor=white", results: topN},
+	{name: "bar", query: "width=400&height=250&graphType=bar&vtitle=requests", results: topN},
+	{name: "bar-horizontal", query: "width=400&height=250&graphType=bar&barOrientation=horizontal&hideLegend=true", results: topN},
+	{name: "empty", query: "width=300&height=150", results: func() []*types.MetricData { return nil }},
+}
+
+func renderTestGraph(t *testing.T, tc graphTestCase, backend cairoBackend, createSurface surfaceFactory) []byte {
+	t.Helper()
+
+	r := httptest.NewRequest("GET", "/render?tz=UTC&"+tc.query, nil)
+	template := tc.template
+	if template == "" {
+		template = "default"
+	}
+	results := tc.results()
+	b, err := marshalGraph(GetPictureParamsWithTemplate(r, template, results), results, backend, "", createSurface)
+	if err != nil {
+		t.Fatalf("failed to render the graph: %v", err)
+	}
+
+	return b
+}
+
+func decodePNG(t *testing.T, b []byte) image.Image {
+	t.Helper()
+
+	img, err := stdpng.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to decode the PNG: %v", err)
+	}
+
+	return img
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return rgba
+}
+
+// imageDifference returns the mean absolute difference of the color channels of the images
+// downsampled to blocks of the size, in the range [0, 1]. The blocks level out the differences
+// of the antialiasing and of the glyph shapes.
+func imageDifference(a, b image.Image, block int) float64 {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return 1
+	}
+
+	ra, rb := toRGBA(a), toRGBA(b)
+	size := ra.Bounds().Size()
+	var diff float64
+	var count int
+	for y := 0; y < size.Y; y += block {
+		for x := 0; x < size.X; x += block {
+			var sa, sb [3]int
+			for dy := 0; dy < block && y+dy < size.Y; dy++ {
+				for dx := 0; dx < block && x+dx < size.X; dx++ {
+					ia := ra.PixOffset(ra.Rect.Min.X+x+dx, ra.Rect.Min.Y+y+dy)
+					ib := rb.PixOffset(rb.Rect.Min.X+x+dx, rb.Rect.Min.Y+y+dy)
+					for i := range sa {
+						sa[i] += int(ra.Pix[ia+i])
+						sb[i] += int(rb.Pix[ib+i])
+					}
+				}
+			}
+			for i := range sa {
+				d := sa[i] - sb[i]
+				if d < 0 {
+					d = -d
+				}
+				diff += float64(d) / float64(block*block) / 0xff
+			}
+			count += 3
+		}
+	}
+
+	return diff / float64(count)
+}
+
+func TestCanvasGolden(t *testing.T) {
+	for _, tc := range graphTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := renderTestGraph(t, tc, cairoPNG, newCanvasSurface)
+			golden := filepath.Join("testdata", tc.name+".png")
+
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatalf("failed to update the golden image: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read the golden image: %v", err)
+			}
+			// the images are compared pixel by pixel, the tolerance only covers the floating point differences
+			if d := imageDifference(decodePNG(t, got), decodePNG(t, want), 1); d > 0.001 {
+				t.Errorf("the image differs from %s by %f, run the tests with -update to accept the change", golden, d)
+			}
+		})
+	}
+}
+
+func TestCanvasSVG(t *testing.T) {
+	for _, tc := range graphTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := renderTestGraph(t, tc, cairoSVG, newCanvasSurface)
+
+			d := xml.NewDecoder(bytes.NewReader(got))
+			paths := 0
+			for {
+				tok, err := d.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("the SVG is malformed: %v", err)
+				}
+				if el, ok := tok.(xml.StartElement); ok && el.Name.Local == "path" {
+					paths++
+				}
+			}
+			if paths == 0 {
+				t.Error("the SVG has no paths")
+			}
+		})
+	}
+}
+
+func TestCanvasImageSize(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantWidth  int
+		wantHeight int
+		wantErr    bool
+	}{
+		{query: "width=300&height=150", wantWidth: 300, wantHeight: 150},
+		{query: "width=300&height=150&pixelRatio=1.5", wantWidth: 450, wantHeight: 225},
+		{query: "width=40000&height=150", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/render?"+tt.query, nil)
+			results := sineAndLine()
+			b, err := marshalGraph(GetPictureParams(r, results), results, cairoPNG, "", newCanvasSurface)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to render the graph: %v", err)
+			}
+
+			size := decodePNG(t, b).Bounds().Size()
+			if size.X != tt.wantWidth || size.Y != tt.wantHeight {
+				t.Errorf("got the size %v, want %dx%d", size, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestStrokeDashes(t *testing.T) {
+	sp := subpath{points: []point{{0, 0}, {10, 0}}}
+
+	lines := dashLine(sp, []float64{2, 3}, 0)
+	if len(lines) != 2 {
+		t.Fatalf("got %d dashes, want 2", len(lines))
+	}
+	if got := lines[1].points[0]; got != (point{5, 0}) {
+		t.Errorf("the second dash starts at %v, want {5 0}", got)
+	}
+
+	lines = dashLine(sp, []float64{2, 3}, 1)
+	if len(lines) != 3 || lines[0].points[1] != (point{1, 0}) {
+		t.Errorf("the offset is not applied: %v", lines)
+	}
+}
@@ -0,0 +1,103 @@
+package png
+
+// The graphs are drawn through the cairoContext interface, which mirrors the used subset of
+// the cairo API. It is implemented by cairo itself when built with the cairo tag,
+// and by the pure-Go canvas otherwise.
+
+// TextExtents is the equivalent of cairo_text_extents_t.
+type TextExtents struct {
+	XBearing float64
+	YBearing float64
+	Width    float64
+	Height   float64
+	XAdvance float64
+	YAdvance float64
+}
+
+// FontExtents is the equivalent of cairo_font_extents_t.
+type FontExtents struct {
+	Ascent      float64
+	Descent     float64
+	Height      float64
+	MaxXAdvance float64
+	MaxYAdvance float64
+}
+
+// Matrix is the equivalent of cairo_matrix_t. A point (x, y) is transformed to
+// (Xx*x + Xy*y + X0, Yx*x + Yy*y + Y0).
+type Matrix struct {
+	Xx float64
+	Yx float64
+	Xy float64
+	Yy float64
+	X0 float64
+	Y0 float64
+}
+
+type LineCap int
+
+const (
+	LineCapButt LineCap = iota
+	LineCapRound
+	LineCapSquare
+)
+
+type LineJoin int
+
+const (
+	LineJoinMiter LineJoin = iota
+	LineJoinRound
+	LineJoinBevel
+)
+
+// savedPath is a copy of the current path made by CopyPath. Its content is specific to the implementation.
+type savedPath interface{}
+
+// interface with all used cairo.Context methods
+type cairoContext interface {
+	Rectangle(x, y, width, height float64) // pixel ratio required
+	GetLineWidth() float64                 // pixel ratio required
+	LineTo(x, y float64)                   // pixel ratio required
+	MoveTo(x, y float64)                   // pixel ratio required
+	SetLineWidth(width float64)            // pixel ratio required
+	SetFontSize(size float64)              // pixel ratio required
+	Stroke()
+	SetDash(dashes []float64, offset float64)      // pixel ratio required
+	TextExtents(utf8 string, extents *TextExtents) // pixel ratio required
+	FontExtents(extents *FontExtents)              // pixel ratio required
+	Rotate(angle float64)
+	SetLineCap(lineCap LineCap)
+	SetLineJoin(lineJoin LineJoin)
+	RelMoveTo(dx, dy float64)                   // pixel ratio required
+	Arc(xc, yc, radius, angle1, angle2 float64) // pixel ratio required
+	SetSourceRGBA(red, green, blue, alpha float64)
+	SetMatrix(matrix *Matrix) // pixel ratio required
+	GetMatrix(matrix *Matrix) // pixel ratio required
+	Clip()
+	Fill()
+	ClosePath()
+	SelectFontFace(family string, slant FontSlant, weight FontWeight) // pixel ratio required
+	TextPath(utf8 string)
+	Save()
+	Restore()
+	FillPreserve()
+	AppendPath(path savedPath)
+	CopyPath() savedPath
+}
+
+type cairoSurfaceContext struct {
+	context cairoContext
+}
+
+// graphSurface is the target of the drawing. finish returns the encoded picture.
+type graphSurface interface {
+	context() *cairoSurfaceContext
+	finish() ([]byte, error)
+}
+
+func isDefaultRatio(pixelRatio float64) bool {
+	if pixelRatio > 0.9999 && pixelRatio < 1.0001 {
+		return true
+	}
+	return false
+}
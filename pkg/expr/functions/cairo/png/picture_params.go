@@ -83,6 +83,82 @@ func getPieMode(s string, def PieMode) PieMode {
 	return PieModeAverage
 }
 
+type GraphType int
+
+const (
+	GraphTypeLine GraphType = 1 << iota
+	GraphTypePie
+	GraphTypeBar
+)
+
+func getGraphType(s string, def GraphType) GraphType {
+	if s == "" {
+		return def
+	}
+	switch s {
+	case "pie":
+		return GraphTypePie
+	case "bar":
+		return GraphTypeBar
+	}
+	return GraphTypeLine
+}
+
+type PieLabels int
+
+const (
+	PieLabelsHorizontal PieLabels = 1 << iota
+	PieLabelsRotated
+)
+
+func getPieLabels(s string, def PieLabels) PieLabels {
+	if s == "" {
+		return def
+	}
+	if s == "rotated" {
+		return PieLabelsRotated
+	}
+	return PieLabelsHorizontal
+}
+
+type ValueLabels int
+
+const (
+	ValueLabelsPercent ValueLabels = 1 << iota
+	ValueLabelsNumber
+	ValueLabelsNone
+)
+
+func getValueLabels(s string, def ValueLabels) ValueLabels {
+	if s == "" {
+		return def
+	}
+	switch s {
+	case "number":
+		return ValueLabelsNumber
+	case "none":
+		return ValueLabelsNone
+	}
+	return ValueLabelsPercent
+}
+
+type BarOrientation int
+
+const (
+	BarOrientationVertical BarOrientation = 1 << iota
+	BarOrientationHorizontal
+)
+
+func getBarOrientation(s string, def BarOrientation) BarOrientation {
+	if s == "" {
+		return def
+	}
+	if s == "horizontal" {
+		return BarOrientationHorizontal
+	}
+	return BarOrientationVertical
+}
+
 func getLineMode(s string, def LineMode) LineMode {
 	if s == "" {
 		return def
@@ -168,6 +244,13 @@ type PictureParams struct {
 	LineWidth      float64
 	ColorList      []string
 
+	GraphType        GraphType
+	PieLabels        PieLabels
+	ValueLabels      ValueLabels
+	ValueLabelsMin   float64
+	ValueLabelsColor string
+	BarOrientation   BarOrientation
+
 	YMin    float64
 	YMax    float64
 	XMin    float64
@@ -252,6 +335,13 @@ func GetPictureParamsWithTemplate(r *http.Request, template string, metricData [
 		LineWidth:      getFloat64(r.FormValue("lineWidth"), t.LineWidth),
 		ColorList:      getStringArray(r.FormValue("colorList"), t.ColorList),
 
+		GraphType:        getGraphType(r.FormValue("graphType"), t.GraphType),
+		PieLabels:        getPieLabels(r.FormValue("pieLabels"), t.PieLabels),
+		ValueLabels:      getValueLabels(r.FormValue("valueLabels"), t.ValueLabels),
+		ValueLabelsMin:   getFloat64(r.FormValue("valueLabelsMin"), t.ValueLabelsMin),
+		ValueLabelsColor: getString(r.FormValue("valueLabelsColor"), t.ValueLabelsColor),
+		BarOrientation:   getBarOrientation(r.FormValue("barOrientation"), t.BarOrientation),
+
 		YMin:    getFloat64(r.FormValue("yMin"), t.YMin),
 		YMax:    getFloat64(r.FormValue("yMax"), t.YMax),
 		YStep:   getFloat64(r.FormValue("yStep"), t.YStep),
@@ -382,6 +472,13 @@ var DefaultParams = PictureParams{
 	LineWidth:      1.2,
 	ColorList:      DefaultColorList,
 
+	GraphType:        GraphTypeLine,
+	PieLabels:        PieLabelsHorizontal,
+	ValueLabels:      ValueLabelsPercent,
+	ValueLabelsMin:   5,
+	ValueLabelsColor: "black",
+	BarOrientation:   BarOrientationVertical,
+
 	YMin:    math.NaN(),
 	YMax:    math.NaN(),
 	YStep:   math.NaN(),
@@ -455,6 +552,13 @@ var templates = map[string]PictureParams{
 		LineWidth:      1.2,
 		ColorList:      DefaultColorList,
 
+		GraphType:        GraphTypeLine,
+		PieLabels:        PieLabelsHorizontal,
+		ValueLabels:      ValueLabelsPercent,
+		ValueLabelsMin:   5,
+		ValueLabelsColor: "black",
+		BarOrientation:   BarOrientationVertical,
+
 		YMin:    math.NaN(),
 		YMax:    math.NaN(),
 		YStep:   math.NaN(),
@@ -0,0 +1,185 @@
+package png
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+)
+
+// The pie and the bar graphs summarize each series by a single value, selected by pieMode.
+
+type pieSlice struct {
+	name     string
+	value    float64
+	color    string
+	midAngle float64
+}
+
+// summarizeSeries returns the average, the maximum or the minimum of the present values of the series.
+// It returns false if the series has no values.
+func summarizeSeries(mode PieMode, r *types.MetricData) (float64, bool) {
+	var sum float64
+	var count int
+	value := math.NaN()
+	for i, v := range r.Values {
+		if r.IsAbsent[i] || math.IsNaN(v) {
+			continue
+		}
+		count++
+		sum += v
+		switch {
+		case math.IsNaN(value):
+			value = v
+		case mode == PieModeMaximum && v > value:
+			value = v
+		case mode == PieModeMinimum && v < value:
+			value = v
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	if mode == PieModeAverage {
+		value = sum / float64(count)
+	}
+
+	return value, true
+}
+
+// setSeriesColors assigns the colors of the color list to the series without a color.
+func setSeriesColors(params *Params, results []*types.MetricData) {
+	var colorsCur int
+	for _, res := range results {
+		if res.Color != "" {
+			continue
+		}
+		res.Color = params.colorList[colorsCur]
+		colorsCur++
+		if colorsCur >= len(params.colorList) {
+			colorsCur = 0
+		}
+	}
+}
+
+// drawTitles draws the title and the left vertical title, and moves the graph area accordingly.
+func drawTitles(cr *cairoSurfaceContext, params *Params, withVTitle bool) {
+	if params.title == "" && (!withVTitle || params.vtitle == "") {
+		return
+	}
+
+	titleSize := params.fontSize + math.Floor(math.Log(params.fontSize))
+	setColor(cr, params.fgColor)
+	setFont(cr, params, titleSize)
+
+	if params.title != "" {
+		drawTitle(cr, params)
+	}
+	if withVTitle && params.vtitle != "" {
+		drawVTitle(cr, params, params.vtitle, false)
+	}
+}
+
+func drawPie(cr *cairoSurfaceContext, params *Params, results []*types.MetricData, emptyText string) error {
+	setSeriesColors(params, results)
+
+	var slices []pieSlice
+	var total float64
+	for _, res := range results {
+		value, ok := summarizeSeries(params.pieMode, res)
+		// negative slices can't be drawn
+		if !ok || value <= 0 || math.IsInf(value, 0) {
+			continue
+		}
+		slices = append(slices, pieSlice{name: res.Name, value: value, color: res.Color})
+		total += value
+	}
+
+	if len(slices) == 0 {
+		drawNoData(cr, params, emptyText)
+		return nil
+	}
+
+	if params.graphOnly {
+		params.hideLegend = true
+		params.area.xmin = 0
+		params.area.xmax = params.width
+		params.area.ymin = 0
+		params.area.ymax = params.height
+	} else {
+		drawTitles(cr, params, false)
+	}
+
+	setFont(cr, params, params.fontSize)
+	if !params.hideLegend {
+		drawLegend(cr, params, results)
+	}
+
+	halfX := (params.area.xmax - params.area.xmin) / 2
+	halfY := (params.area.ymax - params.area.ymin) / 2
+	centerX := params.area.xmin + halfX
+	centerY := params.area.ymin + halfY
+	radius := math.Min(halfX, halfY) * 0.95
+
+	// the slices start at the top and go clockwise
+	theta := 3 * math.Pi / 2
+	for i := range slices {
+		s := &slices[i]
+		phi := theta + 2*math.Pi*s.value/total
+		s.midAngle = (theta + phi) / 2
+
+		setColor(cr, string2RGBA(s.color))
+		cr.context.MoveTo(centerX, centerY)
+		cr.context.Arc(centerX, centerY, radius, theta, phi)
+		cr.context.LineTo(centerX, centerY)
+		cr.context.ClosePath()
+		cr.context.Fill()
+
+		theta = phi
+	}
+
+	if params.valueLabels == ValueLabelsNone {
+		return nil
+	}
+
+	setColor(cr, string2RGBA(params.valueLabelsColor))
+	for _, s := range slices {
+		var label string
+		if params.valueLabels == ValueLabelsPercent {
+			percent := 100 * s.value / total
+			if percent < params.valueLabelsMin {
+				continue
+			}
+			label = strconv.Itoa(int(math.Round(percent))) + "%"
+		} else {
+			if s.value < params.valueLabelsMin {
+				continue
+			}
+			label = formatValueLabel(s.value)
+		}
+
+		angle := math.Mod(s.midAngle, 2*math.Pi)
+		x := centerX + radius/2*math.Cos(angle)
+		y := centerY + radius/2*math.Sin(angle)
+		if params.pieLabels == PieLabelsRotated {
+			// keep the labels on the left half readable
+			if angle > math.Pi/2 && angle <= 3*math.Pi/2 {
+				angle -= math.Pi
+			}
+			drawText(cr, label, x, y, HAlignCenter, VAlignCenter, angle*180/math.Pi)
+		} else {
+			drawText(cr, label, x, y, HAlignCenter, VAlignCenter, 0)
+		}
+	}
+
+	return nil
+}
+
+// formatValueLabel formats the value as graphite does for the pie labels.
+func formatValueLabel(v float64) string {
+	if v < 10 && v != math.Trunc(v) {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+
+	return strconv.FormatInt(int64(v), 10)
+}
@@ -0,0 +1,50 @@
+package png
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+)
+
+func TestSummarizeSeries(t *testing.T) {
+	series := types.MakeMetricData("metric", []float64{1, math.NaN(), 5, 3}, 60, 0)
+	empty := types.MakeMetricData("metric", []float64{math.NaN(), math.NaN()}, 60, 0)
+
+	tests := []struct {
+		mode   PieMode
+		series *types.MetricData
+		want   float64
+		wantOk bool
+	}{
+		{mode: PieModeAverage, series: series, want: 3, wantOk: true},
+		{mode: PieModeMaximum, series: series, want: 5, wantOk: true},
+		{mode: PieModeMinimum, series: series, want: 1, wantOk: true},
+		{mode: PieModeAverage, series: empty, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := summarizeSeries(tt.mode, tt.series)
+		if ok != tt.wantOk {
+			t.Errorf("mode %d: got ok %v, want %v", tt.mode, ok, tt.wantOk)
+			continue
+		}
+		if ok && got != tt.want {
+			t.Errorf("mode %d: got %f, want %f", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestFormatValueLabel(t *testing.T) {
+	tests := map[float64]string{
+		3.14159: "3.14",
+		5:       "5",
+		123.7:   "123",
+	}
+
+	for v, want := range tests {
+		if got := formatValueLabel(v); got != want {
+			t.Errorf("formatValueLabel(%f) = %q, want %q", v, got, want)
+		}
+	}
+}
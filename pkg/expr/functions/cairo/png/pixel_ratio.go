@@ -10,54 +10,11 @@ import (
 	"github.com/evmar/gocairo/cairo"
 )
 
-// interface with all used cairo.Context methods
-type cairoContext interface {
-	Rectangle(x, y, width, height float64) // pixel ratio required
-	GetLineWidth() float64                 // pixel ratio required
-	LineTo(x, y float64)                   // pixel ratio required
-	MoveTo(x, y float64)                   // pixel ratio required
-	SetLineWidth(width float64)            // pixel ratio required
-	SetFontSize(size float64)              // pixel ratio required
-	SetFontOptions(options *cairo.FontOptions)
-	Stroke()
-	SetDash(dashes []float64, offset float64)            // pixel ratio required
-	TextExtents(utf8 string, extents *cairo.TextExtents) // pixel ratio required
-	FontExtents(extents *cairo.FontExtents)              // pixel ratio required
-	Rotate(angle float64)
-	SetLineCap(lineCap cairo.LineCap)
-	SetLineJoin(lineJoin cairo.LineJoin)
-	RelMoveTo(dx, dy float64) // pixel ratio required
-	SetSourceRGBA(red, green, blue, alpha float64)
-	SetMatrix(matrix *cairo.Matrix) // pixel ratio required
-	GetMatrix(matrix *cairo.Matrix) // pixel ratio required
-	Clip()
-	Fill()
-	ClosePath()
-	SelectFontFace(family string, slant cairo.FontSlant, weight cairo.FontWeight) // pixel ratio required
-	TextPath(utf8 string)
-	Save()
-	Restore()
-	FillPreserve()
-	AppendPath(path *cairo.Path)
-	CopyPath() *cairo.Path
-}
-
 type pixelRatioContext struct {
-	*cairo.Context
+	cairoGraphContext
 	pr float64 // pixel ratio
 }
 
-type cairoSurfaceContext struct {
-	context cairoContext
-}
-
-func isDefaultRatio(pixelRatio float64) bool {
-	if pixelRatio > 0.9999 && pixelRatio < 1.0001 {
-		return true
-	}
-	return false
-}
-
 func svgSurfaceCreate(filename string, widthInPoints, heightInPoints float64, pixelRatio float64) *cairo.SVGSurface {
 	if isDefaultRatio(pixelRatio) {
 		return cairo.SVGSurfaceCreate(filename, widthInPoints, heightInPoints)
@@ -84,14 +41,22 @@ func imageSurfaceCreate(format cairo.Format, width, height float64, pixelRatio f
 }
 
 func createContext(surface *cairo.Surface, pixelRatio float64) *cairoSurfaceContext {
+	cr := cairo.Create(surface)
+
+	// Setting font parameters
+
+	fontOpts := cairo.FontOptionsCreate()
+	fontOpts.SetAntialias(cairo.AntialiasNone)
+	cr.SetFontOptions(fontOpts)
+
 	if isDefaultRatio(pixelRatio) {
-		return &cairoSurfaceContext{context: cairo.Create(surface)}
+		return &cairoSurfaceContext{context: cairoGraphContext{cr}}
 	}
 
 	return &cairoSurfaceContext{
 		context: &pixelRatioContext{
-			Context: cairo.Create(surface),
-			pr:      pixelRatio,
+			cairoGraphContext: cairoGraphContext{cr},
+			pr:                pixelRatio,
 		},
 	}
 }
@@ -128,9 +93,9 @@ func (c *pixelRatioContext) SetDash(dashes []float64, offset float64) {
 	c.Context.SetDash(dr, offset*c.pr)
 }
 
-func (c *pixelRatioContext) TextExtents(utf8 string, extents *cairo.TextExtents) {
-	var e cairo.TextExtents
-	c.Context.TextExtents(utf8, &e)
+func (c *pixelRatioContext) TextExtents(utf8 string, extents *TextExtents) {
+	var e TextExtents
+	c.cairoGraphContext.TextExtents(utf8, &e)
 	extents.XBearing = e.XBearing / c.pr
 	extents.YBearing = e.YBearing / c.pr
 	extents.Width = e.Width / c.pr
@@ -139,9 +104,9 @@ func (c *pixelRatioContext) TextExtents(utf8 string, extents *cairo.TextExtents)
 	extents.YAdvance = e.YAdvance / c.pr
 }
 
-func (c *pixelRatioContext) FontExtents(extents *cairo.FontExtents) {
-	var e cairo.FontExtents
-	c.Context.FontExtents(&e)
+func (c *pixelRatioContext) FontExtents(extents *FontExtents) {
+	var e FontExtents
+	c.cairoGraphContext.FontExtents(&e)
 	extents.Ascent = e.Ascent / c.pr
 	extents.Descent = e.Descent / c.pr
 	extents.Height = e.Height / c.pr
@@ -153,20 +118,24 @@ func (c *pixelRatioContext) RelMoveTo(dx, dy float64) {
 	c.Context.RelMoveTo(c.pr*dx, c.pr*dy)
 }
 
-func (c *pixelRatioContext) SetMatrix(matrix *cairo.Matrix) {
-	var m cairo.Matrix
+func (c *pixelRatioContext) Arc(xc, yc, radius, angle1, angle2 float64) {
+	c.Context.Arc(c.pr*xc, c.pr*yc, c.pr*radius, angle1, angle2)
+}
+
+func (c *pixelRatioContext) SetMatrix(matrix *Matrix) {
+	var m Matrix
 	m.Xx = matrix.Xx * c.pr
 	m.Yx = matrix.Yx * c.pr
 	m.Xy = matrix.Xy * c.pr
 	m.Yy = matrix.Yy * c.pr
 	m.X0 = matrix.X0 * c.pr
 	m.Y0 = matrix.Y0 * c.pr
-	c.Context.SetMatrix(&m)
+	c.cairoGraphContext.SetMatrix(&m)
 }
 
-func (c *pixelRatioContext) GetMatrix(matrix *cairo.Matrix) {
-	var m cairo.Matrix
-	c.Context.GetMatrix(&m)
+func (c *pixelRatioContext) GetMatrix(matrix *Matrix) {
+	var m Matrix
+	c.cairoGraphContext.GetMatrix(&m)
 	matrix.Xx = m.Xx / c.pr
 	matrix.Yx = m.Yx / c.pr
 	matrix.Xy = m.Xy / c.pr
@@ -175,6 +144,6 @@ func (c *pixelRatioContext) GetMatrix(matrix *cairo.Matrix) {
 	matrix.Y0 = m.Y0 / c.pr
 }
 
-func (c *pixelRatioContext) SelectFontFace(family string, slant cairo.FontSlant, weight cairo.FontWeight) {
-	c.Context.SelectFontFace(family, slant, cairo.FontWeight(c.pr*float64(weight)))
+func (c *pixelRatioContext) SelectFontFace(family string, slant FontSlant, weight FontWeight) {
+	c.cairoGraphContext.SelectFontFace(family, slant, FontWeight(c.pr*float64(weight)))
 }
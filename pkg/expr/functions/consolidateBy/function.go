@@ -2,6 +2,7 @@ package consolidateBy
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bookingcom/carbonapi/pkg/expr/helper"
 	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
@@ -38,30 +39,16 @@ func (f *consolidateBy) Do(ctx context.Context, e parser.Expr, from, until int32
 		return nil, err
 	}
 
+	aggFunc, ok := types.GetAggregateFunction(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported consolidation function %v", parser.ErrInvalidArgumentValue, name)
+	}
+
 	var results []*types.MetricData
 
 	for _, a := range arg {
 		r := *a
-
-		var f func([]float64, []bool) (float64, bool)
-
-		switch name {
-		case "max":
-			f = types.AggMax
-		case "min":
-			f = types.AggMin
-		case "sum":
-			f = types.AggSum
-		case "average":
-			f = types.AggMean
-		case "first":
-			f = types.AggFirst
-		case "last":
-			f = types.AggLast
-		}
-
-		r.AggregateFunction = f
-
+		r.AggregateFunction = aggFunc
 		results = append(results, &r)
 	}
 
@@ -84,15 +71,8 @@ func (f *consolidateBy) Description() map[string]types.FunctionDescription {
 					Type:     types.SeriesList,
 				},
 				{
-					Name: "consolidationFunc",
-					Options: []string{
-						"max",
-						"min",
-						"sum",
-						"average",
-						"first",
-						"last",
-					},
+					Name:     "consolidationFunc",
+					Options:  types.AggregateFunctionNames(),
 					Required: true,
 					Type:     types.String,
 				},
@@ -31,7 +31,7 @@ func TestConstantLine(t *testing.T) {
 		{
 			"constantLine(42.42)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"42.42", 0, 1}: {types.MakeMetricData("constantLine", []float64{12.3, 12.3}, 1, now32)},
+				{"42.42", 0, 1, 0}: {types.MakeMetricData("constantLine", []float64{12.3, 12.3}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("42.42",
 				[]float64{42.42, 42.42, 42.42}, 1, now32)},
@@ -32,14 +32,14 @@ func TestDivideSeriesMultiReturn(t *testing.T) {
 		{
 			"divideSeries(metric[12],metric2)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric[12]", 0, 1}: {
+				{"metric[12]", 0, 1, 0}: {
 					types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 5}, 1, now32),
 					types.MakeMetricData("metric2", []float64{2, 4, 6, 8, 10}, 1, now32),
 				},
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 5}, 1, now32),
 				},
-				{"metric2", 0, 1}: {
+				{"metric2", 0, 1, 0}: {
 					types.MakeMetricData("metric2", []float64{2, 4, 6, 8, 10}, 1, now32),
 				},
 			},
@@ -68,8 +68,8 @@ func TestDivideSeries(t *testing.T) {
 		{
 			"divideSeries(metric1,metric2)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, now32)},
-				{"metric2", 0, 1}: {types.MakeMetricData("metric2", []float64{2, math.NaN(), 3, math.NaN(), 0, 6}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, now32)},
+				{"metric2", 0, 1, 0}: {types.MakeMetricData("metric2", []float64{2, math.NaN(), 3, math.NaN(), 0, 6}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("divideSeries(metric1,metric2)",
 				[]float64{0.5, math.NaN(), math.NaN(), math.NaN(), math.NaN(), 2}, 1, now32)},
@@ -77,7 +77,7 @@ func TestDivideSeries(t *testing.T) {
 		{
 			"divideSeries(metric[12])",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric[12]", 0, 1}: {
+				{"metric[12]", 0, 1, 0}: {
 					types.MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, now32),
 					types.MakeMetricData("metric2", []float64{2, math.NaN(), 3, math.NaN(), 0, 6}, 1, now32),
 				},
@@ -88,7 +88,7 @@ func TestDivideSeries(t *testing.T) {
 		{
 			"divideSeries(different_length_metric[12])",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"different_length_metric[12]", 0, 1}: {
+				{"different_length_metric[12]", 0, 1, 0}: {
 					types.MakeMetricData("different_length_metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 5, 6, 7, 8}, 1, now32),
 					types.MakeMetricData("different_length_metric2", []float64{2, math.NaN(), 3, math.NaN(), 2}, 2, now32),
 				},
@@ -99,7 +99,7 @@ func TestDivideSeries(t *testing.T) {
 		{
 			"divideSeries(different_length_metric[12])",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"different_length_metric[12]", 0, 1}: {
+				{"different_length_metric[12]", 0, 1, 0}: {
 					types.MakeMetricData("different_length_metric1", []float64{1, math.NaN(), 2, 3, 4}, 1, now32),
 					types.MakeMetricData("different_length_metric2", []float64{1, math.NaN(), 2}, 1, now32),
 				},
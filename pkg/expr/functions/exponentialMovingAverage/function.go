@@ -0,0 +1,123 @@
+package exponentialMovingAverage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type exponentialMovingAverage struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &exponentialMovingAverage{}
+	functions := []string{"exponentialMovingAverage"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// exponentialMovingAverage(seriesList, windowSize)
+func (f *exponentialMovingAverage) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	window, err := helper.GetWindowArg(e, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	args, bootstrap, err := window.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*types.MetricData, 0, len(args))
+	for _, a := range args {
+		points := window.Points(a.StepTime)
+		if points <= 0 {
+			return nil, fmt.Errorf("%w: the window %s is shorter than the step of %s", parser.ErrInvalidArgumentValue, window, a.Name)
+		}
+		constant := 2 / float64(points+1)
+
+		offset := int(bootstrap / a.StepTime)
+		if offset > len(a.Values) {
+			offset = len(a.Values)
+		}
+
+		// the average of the bootstrapped window is the initial value
+		start := offset - points
+		if start < 0 {
+			start = 0
+		}
+		ema, noEMA := types.AggMean(a.Values[start:offset], a.IsAbsent[start:offset])
+
+		r := *a
+		r.Name = fmt.Sprintf("exponentialMovingAverage(%s,%s)", a.Name, window)
+		r.Values = make([]float64, len(a.Values)-offset)
+		r.IsAbsent = make([]bool, len(a.Values)-offset)
+		r.StartTime = a.StartTime + int32(offset)*a.StepTime
+
+		for i := offset; i < len(a.Values); i++ {
+			ridx := i - offset
+			if a.IsAbsent[i] {
+				r.IsAbsent[ridx] = true
+				continue
+			}
+
+			if noEMA {
+				ema, noEMA = a.Values[i], false
+			} else {
+				ema = constant*a.Values[i] + (1-constant)*ema
+			}
+			r.Values[ridx] = ema
+		}
+
+		results = append(results, &r)
+	}
+
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *exponentialMovingAverage) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"exponentialMovingAverage": {
+			Description: "Takes a series of values and a window size and produces an exponential moving\naverage utilizing the following formula:\n\n.. code-block:: none\n\n  ema(current) = constant * (Current Value) + (1 - constant) * ema(previous)\n\nThe Constant is calculated as:\n\n.. code-block:: none\n\n  constant = 2 / (windowSize + 1)\n\nThe first period EMA uses a simple moving average for its value.\n\nExample:\n\n.. code-block:: none\n\n  &target=exponentialMovingAverage(*.transactions.count, 10)\n  &target=exponentialMovingAverage(*.transactions.count, '-10s')",
+			Function:    "exponentialMovingAverage(seriesList, windowSize)",
+			Group:       "Calculate",
+			Module:      "graphite.render.functions",
+			Name:        "exponentialMovingAverage",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "windowSize",
+					Required: true,
+					Suggestions: types.NewSuggestions(
+						5,
+						7,
+						10,
+						"1min",
+						"5min",
+						"10min",
+						"30min",
+						"1hour",
+					),
+					Type: types.IntOrInterval,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package exponentialMovingAverage
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestExponentialMovingAverage(t *testing.T) {
+	var from, until int32 = 1000, 1010
+
+	tests := []struct {
+		target string
+		m      map[parser.MetricRequest][]*types.MetricData
+		want   *types.MetricData
+	}{
+		{
+			"exponentialMovingAverage(metric1,3)",
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "metric1", From: from, Until: until}:     {types.MakeMetricData("metric1", []float64{4, 5, 6, math.NaN(), 8}, 2, from)},
+				{Metric: "metric1", From: from - 6, Until: until}: {types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 5, 6, math.NaN(), 8}, 2, from-6)},
+			},
+			types.MakeMetricData("exponentialMovingAverage(metric1,3)", []float64{3, 4, 5, math.NaN(), 6.5}, 2, from),
+		},
+		{
+			"exponentialMovingAverage(metric1,'6s')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "metric1", From: from - 6, Until: until}: {types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 5, 6, math.NaN(), 8}, 2, from-6)},
+			},
+			types.MakeMetricData(`exponentialMovingAverage(metric1,"6s")`, []float64{3, 4, 5, math.NaN(), 6.5}, 2, from),
+		},
+		{
+			// the sign of an interval is ignored
+			"exponentialMovingAverage(metric1,'-6s')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "metric1", From: from - 6, Until: until}: {types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 5, 6, math.NaN(), 8}, 2, from-6)},
+			},
+			types.MakeMetricData(`exponentialMovingAverage(metric1,"-6s")`, []float64{3, 4, 5, math.NaN(), 6.5}, 2, from),
+		},
+		{
+			// without any history the first point starts the average
+			"exponentialMovingAverage(metric1,'6s')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "metric1", From: from - 6, Until: until}: {types.MakeMetricData("metric1", []float64{math.NaN(), math.NaN(), math.NaN(), 4, 5, 6, math.NaN(), 8}, 2, from-6)},
+			},
+			types.MakeMetricData(`exponentialMovingAverage(metric1,"6s")`, []float64{4, 4.5, 5.25, math.NaN(), 6.625}, 2, from),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.target, func(t *testing.T) {
+			exp, _, err := parser.ParseExpr(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, from, until, tt.m, th.NoopGetTargetData)
+			if err != nil {
+				t.Fatalf("failed to eval %s: %+v", tt.target, err)
+			}
+			if len(g) != 1 {
+				t.Fatalf("expected one series, got %d", len(g))
+			}
+			if g[0].Name != tt.want.Name {
+				t.Errorf("got the name %s, want %s", g[0].Name, tt.want.Name)
+			}
+			if g[0].StartTime != tt.want.StartTime || g[0].StepTime != tt.want.StepTime {
+				t.Errorf("got start %d and step %d, want %d and %d", g[0].StartTime, g[0].StepTime, tt.want.StartTime, tt.want.StepTime)
+			}
+			if !th.NearlyEqualMetrics(g[0], tt.want) {
+				t.Errorf("got %v, want %v", g[0].Values, tt.want.Values)
+			}
+		})
+	}
+}
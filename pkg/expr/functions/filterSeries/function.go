@@ -39,20 +39,8 @@ func (f *filterSeries) Do(ctx context.Context, e parser.Expr, from, until int32,
 		return nil, err
 	}
 
-	var callbackFunc func([]float64, []bool) (float64, bool)
-	switch callback {
-	case "max":
-		callbackFunc = types.AggMax
-	case "min":
-		callbackFunc = types.AggMin
-	case "sum":
-		callbackFunc = types.AggSum
-	case "average":
-		callbackFunc = types.AggMean
-	case "last":
-		callbackFunc = types.AggLast
-	// TODO: this implementation does not support diff, median, multiply, range, stddev
-	default:
+	callbackFunc, ok := types.GetAggregateFunction(callback)
+	if !ok {
 		return nil, fmt.Errorf("%w: unsupported consolidation function %v", parser.ErrInvalidArgumentValue, callback)
 	}
 
@@ -70,7 +58,7 @@ func (f *filterSeries) Do(ctx context.Context, e parser.Expr, from, until int32,
 		return nil, err
 	}
 
-	_, ok := operators[operator]
+	_, ok = operators[operator]
 	if !ok {
 		return nil, fmt.Errorf("%w: unsupported operator %v", parser.ErrInvalidArgumentValue, operator)
 	}
@@ -139,14 +127,8 @@ func (f *filterSeries) Description() map[string]types.FunctionDescription {
 				{
 					Name:     "func",
 					Required: true,
-					Options: []string{
-						"average",
-						"last",
-						"max",
-						"min",
-						"sum",
-					},
-					Type: types.AggFunc,
+					Options:  types.AggregateFunctionNames(),
+					Type:     types.AggFunc,
 				},
 				{
 					Name:     "operator",
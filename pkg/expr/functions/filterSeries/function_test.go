@@ -30,7 +30,7 @@ func TestFilterSeriesMultiReturn(t *testing.T) {
 		{
 			"filterSeries(metric1.foo.*.baz,\"max\", \">\",20)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.baz", 0, 1}: {
+				{"metric1.foo.*.baz", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar1.baz", []float64{15, 22, 13, 24, 15}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar2.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
 				},
@@ -43,7 +43,7 @@ func TestFilterSeriesMultiReturn(t *testing.T) {
 		{
 			"filterSeries(metric1.foo.*.baz,\"min\", \"<\",13)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.baz", 0, 1}: {
+				{"metric1.foo.*.baz", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar3.baz", []float64{15, 22, 13, 24, 15}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar4.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
 				},
@@ -56,7 +56,7 @@ func TestFilterSeriesMultiReturn(t *testing.T) {
 		{
 			"filterSeries(metric1.foo.*.baz,\"average\", \"<\",14)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.baz", 0, 1}: {
+				{"metric1.foo.*.baz", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar5.baz", []float64{15, 22, 13, 24, 15}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar6.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
 				},
@@ -69,7 +69,7 @@ func TestFilterSeriesMultiReturn(t *testing.T) {
 		{
 			"filterSeries(metric1.foo.*.baz,\"last\", \"=\",15)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.baz", 0, 1}: {
+				{"metric1.foo.*.baz", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar5.baz", []float64{15, 22, 13, 24, 15}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar6.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
 				},
@@ -83,7 +83,7 @@ func TestFilterSeriesMultiReturn(t *testing.T) {
 		{
 			"filterSeries(metric1.foo.*.baz,\"sum\", \"=\",89)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.baz", 0, 1}: {
+				{"metric1.foo.*.baz", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar5.baz", []float64{15, 22, 13, 24, 15}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar6.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
 				},
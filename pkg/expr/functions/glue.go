@@ -7,12 +7,18 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/absolute"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/aggregate"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/aggregateLine"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/aggregateWithWildcards"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/alias"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/aliasByMetric"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/aliasByNode"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/aliasByTags"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/aliasQuery"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/aliasSub"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/applyByNode"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/asPercent"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/averageOutsidePercentile"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/averageSeries"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/averageSeriesWithWildcards"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/below"
@@ -29,20 +35,24 @@ import (
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/divideSeries"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/ewma"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/exclude"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/exponentialMovingAverage"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/fallbackSeries"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/fft"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/filterSeries"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/grep"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/group"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/groupByNode"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/groupByTags"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/highest"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/hitcount"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/holtWintersAberration"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/holtWintersConfidenceBands"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/holtWintersForecast"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/identity"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/ifft"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/integral"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/integralByInterval"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/interpolate"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/invert"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/isNotNull"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/keepLastValue"
@@ -59,6 +69,7 @@ import (
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/mostDeviant"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/moving"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/movingMedian"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/movingWindow"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/multiplySeries"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/multiplySeriesWithWildcards"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/nPercentile"
@@ -71,14 +82,21 @@ import (
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/percentileOfSeries"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/polyfit"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/pow"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/powSeries"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/randomWalk"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/rangeOfSeries"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/reduce"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/removeBelowSeries"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/removeBetweenPercentile"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/removeEmptySeries"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/round"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/scale"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/scaleToSeconds"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/seriesByTag"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/seriesList"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/setXFilesFactor"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/sinFunction"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/smartSummarize"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/sortBy"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/sortByName"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/squareRoot"
@@ -91,9 +109,12 @@ import (
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/timeFunction"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/timeLag"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/timeShift"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/timeSlice"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/timeStack"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/transformNull"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/tukey"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/unique"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/useSeriesAbove"
 	"github.com/bookingcom/carbonapi/pkg/expr/functions/weightedAverage"
 	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
 	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
@@ -103,25 +124,40 @@ type initFunc struct {
 	name  string
 	order interfaces.Order
 	f     func(configFile string) []interfaces.FunctionMetadata
+	// configured is used instead of f by the functions that reject an invalid config
+	configured func(configFile string) ([]interfaces.FunctionMetadata, error)
 }
 
-func New(configs map[string]string, logger *zap.Logger) {
+// New registers the functions, configured by the files of configs keyed by their lowercase names.
+// It returns the error of the first function with an invalid config.
+func New(configs map[string]string, logger *zap.Logger) error {
 	funcs := []initFunc{}
 
 	funcs = append(funcs, initFunc{name: "absolute", order: absolute.GetOrder(), f: absolute.New})
 
+	funcs = append(funcs, initFunc{name: "aggregate", order: aggregate.GetOrder(), f: aggregate.New})
+
+	funcs = append(funcs, initFunc{name: "aggregateLine", order: aggregateLine.GetOrder(), f: aggregateLine.New})
+
+	funcs = append(funcs, initFunc{name: "aggregateWithWildcards", order: aggregateWithWildcards.GetOrder(), f: aggregateWithWildcards.New})
+
 	funcs = append(funcs, initFunc{name: "alias", order: alias.GetOrder(), f: alias.New})
 
 	funcs = append(funcs, initFunc{name: "aliasByMetric", order: aliasByMetric.GetOrder(), f: aliasByMetric.New})
 
 	funcs = append(funcs, initFunc{name: "aliasByNode", order: aliasByNode.GetOrder(), f: aliasByNode.New})
 
+	funcs = append(funcs, initFunc{name: "aliasByTags", order: aliasByTags.GetOrder(), f: aliasByTags.New})
+
+	funcs = append(funcs, initFunc{name: "aliasQuery", order: aliasQuery.GetOrder(), configured: aliasQuery.New})
 	funcs = append(funcs, initFunc{name: "aliasSub", order: aliasSub.GetOrder(), f: aliasSub.New})
 
 	funcs = append(funcs, initFunc{name: "applyByNode", order: applyByNode.GetOrder(), f: applyByNode.New})
 
 	funcs = append(funcs, initFunc{name: "asPercent", order: asPercent.GetOrder(), f: asPercent.New})
 
+	funcs = append(funcs, initFunc{name: "averageOutsidePercentile", order: averageOutsidePercentile.GetOrder(), f: averageOutsidePercentile.New})
+
 	funcs = append(funcs, initFunc{name: "averageSeries", order: averageSeries.GetOrder(), f: averageSeries.New})
 
 	funcs = append(funcs, initFunc{name: "averageSeriesWithWildcards", order: averageSeriesWithWildcards.GetOrder(), f: averageSeriesWithWildcards.New})
@@ -154,6 +190,8 @@ func New(configs map[string]string, logger *zap.Logger) {
 
 	funcs = append(funcs, initFunc{name: "exclude", order: exclude.GetOrder(), f: exclude.New})
 
+	funcs = append(funcs, initFunc{name: "exponentialMovingAverage", order: exponentialMovingAverage.GetOrder(), f: exponentialMovingAverage.New})
+
 	funcs = append(funcs, initFunc{name: "fallbackSeries", order: fallbackSeries.GetOrder(), f: fallbackSeries.New})
 
 	funcs = append(funcs, initFunc{name: "fft", order: fft.GetOrder(), f: fft.New})
@@ -165,6 +203,7 @@ func New(configs map[string]string, logger *zap.Logger) {
 	funcs = append(funcs, initFunc{name: "group", order: group.GetOrder(), f: group.New})
 
 	funcs = append(funcs, initFunc{name: "groupByNode", order: groupByNode.GetOrder(), f: groupByNode.New})
+	funcs = append(funcs, initFunc{name: "groupByTags", order: groupByTags.GetOrder(), f: groupByTags.New})
 
 	funcs = append(funcs, initFunc{name: "highest", order: highest.GetOrder(), f: highest.New})
 
@@ -176,12 +215,16 @@ func New(configs map[string]string, logger *zap.Logger) {
 
 	funcs = append(funcs, initFunc{name: "holtWintersForecast", order: holtWintersForecast.GetOrder(), f: holtWintersForecast.New})
 
+	funcs = append(funcs, initFunc{name: "identity", order: identity.GetOrder(), f: identity.New})
+
 	funcs = append(funcs, initFunc{name: "ifft", order: ifft.GetOrder(), f: ifft.New})
 
 	funcs = append(funcs, initFunc{name: "integral", order: integral.GetOrder(), f: integral.New})
 
 	funcs = append(funcs, initFunc{name: "integralByInterval", order: integralByInterval.GetOrder(), f: integralByInterval.New})
 
+	funcs = append(funcs, initFunc{name: "interpolate", order: interpolate.GetOrder(), f: interpolate.New})
+
 	funcs = append(funcs, initFunc{name: "invert", order: invert.GetOrder(), f: invert.New})
 
 	funcs = append(funcs, initFunc{name: "isNotNull", order: isNotNull.GetOrder(), f: isNotNull.New})
@@ -214,6 +257,8 @@ func New(configs map[string]string, logger *zap.Logger) {
 
 	funcs = append(funcs, initFunc{name: "movingMedian", order: movingMedian.GetOrder(), f: movingMedian.New})
 
+	funcs = append(funcs, initFunc{name: "movingWindow", order: movingWindow.GetOrder(), f: movingWindow.New})
+
 	funcs = append(funcs, initFunc{name: "multiplySeries", order: multiplySeries.GetOrder(), f: multiplySeries.New})
 
 	funcs = append(funcs, initFunc{name: "multiplySeriesWithWildcards", order: multiplySeriesWithWildcards.GetOrder(), f: multiplySeriesWithWildcards.New})
@@ -238,6 +283,8 @@ func New(configs map[string]string, logger *zap.Logger) {
 
 	funcs = append(funcs, initFunc{name: "pow", order: pow.GetOrder(), f: pow.New})
 
+	funcs = append(funcs, initFunc{name: "powSeries", order: powSeries.GetOrder(), f: powSeries.New})
+
 	funcs = append(funcs, initFunc{name: "randomWalk", order: randomWalk.GetOrder(), f: randomWalk.New})
 
 	funcs = append(funcs, initFunc{name: "rangeOfSeries", order: rangeOfSeries.GetOrder(), f: rangeOfSeries.New})
@@ -246,14 +293,25 @@ func New(configs map[string]string, logger *zap.Logger) {
 
 	funcs = append(funcs, initFunc{name: "removeBelowSeries", order: removeBelowSeries.GetOrder(), f: removeBelowSeries.New})
 
+	funcs = append(funcs, initFunc{name: "removeBetweenPercentile", order: removeBetweenPercentile.GetOrder(), f: removeBetweenPercentile.New})
+
 	funcs = append(funcs, initFunc{name: "removeEmptySeries", order: removeEmptySeries.GetOrder(), f: removeEmptySeries.New})
 
+	funcs = append(funcs, initFunc{name: "round", order: round.GetOrder(), f: round.New})
+
 	funcs = append(funcs, initFunc{name: "scale", order: scale.GetOrder(), f: scale.New})
 
 	funcs = append(funcs, initFunc{name: "scaleToSeconds", order: scaleToSeconds.GetOrder(), f: scaleToSeconds.New})
 
+	funcs = append(funcs, initFunc{name: "seriesByTag", order: seriesByTag.GetOrder(), f: seriesByTag.New})
 	funcs = append(funcs, initFunc{name: "seriesList", order: seriesList.GetOrder(), f: seriesList.New})
 
+	funcs = append(funcs, initFunc{name: "setXFilesFactor", order: setXFilesFactor.GetOrder(), f: setXFilesFactor.New})
+
+	funcs = append(funcs, initFunc{name: "sinFunction", order: sinFunction.GetOrder(), f: sinFunction.New})
+
+	funcs = append(funcs, initFunc{name: "smartSummarize", order: smartSummarize.GetOrder(), f: smartSummarize.New})
+
 	funcs = append(funcs, initFunc{name: "sortBy", order: sortBy.GetOrder(), f: sortBy.New})
 
 	funcs = append(funcs, initFunc{name: "sortByName", order: sortByName.GetOrder(), f: sortByName.New})
@@ -278,12 +336,18 @@ func New(configs map[string]string, logger *zap.Logger) {
 
 	funcs = append(funcs, initFunc{name: "timeShift", order: timeShift.GetOrder(), f: timeShift.New})
 
+	funcs = append(funcs, initFunc{name: "timeSlice", order: timeSlice.GetOrder(), f: timeSlice.New})
+
 	funcs = append(funcs, initFunc{name: "timeStack", order: timeStack.GetOrder(), f: timeStack.New})
 
 	funcs = append(funcs, initFunc{name: "transformNull", order: transformNull.GetOrder(), f: transformNull.New})
 
 	funcs = append(funcs, initFunc{name: "tukey", order: tukey.GetOrder(), f: tukey.New})
 
+	funcs = append(funcs, initFunc{name: "unique", order: unique.GetOrder(), f: unique.New})
+
+	funcs = append(funcs, initFunc{name: "useSeriesAbove", order: useSeriesAbove.GetOrder(), f: useSeriesAbove.New})
+
 	funcs = append(funcs, initFunc{name: "weightedAverage", order: weightedAverage.GetOrder(), f: weightedAverage.New})
 
 	// Sort functions in REVERSE order by name unless function's GetOrder() is set to Last.
@@ -298,9 +362,20 @@ func New(configs map[string]string, logger *zap.Logger) {
 	})
 
 	for _, f := range funcs {
-		md := f.f(configs[strings.ToLower(f.name)])
+		var md []interfaces.FunctionMetadata
+		if f.configured != nil {
+			var err error
+			md, err = f.configured(configs[strings.ToLower(f.name)])
+			if err != nil {
+				return err
+			}
+		} else {
+			md = f.f(configs[strings.ToLower(f.name)])
+		}
 		for _, m := range md {
 			metadata.RegisterFunction(m.Name, m.F, logger)
 		}
 	}
+
+	return nil
 }
@@ -30,7 +30,7 @@ func TestGrep(t *testing.T) {
 		{
 			"grep(metric1,\"Bar\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metricFoo", []float64{1, 1, 1, 1, 1}, 1, now32),
 					types.MakeMetricData("metricBar", []float64{2, 2, 2, 2, 2}, 1, now32),
 					types.MakeMetricData("metricBaz", []float64{3, 3, 3, 3, 3}, 1, now32),
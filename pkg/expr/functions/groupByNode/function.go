@@ -94,14 +94,14 @@ func (f *groupByNode) Do(ctx context.Context, e parser.Expr, from, until int32,
 
 		// create a stub context to evaluate the callback in
 		nexpr, _, err := parser.ParseExpr(expr)
+		if err != nil {
+			return nil, err
+		}
 		// remove all stub_ prefixes we've prepended before
 		nexpr.SetRawArgs(strings.Replace(nexpr.RawArgs(), "stub_", "", 1))
 		for argIdx := range nexpr.Args() {
 			nexpr.Args()[argIdx].SetTarget(strings.Replace(nexpr.Args()[0].Target(), "stub_", "", 1))
 		}
-		if err != nil {
-			return nil, err
-		}
 
 		nvalues := values
 		if e.Target() == "groupByNode" || e.Target() == "groupByNodes" {
@@ -115,7 +115,7 @@ func (f *groupByNode) Do(ctx context.Context, e parser.Expr, from, until int32,
 		}
 
 		r, _ := f.Evaluator.EvalExpr(ctx, nexpr, from, until, nvalues, getTargetData)
-		if r != nil {
+		if len(r) > 0 {
 			r[0].Name = k
 			results = append(results, r...)
 		}
@@ -55,7 +55,7 @@ func TestGroupByNode(t *testing.T) {
 		{
 			"groupByNode(metric1.foo.*.*,3,\"avg\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.*", 0, 1}: {
+				{"metric1.foo.*.*", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar1.baz", []float64{1, 22, 3, 24, 5}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar2.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
 				},
@@ -68,7 +68,7 @@ func TestGroupByNode(t *testing.T) {
 		{
 			"groupByNode(metric1.foo.*.*,3,\"diff\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.*", 0, 1}: {
+				{"metric1.foo.*.*", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar1.baz", []float64{1, 22, 3, 24, 5}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar2.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
 				},
@@ -81,7 +81,7 @@ func TestGroupByNode(t *testing.T) {
 		{
 			"groupByNode(metric1.foo.*.*,3,\"stddev\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.*", 0, 1}: {
+				{"metric1.foo.*.*", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar1.baz", []float64{1, 22, 3, 24, 5}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar2.baz", []float64{11, 2, 13, 4, 5}, 1, now32),
 				},
@@ -94,7 +94,7 @@ func TestGroupByNode(t *testing.T) {
 		{
 			"groupByNode(metric1.foo.*.*,3,\"max\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.*", 0, 1}: {
+				{"metric1.foo.*.*", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar1.baz", []float64{1, 22, 3, 24, 5}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar2.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
 				},
@@ -107,7 +107,7 @@ func TestGroupByNode(t *testing.T) {
 		{
 			"groupByNode(metric1.foo.*.*,3,\"min\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.*", 0, 1}: {
+				{"metric1.foo.*.*", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar1.baz", []float64{1, 22, 3, 24, 5}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar2.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
 				},
@@ -120,7 +120,7 @@ func TestGroupByNode(t *testing.T) {
 		{
 			"groupByNode(metric1.foo.*.*,3,\"sum\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.*", 0, 1}: {
+				{"metric1.foo.*.*", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar1.baz", []float64{1, 2, 3, 4, 5}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar1.qux", []float64{6, 7, 8, 9, 10}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar2.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
@@ -136,7 +136,7 @@ func TestGroupByNode(t *testing.T) {
 		{
 			"groupByNode(metric1.foo.*.*,3,\"sum\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.*", 0, 1}: {
+				{"metric1.foo.*.*", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar1.01", []float64{1, 2, 3, 4, 5}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar1.10", []float64{6, 7, 8, 9, 10}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar2.01", []float64{11, 12, 13, 14, 15}, 1, now32),
@@ -152,7 +152,7 @@ func TestGroupByNode(t *testing.T) {
 		{
 			"groupByNode(metric1.foo.*.*,3,\"sum\")",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.*", 0, 1}: {
+				{"metric1.foo.*.*", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar1.127_0_0_1:2003", []float64{1, 2, 3, 4, 5}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar1.127_0_0_1:2004", []float64{6, 7, 8, 9, 10}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar2.127_0_0_1:2003", []float64{11, 12, 13, 14, 15}, 1, now32),
@@ -168,7 +168,7 @@ func TestGroupByNode(t *testing.T) {
 		{
 			"groupByNodes(metric1.foo.*.*,\"sum\",0,1,3)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.*.*", 0, 1}: {
+				{"metric1.foo.*.*", 0, 1, 0}: {
 					types.MakeMetricData("metric1.foo.bar1.baz", []float64{1, 2, 3, 4, 5}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar1.qux", []float64{6, 7, 8, 9, 10}, 1, now32),
 					types.MakeMetricData("metric1.foo.bar2.baz", []float64{11, 12, 13, 14, 15}, 1, now32),
@@ -0,0 +1,142 @@
+package groupByTags
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type groupByTags struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &groupByTags{}
+	functions := []string{"groupByTags"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// groupByTags(seriesList, callback, *tags)
+func (f *groupByTags) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := e.GetStringArg(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(e.Args()) < 3 {
+		return nil, parser.ErrMissingArgument
+	}
+	var tags []string
+	byName := false
+	for i := 2; i < len(e.Args()); i++ {
+		tag, err := e.GetStringArg(i)
+		if err != nil {
+			return nil, err
+		}
+		if tag == "name" {
+			byName = true
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	seriesTags := make([]map[string]string, len(args))
+	names := make(map[string]struct{})
+	for i, a := range args {
+		seriesTags[i] = helper.ExtractTags(a.Name)
+		names[seriesTags[i]["name"]] = struct{}{}
+	}
+
+	// if all of the series have the same name it is used for the results, otherwise the callback is
+	name := callback
+	if len(names) == 1 {
+		for n := range names {
+			name = n
+		}
+	}
+
+	groups := make(map[string][]*types.MetricData)
+	keys := []string{}
+	for i, a := range args {
+		key := make([]string, 0, len(tags)+1)
+		if byName {
+			key = append(key, seriesTags[i]["name"])
+		} else {
+			key = append(key, name)
+		}
+		for _, tag := range tags {
+			key = append(key, tag+"="+seriesTags[i][tag])
+		}
+
+		k := strings.Join(key, ";")
+		if len(groups[k]) == 0 {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], a)
+	}
+
+	return helper.AggregateGroups(ctx, callback, keys, groups, from, until, getTargetData)
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *groupByTags) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"groupByTags": {
+			Description: "Takes a serieslist and maps a callback to subgroups within as defined by multiple tags\n\n.. code-block:: none\n\n  &target=seriesByTag(\"name=cpu\")|groupByTags(\"average\",\"dc\")\n\nWould return multiple series which are each the result of applying the \"averageSeries\" function\nto groups joined on the specified tags resulting in a list of targets like\n\n.. code-block :: none\n\n  averageSeries(seriesByTag(\"name=cpu\",\"dc=dc1\")),averageSeries(seriesByTag(\"name=cpu\",\"dc=dc2\")),...\n\nThis function can be used with all aggregation functions supported by\n:py:func:`aggregate <aggregate>`: ``average``, ``median``, ``sum``, ``min``, ``max``, ``diff``,\n``stddev``, ``range`` & ``multiply``.",
+			Function:    "groupByTags(seriesList, callback, *tags)",
+			Group:       "Combine",
+			Module:      "graphite.render.functions",
+			Name:        "groupByTags",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name: "callback",
+					Options: []string{
+						"average",
+						"count",
+						"diff",
+						"last",
+						"max",
+						"median",
+						"min",
+						"multiply",
+						"range",
+						"stddev",
+						"sum",
+					},
+					Required: true,
+					Type:     types.AggFunc,
+				},
+				{
+					Multiple: true,
+					Name:     "tags",
+					Required: true,
+					Type:     types.Tag,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package groupByTags
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/averageSeries"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/seriesByTag"
+	"github.com/bookingcom/carbonapi/pkg/expr/functions/sum"
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	for _, md := range [][]interfaces.FunctionMetadata{New(""), sum.New(""), averageSeries.New(""), seriesByTag.New("")} {
+		for _, m := range md {
+			metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+		}
+	}
+	evaluator := th.EvaluatorFromFuncWithMetadata(metadata.FunctionMD.Functions)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+}
+
+func TestGroupByTags(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	tests := []th.MultiReturnEvalTestItem{
+		{
+			"groupByTags(seriesByTag('name=cpu'),'sum','dc')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"seriesByTag('name=cpu')", 0, 1, 0}: {
+					types.MakeMetricData("cpu;dc=ams;host=a", []float64{1, 2, 3}, 1, now32),
+					types.MakeMetricData("cpu;dc=ams;host=b", []float64{4, 5, 6}, 1, now32),
+					types.MakeMetricData("cpu;dc=lhr;host=c", []float64{7, 8, 9}, 1, now32),
+				},
+			},
+			"groupByTagsSum",
+			map[string][]*types.MetricData{
+				"cpu;dc=ams": {types.MakeMetricData("cpu;dc=ams", []float64{5, 7, 9}, 1, now32)},
+				"cpu;dc=lhr": {types.MakeMetricData("cpu;dc=lhr", []float64{7, 8, 9}, 1, now32)},
+			},
+		},
+		{
+			"groupByTags(seriesByTag('dc=ams'),'average','name','dc')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"seriesByTag('dc=ams')", 0, 1, 0}: {
+					types.MakeMetricData("cpu;dc=ams;host=a", []float64{1, 2, 3}, 1, now32),
+					types.MakeMetricData("cpu;dc=ams;host=b", []float64{3, 4, 5}, 1, now32),
+					types.MakeMetricData("mem;dc=ams;host=a", []float64{7, 8, 9}, 1, now32),
+				},
+			},
+			"groupByTagsByName",
+			map[string][]*types.MetricData{
+				"cpu;dc=ams": {types.MakeMetricData("cpu;dc=ams", []float64{2, 3, 4}, 1, now32)},
+				"mem;dc=ams": {types.MakeMetricData("mem;dc=ams", []float64{7, 8, 9}, 1, now32)},
+			},
+		},
+		{
+			"groupByTags(seriesByTag('dc=ams'),'sum','host')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"seriesByTag('dc=ams')", 0, 1, 0}: {
+					types.MakeMetricData("cpu;dc=ams;host=a", []float64{1, 2, 3}, 1, now32),
+					types.MakeMetricData("mem;dc=ams;host=a", []float64{7, 8, 9}, 1, now32),
+				},
+			},
+			"groupByTagsMixedNames",
+			map[string][]*types.MetricData{
+				"sum;host=a": {types.MakeMetricData("sum;host=a", []float64{8, 10, 12}, 1, now32)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		testName := tt.Name
+		t.Run(testName, func(t *testing.T) {
+			th.TestMultiReturnEvalExpr(t, &tt)
+		})
+	}
+}
+
+func TestGroupByTagsCallbackError(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+	exp, _, err := parser.ParseExpr("groupByTags(seriesByTag('name=cpu'),'unknownCallback','dc')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[parser.MetricRequest][]*types.MetricData{
+		{"seriesByTag('name=cpu')", 0, 1, 0}: {
+			types.MakeMetricData("cpu;dc=ams;host=a", []float64{1, 2, 3}, 1, now32),
+		},
+	}
+
+	res, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1, values, th.NoopGetTargetData)
+	if err == nil {
+		t.Errorf("expected the error of the callback, got %v", res)
+	}
+}
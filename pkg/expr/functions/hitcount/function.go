@@ -68,14 +68,17 @@ func (f *hitcount) Do(ctx context.Context, e parser.Expr, from, until int32, val
 		}
 		name += ")"
 
-		r := types.MetricData{Metric: dataTypes.Metric{
-			Name:      name,
-			Values:    make([]float64, buckets, buckets+1),
-			IsAbsent:  make([]bool, buckets, buckets+1),
-			StepTime:  bucketSize,
-			StartTime: start,
-			StopTime:  stop,
-		}}
+		r := types.MetricData{
+			Metric: dataTypes.Metric{
+				Name:         name,
+				Values:       make([]float64, buckets, buckets+1),
+				IsAbsent:     make([]bool, buckets, buckets+1),
+				StepTime:     bucketSize,
+				StartTime:    start,
+				StopTime:     stop,
+				XFilesFactor: arg.XFilesFactor,
+			},
+		}
 
 		bucketEnd := start + bucketSize
 		t := arg.StartTime
@@ -69,14 +69,17 @@ func (f *holtWintersAberration) Do(ctx context.Context, e parser.Expr, from, unt
 			}
 		}
 
-		r := types.MetricData{Metric: dataTypes.Metric{
-			Name:      fmt.Sprintf("holtWintersAberration(%s)", arg.Name),
-			Values:    aberration,
-			IsAbsent:  make([]bool, len(aberration)),
-			StepTime:  arg.StepTime,
-			StartTime: arg.StopTime - int32(datapoints)*stepTime,
-			StopTime:  arg.StopTime,
-		}}
+		r := types.MetricData{
+			Metric: dataTypes.Metric{
+				Name:         fmt.Sprintf("holtWintersAberration(%s)", arg.Name),
+				Values:       aberration,
+				IsAbsent:     make([]bool, len(aberration)),
+				StepTime:     arg.StepTime,
+				StartTime:    arg.StopTime - int32(datapoints)*stepTime,
+				StopTime:     arg.StopTime,
+				XFilesFactor: arg.XFilesFactor,
+			},
+		}
 
 		results = append(results, &r)
 	}
@@ -54,14 +54,17 @@ func (f *holtWintersConfidenceBands) Do(ctx context.Context, e parser.Expr, from
 		}
 		datapoints := int((until - from) / stepTime)
 		lowerBand, upperBand := holtwinters.HoltWintersConfidenceBands(values, datapoints, stepTime, delta)
-		lowerSeries := types.MetricData{Metric: dataTypes.Metric{
-			Name:      fmt.Sprintf("holtWintersConfidenceLower(%s)", arg.Name),
-			Values:    lowerBand,
-			IsAbsent:  make([]bool, len(lowerBand)),
-			StepTime:  arg.StepTime,
-			StartTime: arg.StopTime - int32(datapoints)*stepTime,
-			StopTime:  arg.StopTime,
-		}}
+		lowerSeries := types.MetricData{
+			Metric: dataTypes.Metric{
+				Name:         fmt.Sprintf("holtWintersConfidenceLower(%s)", arg.Name),
+				Values:       lowerBand,
+				IsAbsent:     make([]bool, len(lowerBand)),
+				StepTime:     arg.StepTime,
+				StartTime:    arg.StopTime - int32(datapoints)*stepTime,
+				StopTime:     arg.StopTime,
+				XFilesFactor: arg.XFilesFactor,
+			},
+		}
 
 		for i, val := range lowerSeries.Values {
 			if math.IsNaN(val) {
@@ -70,14 +73,17 @@ func (f *holtWintersConfidenceBands) Do(ctx context.Context, e parser.Expr, from
 			}
 		}
 
-		upperSeries := types.MetricData{Metric: dataTypes.Metric{
-			Name:      fmt.Sprintf("holtWintersConfidenceUpper(%s)", arg.Name),
-			Values:    upperBand,
-			IsAbsent:  make([]bool, len(upperBand)),
-			StepTime:  arg.StepTime,
-			StartTime: arg.StopTime - int32(datapoints)*stepTime,
-			StopTime:  arg.StopTime,
-		}}
+		upperSeries := types.MetricData{
+			Metric: dataTypes.Metric{
+				Name:         fmt.Sprintf("holtWintersConfidenceUpper(%s)", arg.Name),
+				Values:       upperBand,
+				IsAbsent:     make([]bool, len(upperBand)),
+				StepTime:     arg.StepTime,
+				StartTime:    arg.StopTime - int32(datapoints)*stepTime,
+				StopTime:     arg.StopTime,
+				XFilesFactor: arg.XFilesFactor,
+			},
+		}
 
 		for i, val := range upperSeries.Values {
 			if math.IsNaN(val) {
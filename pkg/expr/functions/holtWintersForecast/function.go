@@ -45,14 +45,17 @@ func (f *holtWintersForecast) Do(ctx context.Context, e parser.Expr, from, until
 		windowPoints := 7 * 86400 / stepTime
 		predictionsOfInterest := predictions[windowPoints:]
 
-		r := types.MetricData{Metric: dataTypes.Metric{
-			Name:      fmt.Sprintf("holtWintersForecast(%s)", arg.Name),
-			Values:    predictionsOfInterest,
-			IsAbsent:  make([]bool, len(predictionsOfInterest)),
-			StepTime:  arg.StepTime,
-			StartTime: arg.StartTime + 7*86400,
-			StopTime:  arg.StopTime,
-		}}
+		r := types.MetricData{
+			Metric: dataTypes.Metric{
+				Name:         fmt.Sprintf("holtWintersForecast(%s)", arg.Name),
+				Values:       predictionsOfInterest,
+				IsAbsent:     make([]bool, len(predictionsOfInterest)),
+				StepTime:     arg.StepTime,
+				StartTime:    arg.StartTime + 7*86400,
+				StopTime:     arg.StopTime,
+				XFilesFactor: arg.XFilesFactor,
+			},
+		}
 
 		results = append(results, &r)
 	}
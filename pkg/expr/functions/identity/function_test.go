@@ -0,0 +1,42 @@
+package identity
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	from, until := int32(1600000000), int32(1600000300)
+
+	exp, _, err := parser.ParseExpr("identity('the.time.series')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, from, until, nil, th.NoopGetTargetData)
+	if err != nil {
+		t.Fatalf("failed to eval: %v", err)
+	}
+
+	want := types.MakeMetricData("the.time.series", []float64{1600000000, 1600000060, 1600000120, 1600000180, 1600000240}, 60, from)
+	if len(g) != 1 || g[0].Name != want.Name || g[0].StepTime != want.StepTime || !th.NearlyEqualMetrics(g[0], want) {
+		t.Errorf("got %+v, want %+v", g, want)
+	}
+}
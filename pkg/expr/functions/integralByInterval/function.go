@@ -56,12 +56,13 @@ func (f *integralByInterval) Do(ctx context.Context, e parser.Expr, from, until
 		name := fmt.Sprintf("integralByInterval(%s,'%s')", arg.Name, e.Args()[1].StringValue())
 		result := &types.MetricData{
 			Metric: dataTypes.Metric{
-				Name:      name,
-				Values:    make([]float64, len(arg.Values)),
-				IsAbsent:  arg.IsAbsent,
-				StepTime:  arg.StepTime,
-				StartTime: arg.StartTime,
-				StopTime:  arg.StopTime,
+				Name:         name,
+				Values:       make([]float64, len(arg.Values)),
+				IsAbsent:     arg.IsAbsent,
+				StepTime:     arg.StepTime,
+				StartTime:    arg.StartTime,
+				StopTime:     arg.StopTime,
+				XFilesFactor: arg.XFilesFactor,
 			},
 		}
 		for i, v := range arg.Values {
@@ -0,0 +1,98 @@
+package interpolate
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type interpolate struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &interpolate{}
+	functions := []string{"interpolate"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// interpolate(seriesList, limit=inf)
+func (f *interpolate) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := e.GetFloatNamedOrPosArgDefault("limit", 1, math.Inf(1))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*types.MetricData, 0, len(args))
+	for _, a := range args {
+		r := *a
+		r.Name = fmt.Sprintf("interpolate(%s)", a.Name)
+		r.Values = make([]float64, len(a.Values))
+		r.IsAbsent = make([]bool, len(a.Values))
+		copy(r.Values, a.Values)
+		copy(r.IsAbsent, a.IsAbsent)
+
+		// the gaps between two present points are filled linearly, unless they are longer than the limit
+		last := -1
+		for i, absent := range a.IsAbsent {
+			if absent {
+				continue
+			}
+			gap := i - last - 1
+			if last >= 0 && gap > 0 && float64(gap) <= limit {
+				delta := (a.Values[i] - a.Values[last]) / float64(gap+1)
+				for j := last + 1; j < i; j++ {
+					r.Values[j] = a.Values[last] + delta*float64(j-last)
+					r.IsAbsent[j] = false
+				}
+			}
+			last = i
+		}
+
+		results = append(results, &r)
+	}
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *interpolate) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"interpolate": {
+			Description: "Takes one metric or a wildcard seriesList, and optionally a limit to the number of 'None' values to skip over.\nContinues the line with the last received value when gaps ('None' values) appear in your data, rather than breaking your line.\n\nExample:\n\n.. code-block:: none\n\n  &target=interpolate(Server01.connections.handled)\n  &target=interpolate(Server01.connections.handled, 10)",
+			Function:    "interpolate(seriesList, limit=inf)",
+			Group:       "Transform",
+			Module:      "graphite.render.functions",
+			Name:        "interpolate",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Default: types.NewSuggestion("inf"),
+					Name:    "limit",
+					Type:    types.Float,
+				},
+			},
+		},
+	}
+}
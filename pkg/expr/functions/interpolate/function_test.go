@@ -0,0 +1,56 @@
+package interpolate
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	tests := []th.EvalTestItem{
+		{
+			"interpolate(metric1)",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{math.NaN(), 1, math.NaN(), 3, math.NaN(), math.NaN(), 0, math.NaN()}, 1, now32)},
+			},
+			[]*types.MetricData{types.MakeMetricData("interpolate(metric1)",
+				[]float64{math.NaN(), 1, 2, 3, 2, 1, 0, math.NaN()}, 1, now32)},
+		},
+		{
+			"interpolate(metric1,1)",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{math.NaN(), 1, math.NaN(), 3, math.NaN(), math.NaN(), 0, math.NaN()}, 1, now32)},
+			},
+			[]*types.MetricData{types.MakeMetricData("interpolate(metric1)",
+				[]float64{math.NaN(), 1, 2, 3, math.NaN(), math.NaN(), 0, math.NaN()}, 1, now32)},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		testName := tt.Target
+		t.Run(testName, func(t *testing.T) {
+			th.TestEvalExpr(t, &tt)
+		})
+	}
+}
@@ -30,7 +30,7 @@ func New(configFile string) []interfaces.FunctionMetadata {
 	return res
 }
 
-// movingXyz(seriesList, windowSize)
+// movingXyz(seriesList, windowSize, xFilesFactor=None)
 func (f *moving) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
 	var n int
 	var err error
@@ -82,6 +82,11 @@ func (f *moving) Do(ctx context.Context, e parser.Expr, from, until int32, value
 	}
 
 	for _, a := range arg {
+		xFilesFactor, err := e.GetFloatNamedOrPosArgDefault("xFilesFactor", 2, float64(a.XFilesFactor))
+		if err != nil {
+			return nil, err
+		}
+
 		w := &types.Windowed{Data: make([]float64, windowSize)}
 
 		r := *a
@@ -120,7 +125,7 @@ func (f *moving) Do(ctx context.Context, e parser.Expr, from, until int32, value
 					case "movingMax":
 						r.Values[ridx] = w.Max()
 					}
-					if i < windowSize || math.IsNaN(r.Values[ridx]) {
+					if i < windowSize || math.IsNaN(r.Values[ridx]) || !types.XFilesFactorValid(w.Len(), windowSize, float32(xFilesFactor)) {
 						r.Values[ridx] = 0
 						r.IsAbsent[ridx] = true
 					}
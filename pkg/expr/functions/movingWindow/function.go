@@ -0,0 +1,163 @@
+package movingWindow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type movingWindow struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &movingWindow{}
+	functions := []string{"movingWindow"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// movingWindow(seriesList, windowSize, func='average', xFilesFactor=None)
+func (f *movingWindow) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	window, err := helper.GetWindowArg(e, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	funcName, err := e.GetStringNamedOrPosArgDefault("func", 2, "average")
+	if err != nil {
+		return nil, err
+	}
+	aggFunc, ok := types.GetAggregateFunction(funcName)
+	if !ok || funcName == "" {
+		return nil, fmt.Errorf("%w: unsupported aggregation function %s", parser.ErrInvalidArgumentValue, funcName)
+	}
+
+	_, xFilesFactorOk := e.NamedArgs()["xFilesFactor"]
+	if !xFilesFactorOk {
+		xFilesFactorOk = len(e.Args()) > 3
+	}
+	xFilesFactor, err := e.GetFloatNamedOrPosArgDefault("xFilesFactor", 3, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	args, bootstrap, err := window.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	// as in graphite-web the name is the one of the moving function of the aggregation
+	name := "moving" + strings.ToUpper(funcName[:1]) + funcName[1:]
+
+	results := make([]*types.MetricData, 0, len(args))
+	for _, a := range args {
+		points := window.Points(a.StepTime)
+		if points <= 0 {
+			return nil, fmt.Errorf("%w: the window %s is shorter than the step of %s", parser.ErrInvalidArgumentValue, window, a.Name)
+		}
+
+		xff := a.XFilesFactor
+		if xFilesFactorOk {
+			xff = float32(xFilesFactor)
+		}
+
+		// the bootstrapped history is only used for the windows
+		offset := int(bootstrap / a.StepTime)
+		if offset > len(a.Values) {
+			offset = len(a.Values)
+		}
+
+		r := *a
+		r.Name = fmt.Sprintf("%s(%s,%s)", name, a.Name, window)
+		r.Values = make([]float64, len(a.Values)-offset)
+		r.IsAbsent = make([]bool, len(a.Values)-offset)
+		r.StartTime = a.StartTime + int32(offset)*a.StepTime
+
+		for i := offset; i < len(a.Values); i++ {
+			start := i - points
+			if start < 0 {
+				start = 0
+			}
+
+			nonNull := 0
+			for _, absent := range a.IsAbsent[start:i] {
+				if !absent {
+					nonNull++
+				}
+			}
+
+			ridx := i - offset
+			if !types.XFilesFactorValid(nonNull, points, xff) {
+				r.IsAbsent[ridx] = true
+				continue
+			}
+
+			r.Values[ridx], r.IsAbsent[ridx] = aggFunc(a.Values[start:i], a.IsAbsent[start:i])
+			if r.IsAbsent[ridx] {
+				r.Values[ridx] = 0
+			}
+		}
+
+		results = append(results, &r)
+	}
+
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *movingWindow) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"movingWindow": {
+			Description: "Graphs a moving window function of a metric (or metrics) over a fixed number of\npast points, or a time interval.\n\nTakes one metric or a wildcard seriesList, a number N of datapoints\nor a quoted string with a length of time like '1hour' or '5min' (See ``from /\nuntil`` in the render\\_api_ for examples of time formats), a function to apply to the points\nin the window to produce the output, and an xFilesFactor value to specify how many points in the\nwindow must be non-null for the output to be considered valid. Graphs the\noutput of the function for the preceeding datapoints for each point on the graph.\n\nExample:\n\n.. code-block:: none\n\n  &target=movingWindow(Server.instance01.threads.busy,10)\n  &target=movingWindow(Server.instance*.threads.idle,'5min','median',0.5)\n\n.. note::\n\n  `xFilesFactor` follows the same semantics as in Whisper storage schemas.  Setting it to 0 (the\n  default) means that only a single value in a given interval needs to be non-null, setting it to\n  1 means that all values in the interval must be non-null.  A setting of 0.5 means that at least\n  half the values in the interval must be non-null.",
+			Function:    "movingWindow(seriesList, windowSize, func='average', xFilesFactor=None)",
+			Group:       "Calculate",
+			Module:      "graphite.render.functions",
+			Name:        "movingWindow",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "windowSize",
+					Required: true,
+					Suggestions: types.NewSuggestions(
+						5,
+						7,
+						10,
+						"1min",
+						"5min",
+						"10min",
+						"30min",
+						"1hour",
+					),
+					Type: types.IntOrInterval,
+				},
+				{
+					Default: types.NewSuggestion("average"),
+					Name:    "func",
+					Options: types.AggregateFunctionNames(),
+					Type:    types.AggFunc,
+				},
+				{
+					Name: "xFilesFactor",
+					Type: types.Float,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package movingWindow
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestMovingWindow(t *testing.T) {
+	var from, until int32 = 1000, 1005
+
+	// the series is fetched from from for its step and with the history of the window
+	m := map[parser.MetricRequest][]*types.MetricData{
+		{Metric: "metric1", From: from, Until: until}:     {types.MakeMetricData("metric1", []float64{4, 5, 6, math.NaN(), 8}, 1, from)},
+		{Metric: "metric1", From: from - 3, Until: until}: {types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 5, 6, math.NaN(), 8}, 1, from-3)},
+	}
+
+	tests := []struct {
+		target string
+		want   *types.MetricData
+	}{
+		{
+			"movingWindow(metric1,3)",
+			types.MakeMetricData("movingAverage(metric1,3)", []float64{2, 3, 4, 5, 5.5}, 1, from),
+		},
+		{
+			"movingWindow(metric1,3,'max')",
+			types.MakeMetricData("movingMax(metric1,3)", []float64{3, 4, 5, 6, 6}, 1, from),
+		},
+		{
+			"movingWindow(metric1,'3s','sum',0.9)",
+			types.MakeMetricData(`movingSum(metric1,"3s")`, []float64{6, 9, 12, 15, math.NaN()}, 1, from),
+		},
+		{
+			"movingWindow(metric1,3,func='median',xFilesFactor=0.5)",
+			types.MakeMetricData("movingMedian(metric1,3)", []float64{2, 3, 4, 5, 5.5}, 1, from),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.target, func(t *testing.T) {
+			exp, _, err := parser.ParseExpr(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, from, until, m, th.NoopGetTargetData)
+			if err != nil {
+				t.Fatalf("failed to eval %s: %+v", tt.target, err)
+			}
+			if len(g) != 1 {
+				t.Fatalf("expected one series, got %d", len(g))
+			}
+			if g[0].Name != tt.want.Name {
+				t.Errorf("got the name %s, want %s", g[0].Name, tt.want.Name)
+			}
+			if g[0].StartTime != tt.want.StartTime || g[0].StepTime != tt.want.StepTime {
+				t.Errorf("got start %d and step %d, want %d and %d", g[0].StartTime, g[0].StepTime, tt.want.StartTime, tt.want.StepTime)
+			}
+			if !th.NearlyEqualMetrics(g[0], tt.want) {
+				t.Errorf("got %v, want %v", g[0].Values, tt.want.Values)
+			}
+		})
+	}
+}
+
+func TestMovingWindowUnknownFunction(t *testing.T) {
+	exp, _, err := parser.ParseExpr("movingWindow(metric1,3,'foo')")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1, map[parser.MetricRequest][]*types.MetricData{}, th.NoopGetTargetData)
+	if !errors.Is(err, parser.ErrInvalidArgumentValue) {
+		t.Errorf("got the error %v, want %v", err, parser.ErrInvalidArgumentValue)
+	}
+}
@@ -0,0 +1,78 @@
+package powSeries
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type powSeries struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &powSeries{}
+	functions := []string{"powSeries"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// powSeries(*seriesLists)
+func (f *powSeries) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArgs(ctx, e.Args(), from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(args))
+	for _, a := range args {
+		names = append(names, a.Name)
+	}
+	name := fmt.Sprintf("powSeries(%s)", strings.Join(names, ","))
+
+	// the first series is raised to the power of the second one, the result to the power of the third one and so on
+	return helper.AggregateSeries(name, args, false, true, 0, func(values []float64) (float64, bool) {
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Pow(result, v)
+		}
+		if math.IsNaN(result) || math.IsInf(result, 0) {
+			return 0, true
+		}
+		return result, false
+	})
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *powSeries) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"powSeries": {
+			Description: "Takes two or more series and pows their points. A constant line may be\nused.\n\nExample:\n\n.. code-block:: none\n\n  &target=powSeries(Server.instance01.app.requests, Server.instance01.app.replies)",
+			Function:    "powSeries(*seriesLists)",
+			Group:       "Combine",
+			Module:      "graphite.render.functions",
+			Name:        "powSeries",
+			Params: []types.FunctionParam{
+				{
+					Multiple: true,
+					Name:     "seriesLists",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+			},
+		},
+	}
+}
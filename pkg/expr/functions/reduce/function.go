@@ -7,6 +7,7 @@ import (
 	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
 	"github.com/bookingcom/carbonapi/pkg/expr/types"
 	"github.com/bookingcom/carbonapi/pkg/parser"
+	"github.com/bookingcom/carbonapi/pkg/util"
 
 	"strings"
 )
@@ -83,9 +84,10 @@ func (f *reduce) Do(ctx context.Context, e parser.Expr, from, until int32, value
 
 		reduceGroups[aliasName][reduceNodeKey] = series
 		valueKey := parser.MetricRequest{
-			Metric: series.Name,
-			From:   from,
-			Until:  until,
+			Metric:        series.Name,
+			From:          from,
+			Until:         until,
+			MaxDataPoints: util.GetMaxDataPoints(ctx),
 		}
 		reducedValues[valueKey] = append(reducedValues[valueKey], series)
 	}
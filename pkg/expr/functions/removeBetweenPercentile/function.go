@@ -0,0 +1,114 @@
+package removeBetweenPercentile
+
+import (
+	"context"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type removeBetweenPercentile struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &removeBetweenPercentile{}
+	functions := []string{"removeBetweenPercentile"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// removeBetweenPercentile(seriesList, n)
+func (f *removeBetweenPercentile) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	percent, err := e.GetFloatArg(1)
+	if err != nil {
+		return nil, err
+	}
+	if percent < 50 {
+		percent = 100 - percent
+	}
+
+	if len(args) == 0 {
+		return args, nil
+	}
+	seriesList, _, _, _, err := helper.Normalize(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var length int
+	for _, s := range seriesList {
+		if len(s.Values) > length {
+			length = len(s.Values)
+		}
+	}
+
+	// the bands of the percentiles of all the series at each point
+	low := make([]float64, length)
+	high := make([]float64, length)
+	lowAbsent := make([]bool, length)
+	for i := 0; i < length; i++ {
+		var column []float64
+		for _, s := range seriesList {
+			if i < len(s.Values) && !s.IsAbsent[i] {
+				column = append(column, s.Values[i])
+			}
+		}
+		low[i], lowAbsent[i] = helper.Percentile(append([]float64(nil), column...), 100-percent, false)
+		high[i], _ = helper.Percentile(column, percent, false)
+	}
+
+	var results []*types.MetricData
+	for j, s := range seriesList {
+		for i, v := range s.Values {
+			if s.IsAbsent[i] || lowAbsent[i] {
+				continue
+			}
+			if v <= low[i] || v >= high[i] {
+				results = append(results, args[j])
+				break
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *removeBetweenPercentile) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"removeBetweenPercentile": {
+			Description: "Removes series that do not have an value lying in the x-percentile of all the values at a moment",
+			Function:    "removeBetweenPercentile(seriesList, n)",
+			Group:       "Filter Series",
+			Module:      "graphite.render.functions",
+			Name:        "removeBetweenPercentile",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "n",
+					Required: true,
+					Type:     types.Integer,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package removeBetweenPercentile
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestRemoveBetweenPercentile(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	metrics := func() map[parser.MetricRequest][]*types.MetricData {
+		var series []*types.MetricData
+		for i := 1; i <= 10; i++ {
+			v := float64(i)
+			series = append(series, types.MakeMetricData(fmt.Sprintf("metric%d", i), []float64{v, math.NaN(), v, v}, 1, now32))
+		}
+		// the spike is out of the band
+		series[4].Values[3] = 20
+
+		return map[parser.MetricRequest][]*types.MetricData{
+			{"metric*", 0, 1, 0}: series,
+		}
+	}
+
+	want := []*types.MetricData{
+		types.MakeMetricData("metric1", []float64{1, math.NaN(), 1, 1}, 1, now32),
+		types.MakeMetricData("metric2", []float64{2, math.NaN(), 2, 2}, 1, now32),
+		types.MakeMetricData("metric3", []float64{3, math.NaN(), 3, 3}, 1, now32),
+		types.MakeMetricData("metric5", []float64{5, math.NaN(), 5, 20}, 1, now32),
+		types.MakeMetricData("metric9", []float64{9, math.NaN(), 9, 9}, 1, now32),
+		types.MakeMetricData("metric10", []float64{10, math.NaN(), 10, 10}, 1, now32),
+	}
+
+	tests := []th.EvalTestItem{
+		{"removeBetweenPercentile(metric*,20)", metrics(), want},
+		{"removeBetweenPercentile(metric*,80)", metrics(), want},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		testName := tt.Target
+		t.Run(testName, func(t *testing.T) {
+			th.TestEvalExpr(t, &tt)
+		})
+	}
+}
@@ -34,19 +34,25 @@ func (f *removeEmptySeries) Do(ctx context.Context, e parser.Expr, from, until i
 		return nil, err
 	}
 
-	// TODO: implement xFilesFactor
-
 	var results []*types.MetricData
 
 	for _, a := range args {
+		xFilesFactor, err := e.GetFloatNamedOrPosArgDefault("xFilesFactor", 1, float64(a.XFilesFactor))
+		if err != nil {
+			return nil, err
+		}
+
+		nonNull := 0
 		for i, v := range a.IsAbsent {
 			if !v {
 				if e.Target() == "removeEmptySeries" || (a.Values[i] != 0) {
-					results = append(results, a)
-					break
+					nonNull++
 				}
 			}
 		}
+		if types.XFilesFactorValid(nonNull, len(a.Values), float32(xFilesFactor)) {
+			results = append(results, a)
+		}
 	}
 	return results, nil
 }
@@ -0,0 +1,64 @@
+package round
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestRound(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	tests := []th.EvalTestItem{
+		{
+			"round(metric1)",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1.4, 1.5, math.NaN(), -2.5, 1234.567}, 1, now32)},
+			},
+			[]*types.MetricData{types.MakeMetricData("round(metric1)",
+				[]float64{1, 2, math.NaN(), -3, 1235}, 1, now32)},
+		},
+		{
+			"round(metric1,2)",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1.4, 1.5, math.NaN(), -2.5, 1234.567}, 1, now32)},
+			},
+			[]*types.MetricData{types.MakeMetricData("round(metric1,2)",
+				[]float64{1.4, 1.5, math.NaN(), -2.5, 1234.57}, 1, now32)},
+		},
+		{
+			"round(metric1,-2)",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1.4, 1.5, math.NaN(), -2.5, 1234.567}, 1, now32)},
+			},
+			[]*types.MetricData{types.MakeMetricData("round(metric1,-2)",
+				[]float64{0, 0, math.NaN(), 0, 1200}, 1, now32)},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		testName := tt.Target
+		t.Run(testName, func(t *testing.T) {
+			th.TestEvalExpr(t, &tt)
+		})
+	}
+}
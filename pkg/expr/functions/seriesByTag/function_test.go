@@ -0,0 +1,88 @@
+package seriesByTag
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestSeriesByTag(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	tests := []th.EvalTestItem{
+		{
+			"seriesByTag('name=cpu')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"seriesByTag('name=cpu')", 0, 1, 0}: {
+					types.MakeMetricData("cpu;dc=ams", []float64{1, 2, 3}, 1, now32),
+					types.MakeMetricData("cpu;dc=lhr", []float64{4, 5, 6}, 1, now32),
+				},
+			},
+			[]*types.MetricData{
+				types.MakeMetricData("cpu;dc=ams", []float64{1, 2, 3}, 1, now32),
+				types.MakeMetricData("cpu;dc=lhr", []float64{4, 5, 6}, 1, now32),
+			},
+		},
+		{
+			"seriesByTag('name=cpu', 'dc=~ams.*')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"seriesByTag('name=cpu', 'dc=~ams.*')", 0, 1, 0}: {
+					types.MakeMetricData("cpu;dc=ams", []float64{1, 2, 3}, 1, now32),
+				},
+			},
+			[]*types.MetricData{
+				types.MakeMetricData("cpu;dc=ams", []float64{1, 2, 3}, 1, now32),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		testName := tt.Target
+		t.Run(testName, func(t *testing.T) {
+			th.TestEvalExpr(t, &tt)
+		})
+	}
+}
+
+func TestSeriesByTagErrors(t *testing.T) {
+	tests := []struct {
+		target string
+		err    error
+	}{
+		{"seriesByTag('name=cpu')", parser.ErrSeriesDoesNotExist},
+		{"seriesByTag(1)", parser.ErrBadType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			exp, _, err := parser.ParseExpr(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1,
+				map[parser.MetricRequest][]*types.MetricData{}, th.NoopGetTargetData)
+			if err != tt.err {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package setXFilesFactor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type setXFilesFactor struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &setXFilesFactor{}
+	functions := []string{"setXFilesFactor", "xFilesFactor"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// setXFilesFactor(seriesList, xFilesFactor)
+func (f *setXFilesFactor) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	arg, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+	xFilesFactor, err := e.GetFloatArg(1)
+	if err != nil {
+		return nil, err
+	}
+	if xFilesFactor < 0 || xFilesFactor > 1 {
+		return nil, fmt.Errorf("%w: xFilesFactor %v is not between 0 and 1", parser.ErrInvalidArgumentValue, xFilesFactor)
+	}
+
+	var results []*types.MetricData
+
+	for _, a := range arg {
+		r := *a
+		r.XFilesFactor = float32(xFilesFactor)
+		results = append(results, &r)
+	}
+
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *setXFilesFactor) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"setXFilesFactor": {
+			Description: "Short form: xFilesFactor()\n\nTakes one metric or a wildcard seriesList and an xFilesFactor value between 0 and 1\n\nWhen a series needs to be consolidated, this sets the fraction of values in an interval that must\nnot be null for the consolidation to be considered valid.  If there are not enough values then\nNone will be returned for that interval.\n\n.. code-block:: none\n\n  &target=xFilesFactor(Sales.widgets.largeBlue, 0.5)\n  &target=Servers.web01.sda1.free_space|consolidateBy('max')|xFilesFactor(0.5)\n\nThe ``xFilesFactor`` set via this function is used as the default for all functions that accept an\n``xFilesFactor`` parameter, all functions that aggregate data across multiple series and/or\nintervals, and `maxDataPoints <render_api.html#maxdatapoints>`_ consolidation.\n\n.. note::\n\n  `xFilesFactor` follows the same semantics as in Whisper storage schemas.  Setting it to 0 (the\n  default) means that only a single value in a given interval needs to be non-null, setting it to\n  1 means that all values in the interval must be non-null.  A setting of 0.5 means that at least\n  half the values in the interval must be non-null.",
+			Function:    "setXFilesFactor(seriesList, xFilesFactor)",
+			Group:       "Special",
+			Module:      "graphite.render.functions",
+			Name:        "setXFilesFactor",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "xFilesFactor",
+					Required: true,
+					Type:     types.Float,
+				},
+			},
+		},
+		"xFilesFactor": {
+			Description: "Short form: xFilesFactor()\n\nTakes one metric or a wildcard seriesList and an xFilesFactor value between 0 and 1\n\nWhen a series needs to be consolidated, this sets the fraction of values in an interval that must\nnot be null for the consolidation to be considered valid.  If there are not enough values then\nNone will be returned for that interval.\n\n.. code-block:: none\n\n  &target=xFilesFactor(Sales.widgets.largeBlue, 0.5)\n  &target=Servers.web01.sda1.free_space|consolidateBy('max')|xFilesFactor(0.5)\n\nThe ``xFilesFactor`` set via this function is used as the default for all functions that accept an\n``xFilesFactor`` parameter, all functions that aggregate data across multiple series and/or\nintervals, and `maxDataPoints <render_api.html#maxdatapoints>`_ consolidation.\n\n.. note::\n\n  `xFilesFactor` follows the same semantics as in Whisper storage schemas.  Setting it to 0 (the\n  default) means that only a single value in a given interval needs to be non-null, setting it to\n  1 means that all values in the interval must be non-null.  A setting of 0.5 means that at least\n  half the values in the interval must be non-null.",
+			Function:    "xFilesFactor(seriesList, xFilesFactor)",
+			Group:       "Special",
+			Module:      "graphite.render.functions",
+			Name:        "xFilesFactor",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "xFilesFactor",
+					Required: true,
+					Type:     types.Float,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package setXFilesFactor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFuncWithMetadata(metadata.FunctionMD.Functions)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestSetXFilesFactor(t *testing.T) {
+	tests := []struct {
+		target string
+		want   float32
+	}{
+		{"setXFilesFactor(metric1,0.5)", 0.5},
+		{"xFilesFactor(metric1,1)", 1},
+		{"xFilesFactor(metric1,0)", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			exp, _, err := parser.ParseExpr(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values := map[parser.MetricRequest][]*types.MetricData{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 4}, 1, 0)},
+			}
+
+			got, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1, values, th.NoopGetTargetData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("expected 1 series, got %d", len(got))
+			}
+			if got[0].Name != "metric1" {
+				t.Errorf("got the name %s, want metric1", got[0].Name)
+			}
+			if got[0].XFilesFactor != tt.want {
+				t.Errorf("got the xFilesFactor %v, want %v", got[0].XFilesFactor, tt.want)
+			}
+
+			// the consolidation of the series honours its xFilesFactor
+			want := []bool{tt.want > 0.5, tt.want > 0.5}
+			consolidated := got[0].Consolidate(2)
+			for i := range want {
+				if consolidated.IsAbsent[i] != want[i] {
+					t.Errorf("got the consolidated absent points %v, want %v", consolidated.IsAbsent, want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSetXFilesFactorOutOfRange(t *testing.T) {
+	exp, _, err := parser.ParseExpr("setXFilesFactor(metric1,1.5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[parser.MetricRequest][]*types.MetricData{
+		{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 2}, 1, 0)},
+	}
+
+	_, err = metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1, values, th.NoopGetTargetData)
+	if !errors.Is(err, parser.ErrInvalidArgumentValue) {
+		t.Errorf("got the error %v, want %v", err, parser.ErrInvalidArgumentValue)
+	}
+}
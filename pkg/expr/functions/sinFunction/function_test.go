@@ -0,0 +1,63 @@
+package sinFunction
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+	evaluator := th.EvaluatorFromFuncWithMetadata(metadata.FunctionMD.Functions)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+}
+
+func TestSinFunction(t *testing.T) {
+	from, until := int32(1600000000), int32(1600000300)
+
+	tests := []struct {
+		target string
+		want   *types.MetricData
+	}{
+		{
+			"sinFunction('the.wave')",
+			types.MakeMetricData("the.wave", []float64{
+				math.Sin(1600000000), math.Sin(1600000060), math.Sin(1600000120), math.Sin(1600000180), math.Sin(1600000240),
+			}, 60, from),
+		},
+		{
+			"sin('the.wave',2,100)",
+			types.MakeMetricData("the.wave", []float64{
+				2 * math.Sin(1600000000), 2 * math.Sin(1600000100), 2 * math.Sin(1600000200),
+			}, 100, from),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			exp, _, err := parser.ParseExpr(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, from, until, nil, th.NoopGetTargetData)
+			if err != nil {
+				t.Fatalf("failed to eval: %v", err)
+			}
+			if len(g) != 1 || g[0].Name != tt.want.Name || g[0].StepTime != tt.want.StepTime || !th.NearlyEqualMetrics(g[0], tt.want) {
+				t.Errorf("got %+v, want %+v", g[0], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package smartSummarize
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	"github.com/bookingcom/carbonapi/pkg/util"
+)
+
+type smartSummarize struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &smartSummarize{}
+	functions := []string{"smartSummarize"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// alignStart aligns the start to the calendar unit in the time zone, as graphite-web does.
+// The unit is one of years, months, weeks, days, hours, minutes and seconds, and may be abbreviated.
+// The weeks start on Monday, unless the unit ends with the ISO week day, e.g. weeks7 for Sunday.
+func alignStart(start int32, unit string, tz *time.Location) (int32, error) {
+	s := time.Unix(int64(start), 0).In(tz)
+	unit = strings.TrimLeft(unit, "0123456789")
+
+	var t time.Time
+	switch {
+	case strings.HasPrefix(unit, "s"):
+		return start, nil
+	case strings.HasPrefix(unit, "min"):
+		t = time.Date(s.Year(), s.Month(), s.Day(), s.Hour(), s.Minute(), 0, 0, tz)
+	case strings.HasPrefix(unit, "h"):
+		t = time.Date(s.Year(), s.Month(), s.Day(), s.Hour(), 0, 0, 0, tz)
+	case strings.HasPrefix(unit, "d"):
+		t = time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, tz)
+	case strings.HasPrefix(unit, "w"):
+		weekDay := 1
+		if n, err := strconv.Atoi(unit[len(unit)-1:]); err == nil {
+			weekDay = n
+		}
+		// time.Weekday counts from Sunday, ISO week days from Monday
+		isoWeekDay := int(s.Weekday())
+		if isoWeekDay == 0 {
+			isoWeekDay = 7
+		}
+		days := isoWeekDay - weekDay
+		if days < 0 {
+			days += 7
+		}
+		t = time.Date(s.Year(), s.Month(), s.Day()-days, 0, 0, 0, 0, tz)
+	case strings.HasPrefix(unit, "mon"):
+		t = time.Date(s.Year(), s.Month(), 1, 0, 0, 0, 0, tz)
+	case strings.HasPrefix(unit, "m"):
+		t = time.Date(s.Year(), s.Month(), s.Day(), s.Hour(), s.Minute(), 0, 0, tz)
+	case strings.HasPrefix(unit, "y"):
+		t = time.Date(s.Year(), 1, 1, 0, 0, 0, 0, tz)
+	default:
+		return 0, fmt.Errorf("%w: invalid alignTo unit %q", parser.ErrInvalidArgumentValue, unit)
+	}
+
+	return int32(t.Unix()), nil
+}
+
+// smartSummarize(seriesList, intervalString, func='sum', alignTo=None)
+func (f *smartSummarize) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	if len(e.Args()) < 2 {
+		return nil, parser.ErrMissingArgument
+	}
+
+	bucketSize, err := e.GetIntervalArg(1, 1)
+	if err != nil {
+		return nil, err
+	}
+	if bucketSize <= 0 {
+		return nil, fmt.Errorf("%w: the interval must be positive", parser.ErrInvalidArgumentValue)
+	}
+
+	summarizeFunction, err := e.GetStringNamedOrPosArgDefault("func", 2, "sum")
+	if err != nil {
+		return nil, err
+	}
+	aggFunc, known := types.GetAggregateFunction(summarizeFunction)
+
+	// alignTo replaced the boolean alignToFrom, which is ignored as in graphite-web
+	var alignTo string
+	if a, ok := e.NamedArgs()["alignTo"]; ok && a.IsString() {
+		alignTo = a.StringValue()
+	} else if len(e.Args()) > 3 && e.Args()[3].IsString() {
+		alignTo = e.Args()[3].StringValue()
+	}
+
+	if alignTo != "" {
+		start, err := alignStart(from, alignTo, util.GetTimeZone(ctx))
+		if err != nil {
+			return nil, err
+		}
+		if start < from {
+			// the series are fetched again from the aligned start
+			err, _ = getTargetData(ctx, e.Args()[0], start, until, values)
+			if err != nil {
+				return nil, err
+			}
+		}
+		from = start
+	}
+
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*types.MetricData, 0, len(args))
+	for _, arg := range args {
+		buckets := int((arg.StopTime - arg.StartTime + bucketSize - 1) / bucketSize)
+		bucketValues := make([][]float64, buckets)
+
+		t := arg.StartTime
+		for i, v := range arg.Values {
+			if t >= arg.StopTime {
+				break
+			}
+			if !arg.IsAbsent[i] {
+				idx := int((t - arg.StartTime) / bucketSize)
+				bucketValues[idx] = append(bucketValues[idx], v)
+			}
+			t += arg.StepTime
+		}
+
+		r := *arg
+		r.Name = fmt.Sprintf("smartSummarize(%s, \"%s\", \"%s\")", arg.Name, e.Args()[1].StringValue(), summarizeFunction)
+		r.Values = make([]float64, buckets)
+		r.IsAbsent = make([]bool, buckets)
+		r.StepTime = bucketSize
+		r.StopTime = arg.StartTime + int32(buckets)*bucketSize
+
+		for i, v := range bucketValues {
+			switch {
+			case !known:
+				// the percentiles, e.g. p95
+				r.Values[i], r.IsAbsent[i], err = helper.SummarizeValues(summarizeFunction, v)
+				if err != nil {
+					return nil, err
+				}
+			case len(v) == 0:
+				r.IsAbsent[i] = true
+			default:
+				r.Values[i], r.IsAbsent[i] = aggFunc(v, make([]bool, len(v)))
+			}
+			if r.IsAbsent[i] {
+				r.Values[i] = 0
+			}
+		}
+
+		results = append(results, &r)
+	}
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *smartSummarize) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"smartSummarize": {
+			Description: "Smarter version of summarize.\n\nThe alignToFrom boolean parameter has been replaced by alignTo and no longer has any effect.\nAlignment can be to years, months, weeks, days, hours, and minutes.\n\nThis function can be used with aggregation functions ``average``, ``median``, ``sum``, ``min``,\n``max``, ``diff``, ``stddev``, ``count``, ``range``, ``multiply`` & ``last``.",
+			Function:    "smartSummarize(seriesList, intervalString, func='sum', alignTo=None)",
+			Group:       "Transform",
+			Module:      "graphite.render.functions",
+			Name:        "smartSummarize",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "intervalString",
+					Required: true,
+					Suggestions: types.NewSuggestions(
+						"10min",
+						"1h",
+						"1d",
+					),
+					Type: types.Interval,
+				},
+				{
+					Default: types.NewSuggestion("sum"),
+					Name:    "func",
+					Options: types.AggregateFunctionNames(),
+					Type:    types.AggFunc,
+				},
+				{
+					Name: "alignTo",
+					Suggestions: types.NewSuggestions(
+						"1m",
+						"1h",
+						"1d",
+						"1w",
+						"1mon",
+						"1y",
+					),
+					Type: types.String,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,144 @@
+package smartSummarize
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	"github.com/bookingcom/carbonapi/pkg/util"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func series(count int) []float64 {
+	values := make([]float64, count)
+	for i := range values {
+		values[i] = float64(i + 1)
+	}
+	return values
+}
+
+func TestSmartSummarize(t *testing.T) {
+	// Monday, 2020-09-14 00:00 UTC
+	midnight := int32(1600041600)
+	from, until := midnight+5*3600+1800, midnight+8*3600+1800
+
+	tests := []struct {
+		target string
+		m      map[parser.MetricRequest][]*types.MetricData
+		want   *types.MetricData
+	}{
+		{
+			"smartSummarize(metric1,'1h')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "metric1", From: from, Until: until}: {types.MakeMetricData("metric1", series(6), 1800, from)},
+			},
+			types.MakeMetricData(`smartSummarize(metric1, "1h", "sum")`, []float64{3, 7, 11}, 3600, from),
+		},
+		{
+			"smartSummarize(metric1,'1h','multiply')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "metric1", From: from, Until: until}: {types.MakeMetricData("metric1", series(6), 1800, from)},
+			},
+			types.MakeMetricData(`smartSummarize(metric1, "1h", "multiply")`, []float64{2, 12, 30}, 3600, from),
+		},
+		{
+			"smartSummarize(metric1,'2h','avg','days')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "metric1", From: midnight, Until: until}: {types.MakeMetricData("metric1", series(17), 1800, midnight)},
+			},
+			types.MakeMetricData(`smartSummarize(metric1, "2h", "avg")`, []float64{2.5, 6.5, 10.5, 14.5, 17}, 7200, midnight),
+		},
+		{
+			"smartSummarize(metric1,'1h','range',alignTo='1h')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{Metric: "metric1", From: from - 1800, Until: until}: {types.MakeMetricData("metric1", series(7), 1800, from-1800)},
+			},
+			types.MakeMetricData(`smartSummarize(metric1, "1h", "range")`, []float64{1, 1, 1, 0}, 3600, from-1800),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.target, func(t *testing.T) {
+			exp, _, err := parser.ParseExpr(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := util.WithTimeZone(context.Background(), time.UTC)
+			g, err := metadata.GetEvaluator().EvalExpr(ctx, exp, from, until, tt.m, th.NoopGetTargetData)
+			if err != nil {
+				t.Fatalf("failed to eval %s: %+v", tt.target, err)
+			}
+			if len(g) != 1 {
+				t.Fatalf("%s returned %d metrics, want 1", tt.target, len(g))
+			}
+
+			actual := g[0]
+			if actual.Name != tt.want.Name {
+				t.Errorf("bad Name: got %s, want %s", actual.Name, tt.want.Name)
+			}
+			if actual.StartTime != tt.want.StartTime || actual.StepTime != tt.want.StepTime || actual.StopTime != tt.want.StopTime {
+				t.Errorf("bad time range: got %d-%d/%d, want %d-%d/%d", actual.StartTime, actual.StopTime, actual.StepTime,
+					tt.want.StartTime, tt.want.StopTime, tt.want.StepTime)
+			}
+			if !th.NearlyEqualMetrics(actual, tt.want) {
+				t.Errorf("different values: got %v, want %v", actual.Values, tt.want.Values)
+			}
+		})
+	}
+}
+
+func TestAlignStart(t *testing.T) {
+	// Wednesday, 2020-09-16 13:45:30 UTC
+	start := time.Date(2020, 9, 16, 13, 45, 30, 0, time.UTC)
+	plus2 := time.FixedZone("UTC+2", 2*3600)
+
+	tests := []struct {
+		unit string
+		tz   *time.Location
+		want time.Time
+	}{
+		{unit: "seconds", tz: time.UTC, want: start},
+		{unit: "minutes", tz: time.UTC, want: time.Date(2020, 9, 16, 13, 45, 0, 0, time.UTC)},
+		{unit: "1h", tz: time.UTC, want: time.Date(2020, 9, 16, 13, 0, 0, 0, time.UTC)},
+		{unit: "days", tz: time.UTC, want: time.Date(2020, 9, 16, 0, 0, 0, 0, time.UTC)},
+		{unit: "days", tz: plus2, want: time.Date(2020, 9, 16, 0, 0, 0, 0, plus2)},
+		{unit: "weeks", tz: time.UTC, want: time.Date(2020, 9, 14, 0, 0, 0, 0, time.UTC)},
+		{unit: "weeks4", tz: time.UTC, want: time.Date(2020, 9, 10, 0, 0, 0, 0, time.UTC)},
+		{unit: "weeks7", tz: time.UTC, want: time.Date(2020, 9, 13, 0, 0, 0, 0, time.UTC)},
+		{unit: "months", tz: time.UTC, want: time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)},
+		{unit: "1y", tz: time.UTC, want: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit+"/"+tt.tz.String(), func(t *testing.T) {
+			got, err := alignStart(int32(start.Unix()), tt.unit, tt.tz)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != int32(tt.want.Unix()) {
+				t.Errorf("got %v, want %v", time.Unix(int64(got), 0).In(tt.tz), tt.want)
+			}
+		})
+	}
+
+	if _, err := alignStart(int32(start.Unix()), "fortnights", time.UTC); err == nil {
+		t.Error("expected an error for an unknown unit")
+	}
+}
@@ -44,7 +44,7 @@ func TestSubstr(t *testing.T) {
 		{
 			"substr(metric1.foo.bar.baz, 1, 3)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.bar.baz", 0, 1}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
+				{"metric1.foo.bar.baz", 0, 1, 0}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("foo.bar",
 				[]float64{1, 2, 3, 4, 5}, 1, now32)},
@@ -52,7 +52,7 @@ func TestSubstr(t *testing.T) {
 		{
 			"substr(metric1.foo.bar.baz, -3, -1)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.bar.baz", 0, 1}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
+				{"metric1.foo.bar.baz", 0, 1, 0}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("foo.bar",
 				[]float64{1, 2, 3, 4, 5}, 1, now32)},
@@ -60,7 +60,7 @@ func TestSubstr(t *testing.T) {
 		{
 			"substr(metric1.foo.bar.baz, -3)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.bar.baz", 0, 1}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
+				{"metric1.foo.bar.baz", 0, 1, 0}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("foo.bar.baz",
 				[]float64{1, 2, 3, 4, 5}, 1, now32)},
@@ -68,7 +68,7 @@ func TestSubstr(t *testing.T) {
 		{
 			"substr(metric1.foo.bar.baz, -6, -1)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.bar.baz", 0, 1}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
+				{"metric1.foo.bar.baz", 0, 1, 0}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("metric1.foo.bar",
 				[]float64{1, 2, 3, 4, 5}, 1, now32)},
@@ -76,7 +76,7 @@ func TestSubstr(t *testing.T) {
 		{
 			"substr(metric1.foo.bar.baz,0, -1)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1.foo.bar.baz", 0, 1}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
+				{"metric1.foo.bar.baz", 0, 1, 0}: {types.MakeMetricData("metric1.foo.bar.baz", []float64{1, 2, 3, 4, 5}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("metric1.foo.bar",
 				[]float64{1, 2, 3, 4, 5}, 1, now32)},
@@ -1,6 +1,7 @@
 package sum
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -30,7 +31,7 @@ func TestSum(t *testing.T) {
 		{
 			"sumSeries(empty)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"empty", 0, 1}: {
+				{"empty", 0, 1, 0}: {
 					types.MakeMetricData("metric0", []float64{}, 1, now32),
 				},
 			},
@@ -40,10 +41,10 @@ func TestSum(t *testing.T) {
 		{
 			"sumSeries(m1,m2)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"m1", 0, 1}: {
+				{"m1", 0, 1, 0}: {
 					types.MakeMetricData("m1", []float64{1, 2, 3, 4, 5}, 1, now32),
 				},
-				{"m2", 0, 1}: {
+				{"m2", 0, 1, 0}: {
 					types.MakeMetricData("m2", []float64{5, 4, 3, 2, 1}, 1, now32),
 				},
 			},
@@ -53,10 +54,10 @@ func TestSum(t *testing.T) {
 		{
 			"sumSeries(m1,m2)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"m1", 0, 1}: {
+				{"m1", 0, 1, 0}: {
 					types.MakeMetricData("m1", []float64{1, 2, 3, 4, 5}, 1, now32),
 				},
-				{"m2", 0, 1}: {
+				{"m2", 0, 1, 0}: {
 					types.MakeMetricData("m2", []float64{5, 4, 3, 2, 1}, 1, now32),
 				},
 			},
@@ -66,10 +67,10 @@ func TestSum(t *testing.T) {
 		{
 			"sumSeries(different,resolution)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"different", 0, 1}: {
+				{"different", 0, 1, 0}: {
 					types.MakeMetricData("m1", []float64{1, 2, 3, 4}, 2, now32),
 				},
-				{"resolution", 0, 1}: {
+				{"resolution", 0, 1, 0}: {
 					types.MakeMetricData("m2", []float64{1, 2, 3, 4, 5, 6, 7, 8}, 1, now32),
 				},
 			},
@@ -79,10 +80,10 @@ func TestSum(t *testing.T) {
 		{
 			"sumSeries(different,length)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"different", 0, 1}: {
+				{"different", 0, 1, 0}: {
 					types.MakeMetricData("m1", []float64{1, 2, 3, 4}, 1, now32),
 				},
-				{"length", 0, 1}: {
+				{"length", 0, 1, 0}: {
 					types.MakeMetricData("m2", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 1, now32),
 				},
 			},
@@ -92,10 +93,10 @@ func TestSum(t *testing.T) {
 		{
 			"sumSeries(different,resolution_and_length)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"different", 0, 1}: {
+				{"different", 0, 1, 0}: {
 					types.MakeMetricData("m1", []float64{1, 2, 3, 4}, 2, now32),
 				},
-				{"resolution_and_length", 0, 1}: {
+				{"resolution_and_length", 0, 1, 0}: {
 					types.MakeMetricData("m2", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 1, now32),
 				},
 			},
@@ -112,3 +113,29 @@ func TestSum(t *testing.T) {
 		})
 	}
 }
+
+func TestSumSparseSeries(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	sparse := func(name string, values []float64) *types.MetricData {
+		m := types.MakeMetricData(name, values, 1, now32)
+		m.XFilesFactor = 0.5
+		return m
+	}
+
+	// fewer than half of the series have a value at most points, which the xFilesFactor
+	// of the series must not turn into absent points
+	tt := th.EvalTestItem{
+		Target: "sumSeries(m*)",
+		M: map[parser.MetricRequest][]*types.MetricData{
+			{"m*", 0, 1, 0}: {
+				sparse("m1", []float64{1, math.NaN(), math.NaN(), math.NaN()}),
+				sparse("m2", []float64{math.NaN(), 2, math.NaN(), math.NaN()}),
+				sparse("m3", []float64{math.NaN(), math.NaN(), 3, math.NaN()}),
+			},
+		},
+		Want: []*types.MetricData{types.MakeMetricData("sumSeries(m*)",
+			[]float64{1, 2, 3, math.NaN()}, 1, now32)},
+	}
+	th.TestEvalExpr(t, &tt)
+}
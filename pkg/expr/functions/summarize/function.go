@@ -94,25 +94,29 @@ func (f *summarize) Do(ctx context.Context, e parser.Expr, from, until int32, va
 			// We don't have enough data to do math
 			results = append(results, &types.MetricData{
 				Metric: dataTypes.Metric{
-					Name:      name,
-					Values:    arg.Values,
-					IsAbsent:  arg.IsAbsent,
-					StepTime:  arg.StepTime,
-					StartTime: arg.StartTime,
-					StopTime:  arg.StopTime,
-				}})
+					Name:         name,
+					Values:       arg.Values,
+					IsAbsent:     arg.IsAbsent,
+					StepTime:     arg.StepTime,
+					StartTime:    arg.StartTime,
+					StopTime:     arg.StopTime,
+					XFilesFactor: arg.XFilesFactor,
+				},
+			})
 			continue
 		}
 
 		r := types.MetricData{
 			Metric: dataTypes.Metric{
-				Name:      name,
-				Values:    make([]float64, buckets),
-				IsAbsent:  make([]bool, buckets),
-				StepTime:  bucketSize,
-				StartTime: start,
-				StopTime:  stop,
-			}}
+				Name:         name,
+				Values:       make([]float64, buckets),
+				IsAbsent:     make([]bool, buckets),
+				StepTime:     bucketSize,
+				StartTime:    start,
+				StopTime:     stop,
+				XFilesFactor: arg.XFilesFactor,
+			},
+		}
 
 		t := arg.StartTime // unadjusted
 		bucketEnd := start + bucketSize
@@ -132,7 +136,7 @@ func (f *summarize) Do(ctx context.Context, e parser.Expr, from, until int32, va
 			}
 
 			if t >= bucketEnd {
-				r.Values[ridx], r.IsAbsent[ridx], err = helper.SummarizeValues(summarizeFunction, values)
+				r.Values[ridx], r.IsAbsent[ridx], err = summarizeBucket(summarizeFunction, values, bucketItems, arg.XFilesFactor)
 				if err != nil {
 					return []*types.MetricData{}, err
 				}
@@ -145,7 +149,7 @@ func (f *summarize) Do(ctx context.Context, e parser.Expr, from, until int32, va
 
 		// last partial bucket
 		if bucketItems > 0 {
-			r.Values[ridx], r.IsAbsent[ridx], err = helper.SummarizeValues(summarizeFunction, values)
+			r.Values[ridx], r.IsAbsent[ridx], err = summarizeBucket(summarizeFunction, values, bucketItems, arg.XFilesFactor)
 			if err != nil {
 				return []*types.MetricData{}, err
 			}
@@ -156,6 +160,16 @@ func (f *summarize) Do(ctx context.Context, e parser.Expr, from, until int32, va
 	return results, nil
 }
 
+// summarizeBucket summarizes the present values out of the bucketItems points of a bucket,
+// which is absent if too few of them are present for the xFilesFactor.
+func summarizeBucket(f string, values []float64, bucketItems int, xFilesFactor float32) (float64, bool, error) {
+	if !types.XFilesFactorValid(len(values), bucketItems, xFilesFactor) {
+		return 0, true, nil
+	}
+
+	return helper.SummarizeValues(f, values)
+}
+
 // Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
 func (f *summarize) Description() map[string]types.FunctionDescription {
 	return map[string]types.FunctionDescription{
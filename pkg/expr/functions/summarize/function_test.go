@@ -1,6 +1,7 @@
 package summarize
 
 import (
+	"context"
 	"math"
 	"testing"
 
@@ -31,7 +32,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'5s')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{
 					1, 1, 1, 1, 1,
 					2, 2, 2, 2, 2,
 					3, 3, 3, 3, 3,
@@ -50,7 +51,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'5s')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{
 					1, 2, 3, 4, 5,
 				}, 10, now32)},
 			},
@@ -63,7 +64,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'5s','avg')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 1, 1, 1, 1, 2, 2, 2, 2, 2, 3, 3, 3, 3, 3, 4, 4, 4, 4, 4, 5, 5, 5, 5, 5, 1, 2, 3, math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 1, 1, 1, 1, 2, 2, 2, 2, 2, 3, 3, 3, 3, 3, 4, 4, 4, 4, 4, 5, 5, 5, 5, 5, 1, 2, 3, math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()}, 1, now32)},
 			},
 			[]float64{1, 2, 3, 4, 5, 2, math.NaN()},
 			"summarize(metric1,'5s','avg')",
@@ -74,7 +75,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'5s','max')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
 			},
 			[]float64{1, 2, 3, 4.5, 5},
 			"summarize(metric1,'5s','max')",
@@ -85,7 +86,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'5s','min')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
 			},
 			[]float64{0, 1, 1.5, 2, 5},
 			"summarize(metric1,'5s','min')",
@@ -96,7 +97,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'5s','last')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
 			},
 			[]float64{1, 2, 3, 4.5, 5},
 			"summarize(metric1,'5s','last')",
@@ -107,7 +108,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'5s','p5')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
 			},
 			[]float64{0, 1, 1.6, 2.2, 5},
 			"summarize(metric1,'5s','p5')",
@@ -118,7 +119,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'5s','p50')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
 			},
 			[]float64{0.5, 1.5, 2, 3, 5},
 			"summarize(metric1,'5s','p50')",
@@ -129,7 +130,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'5s','p25')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
 			},
 			[]float64{0, 1, 2, 3, 5},
 			"summarize(metric1,'5s','p25')",
@@ -140,7 +141,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'5s','p99')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
 			},
 			[]float64{1, 2, 3, 4.48, 5},
 			"summarize(metric1,'5s','p99')",
@@ -151,7 +152,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'1s','p50')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5}, 1, now32)},
 			},
 			[]float64{1, 0, 0, 0.5, 1, 2, 1, 1, 1.5, 2, 3, 2, 2, 1.5, 3, 4, 3, 2, 3, 4.5, 5, 5, 5, 5, 5},
 			"summarize(metric1,'1s','p50')",
@@ -162,7 +163,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'10min')",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{
 					1, 1, 1, 1, 1, 2, 2, 2, 2, 2,
 					3, 3, 3, 3, 3, 4, 4, 4, 4, 4,
 					5, 5, 5, 5, 5}, 60, tenThirtyTwo)},
@@ -176,7 +177,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'10min','sum',true)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{
 					1, 1, 1, 1, 1, 2, 2, 2, 2, 2,
 					3, 3, 3, 3, 3, 4, 4, 4, 4, 4,
 					5, 5, 5, 5, 5}, 60, tenThirtyTwo)},
@@ -190,7 +191,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'10min','sum',true)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{
 					1, 1, 1, 1, 1, 2, 2, 2, 2, 2,
 					3, 3, 3, 3, 3, 4, 4, 4, 4, 4,
 					5, 5, 5, 5, 5}, 60, tenThirtyTwo)},
@@ -204,7 +205,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'10min','count',true)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{
 					1, 1, 1, 1, 1, 2, 2, 2, 2, 2,
 					3, 3, 3, 3, 3, 4, 4, 4, 4, 4,
 					5, 5, 5, 5, 5}, 60, tenThirtyTwo)},
@@ -218,7 +219,7 @@ func TestEvalSummarize(t *testing.T) {
 		{
 			"summarize(metric1,'10min','median',true)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{
 					1, 1, 1, 1, 1, 2, 2, 2, 2, 2,
 					3, 3, 3, 3, 3, 4, 4, 4, 4, 4,
 					5, 5, 5, 5, 5}, 60, tenThirtyTwo)},
@@ -236,3 +237,33 @@ func TestEvalSummarize(t *testing.T) {
 		th.TestSummarizeEvalExpr(t, &tt)
 	}
 }
+
+func TestSummarizeXFilesFactor(t *testing.T) {
+	input := types.MakeMetricData("metric1", []float64{
+		1, 2, math.NaN(), 4,
+		1, math.NaN(), math.NaN(), math.NaN(),
+		math.NaN(), 2, 3, math.NaN(),
+	}, 1, 0)
+	input.XFilesFactor = 0.5
+
+	exp, _, err := parser.ParseExpr("summarize(metric1,'4s')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[parser.MetricRequest][]*types.MetricData{
+		{"metric1", 0, 1, 0}: {input},
+	}
+
+	got, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1, values, th.NoopGetTargetData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := types.MakeMetricData("summarize(metric1,'4s')", []float64{7, math.NaN(), 5}, 4, 0)
+	if len(got) != 1 || !th.NearlyEqualMetrics(got[0], want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got[0].XFilesFactor != input.XFilesFactor {
+		t.Errorf("got the xFilesFactor %v, want %v", got[0].XFilesFactor, input.XFilesFactor)
+	}
+}
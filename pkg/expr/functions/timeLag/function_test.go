@@ -32,14 +32,14 @@ func TestTimeLagSeriesMultiReturn(t *testing.T) {
 		{
 			"timeLagSeries(metric[12],metric2)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric[12]", 0, 1}: {
+				{"metric[12]", 0, 1, 0}: {
 					types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 5}, 1, now32),
 					types.MakeMetricData("metric2", []float64{2, 4, 6, 8, 10}, 1, now32),
 				},
-				{"metric1", 0, 1}: {
+				{"metric1", 0, 1, 0}: {
 					types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 5}, 1, now32),
 				},
-				{"metric2", 0, 1}: {
+				{"metric2", 0, 1, 0}: {
 					types.MakeMetricData("metric2", []float64{2, 4, 6, 8, 10}, 1, now32),
 				},
 			},
@@ -68,8 +68,8 @@ func TestTimeLagSeries(t *testing.T) {
 		{
 			"timeLagSeries(metric1,metric2)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, now32)},
-				{"metric2", 0, 1}: {types.MakeMetricData("metric2", []float64{2, math.NaN(), 3, math.NaN(), 5, 12}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, now32)},
+				{"metric2", 0, 1, 0}: {types.MakeMetricData("metric2", []float64{2, math.NaN(), 3, math.NaN(), 5, 12}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("timeLagSeries(metric1,metric2)",
 				[]float64{math.NaN(), math.NaN(), math.NaN(), 1, 2, 0}, 1, now32)},
@@ -77,7 +77,7 @@ func TestTimeLagSeries(t *testing.T) {
 		{
 			"timeLagSeries(metric[12])",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric[12]", 0, 1}: {
+				{"metric[12]", 0, 1, 0}: {
 					types.MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, now32),
 					types.MakeMetricData("metric2", []float64{2, math.NaN(), 3, math.NaN(), 5, 12}, 1, now32),
 				},
@@ -88,8 +88,8 @@ func TestTimeLagSeries(t *testing.T) {
 		{
 			"timeLagSeries(metric1,metric2)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 1, 1, 1, 1, 1}, 1, now32)},
-				{"metric2", 0, 1}: {types.MakeMetricData("metric2", []float64{2, 2, 2, 2, 2, 2}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 1, 1, 1, 1, 1}, 1, now32)},
+				{"metric2", 0, 1, 0}: {types.MakeMetricData("metric2", []float64{2, 2, 2, 2, 2, 2}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("timeLagSeries(metric1,metric2)",
 				[]float64{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()}, 1, now32)},
@@ -97,8 +97,8 @@ func TestTimeLagSeries(t *testing.T) {
 		{
 			"timeLagSeries(metric1,metric2)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"metric1", 0, 1}: {types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 2, 3}, 1, now32)},
-				{"metric2", 0, 1}: {types.MakeMetricData("metric2", []float64{2, 3, 4, 5, 6, 7}, 1, now32)},
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 2, 3, 4, 2, 3}, 1, now32)},
+				{"metric2", 0, 1, 0}: {types.MakeMetricData("metric2", []float64{2, 3, 4, 5, 6, 7}, 1, now32)},
 			},
 			[]*types.MetricData{types.MakeMetricData("timeLagSeries(metric1,metric2)",
 				[]float64{math.NaN(), 1, 1, 1, 4, 4}, 1, now32)},
@@ -122,24 +122,24 @@ func TestTimeLagSeriesLists(t *testing.T) {
 		{
 			"timeLagSeriesLists(consumer.*,producer.*)",
 			map[parser.MetricRequest][]*types.MetricData{
-				{"consumer.*", 0, 1}: {
+				{"consumer.*", 0, 1, 0}: {
 					types.MakeMetricData("consumer.1", []float64{1, 2, 3, 4, 5}, 1, now32),
 					types.MakeMetricData("consumer.2", []float64{2, 4, 6, 8, 10}, 1, now32),
 				},
-				{"consumer.1", 0, 1}: {
+				{"consumer.1", 0, 1, 0}: {
 					types.MakeMetricData("consumer.1", []float64{1, 2, 3, 4, 5}, 1, now32),
 				},
-				{"consumer.2", 0, 1}: {
+				{"consumer.2", 0, 1, 0}: {
 					types.MakeMetricData("consumer.2", []float64{2, 4, 6, 8, 10}, 1, now32),
 				},
-				{"producer.*", 0, 1}: {
+				{"producer.*", 0, 1, 0}: {
 					types.MakeMetricData("producer.1", []float64{1, 2, 4, 4, 6}, 1, now32),
 					types.MakeMetricData("producer.2", []float64{2, 4, 7, 8, 11}, 1, now32),
 				},
-				{"producer.1", 0, 1}: {
+				{"producer.1", 0, 1, 0}: {
 					types.MakeMetricData("producer.1", []float64{1, 2, 4, 4, 6}, 1, now32),
 				},
-				{"producer.2", 0, 1}: {
+				{"producer.2", 0, 1, 0}: {
 					types.MakeMetricData("producer.2", []float64{2, 4, 7, 8, 11}, 1, now32),
 				},
 			},
@@ -0,0 +1,109 @@
+package timeSlice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/date"
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	"github.com/bookingcom/carbonapi/pkg/util"
+)
+
+type timeSlice struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &timeSlice{}
+	functions := []string{"timeSlice"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// timeSlice(seriesList, startSliceAt, endSliceAt='now')
+func (f *timeSlice) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArg(ctx, e.Args()[0], from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	startSliceAt, err := e.GetStringArg(1)
+	if err != nil {
+		return nil, err
+	}
+	endSliceAt, err := e.GetStringNamedOrPosArgDefault("endSliceAt", 2, "now")
+	if err != nil {
+		return nil, err
+	}
+
+	tz := util.GetTimeZone(ctx)
+	start, err := date.DateParamToEpoch(startSliceAt, "", time.Now().Unix(), tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w: startSliceAt %s: %v", parser.ErrInvalidArgumentValue, startSliceAt, err)
+	}
+	end, err := date.DateParamToEpoch(endSliceAt, "", time.Now().Unix(), tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w: endSliceAt %s: %v", parser.ErrInvalidArgumentValue, endSliceAt, err)
+	}
+
+	results := make([]*types.MetricData, 0, len(args))
+	for _, a := range args {
+		r := *a
+		r.Name = fmt.Sprintf("timeSlice(%s, %d, %d)", a.Name, start, end)
+		r.Values = make([]float64, len(a.Values))
+		r.IsAbsent = make([]bool, len(a.Values))
+
+		t := a.StartTime
+		for i, v := range a.Values {
+			if a.IsAbsent[i] || t < start || t > end {
+				r.IsAbsent[i] = true
+			} else {
+				r.Values[i] = v
+			}
+			t += a.StepTime
+		}
+		results = append(results, &r)
+	}
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *timeSlice) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"timeSlice": {
+			Description: "Takes one metric or a wildcard metric, followed by a quoted string with the\ntime to start the line and another quoted string with the time to end the line.\nThe start and end times are inclusive. See ``from / until`` in the :doc:`Render API <render_api>`\nfor examples of time formats.\n\nUseful for filtering out a part of a series of data from a wider range of\ndata.\n\nExample:\n\n.. code-block:: none\n\n  &target=timeSlice(network.core.port1,\"00:00 20140101\",\"11:59 20140630\")\n  &target=timeSlice(network.core.port1,\"12:00 20140630\",\"now\")",
+			Function:    "timeSlice(seriesList, startSliceAt, endSliceAt='now')",
+			Group:       "Transform",
+			Module:      "graphite.render.functions",
+			Name:        "timeSlice",
+			Params: []types.FunctionParam{
+				{
+					Name:     "seriesList",
+					Required: true,
+					Type:     types.SeriesList,
+				},
+				{
+					Name:     "startSliceAt",
+					Required: true,
+					Type:     types.Date,
+				},
+				{
+					Default: types.NewSuggestion("now"),
+					Name:    "endSliceAt",
+					Type:    types.Date,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package timeSlice
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	"github.com/bookingcom/carbonapi/pkg/util"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestTimeSlice(t *testing.T) {
+	// 2014-06-30 11:00 UTC
+	start := int32(1404126000)
+
+	tests := []th.EvalTestItem{
+		{
+			"timeSlice(metric1,'11:30 20140630','12:30 20140630')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 2, math.NaN(), 4, 5, 6}, 900, start)},
+			},
+			[]*types.MetricData{types.MakeMetricData("timeSlice(metric1, 1404127800, 1404131400)",
+				[]float64{math.NaN(), math.NaN(), math.NaN(), 4, 5, 6}, 900, start)},
+		},
+		{
+			"timeSlice(metric1,'11:30 20140630')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric1", 0, 1, 0}: {types.MakeMetricData("metric1", []float64{1, 2, 3, 4}, 900, start)},
+			},
+			[]*types.MetricData{types.MakeMetricData("",
+				[]float64{math.NaN(), math.NaN(), 3, 4}, 900, start)},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.Target, func(t *testing.T) {
+			exp, _, err := parser.ParseExpr(tt.Target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := util.WithTimeZone(context.Background(), time.UTC)
+			g, err := metadata.GetEvaluator().EvalExpr(ctx, exp, 0, 1, tt.M, th.NoopGetTargetData)
+			if err != nil {
+				t.Fatalf("failed to eval %s: %+v", tt.Target, err)
+			}
+			if len(g) != 1 {
+				t.Fatalf("%s returned %d metrics, want 1", tt.Target, len(g))
+			}
+			// the end of the slice is the current time if it's not set
+			if tt.Want[0].Name != "" && g[0].Name != tt.Want[0].Name {
+				t.Errorf("bad Name: got %s, want %s", g[0].Name, tt.Want[0].Name)
+			}
+			if !th.NearlyEqualMetrics(g[0], tt.Want[0]) {
+				t.Errorf("different values: got %v, want %v", g[0].Values, tt.Want[0].Values)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package unique
+
+import (
+	"context"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+type unique struct {
+	interfaces.FunctionBase
+}
+
+func GetOrder() interfaces.Order {
+	return interfaces.Any
+}
+
+func New(configFile string) []interfaces.FunctionMetadata {
+	res := make([]interfaces.FunctionMetadata, 0)
+	f := &unique{}
+	functions := []string{"unique"}
+	for _, n := range functions {
+		res = append(res, interfaces.FunctionMetadata{Name: n, F: f})
+	}
+	return res
+}
+
+// unique(*seriesLists)
+func (f *unique) Do(ctx context.Context, e parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	args, err := helper.GetSeriesArgs(ctx, e.Args(), from, until, values, getTargetData)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(args))
+	results := make([]*types.MetricData, 0, len(args))
+	for _, a := range args {
+		if _, ok := seen[a.Name]; ok {
+			continue
+		}
+		seen[a.Name] = struct{}{}
+		results = append(results, a)
+	}
+
+	return results, nil
+}
+
+// Description is auto-generated description, based on output of https://github.com/graphite-project/graphite-web
+func (f *unique) Description() map[string]types.FunctionDescription {
+	return map[string]types.FunctionDescription{
+		"unique": {
+			Description: "Takes an arbitrary number of seriesLists and returns unique series, filtered by name.\n\nExample:\n\n.. code-block:: none\n\n  &target=unique(mostDeviant(server.*.disk_free,5),lowestCurrent(server.*.disk_free,5))\n\nDraws servers with low disk space, and servers with highly deviant disk space, but never the same series twice.",
+			Function:    "unique(*seriesLists)",
+			Group:       "Filter Series",
+			Module:      "graphite.render.functions",
+			Name:        "unique",
+			Params: []types.FunctionParam{
+				{
+					Multiple: true,
+					Name:     "seriesLists",
+					Type:     types.SeriesList,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,58 @@
+package unique
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestUnique(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	tests := []th.EvalTestItem{
+		{
+			"unique(metric[12],metric[23])",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"metric[12]", 0, 1, 0}: {
+					types.MakeMetricData("metric1", []float64{1, math.NaN(), 3}, 1, now32),
+					types.MakeMetricData("metric2", []float64{4, 5, 6}, 1, now32),
+				},
+				{"metric[23]", 0, 1, 0}: {
+					types.MakeMetricData("metric2", []float64{4, 5, 6}, 1, now32),
+					types.MakeMetricData("metric3", []float64{7, 8, 9}, 1, now32),
+				},
+			},
+			[]*types.MetricData{
+				types.MakeMetricData("metric1", []float64{1, math.NaN(), 3}, 1, now32),
+				types.MakeMetricData("metric2", []float64{4, 5, 6}, 1, now32),
+				types.MakeMetricData("metric3", []float64{7, 8, 9}, 1, now32),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		testName := tt.Target
+		t.Run(testName, func(t *testing.T) {
+			th.TestEvalExpr(t, &tt)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package useSeriesAbove
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/helper"
+	"github.com/bookingcom/carbonapi/pkg/expr/metadata"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	th "github.com/bookingcom/carbonapi/tests"
+)
+
+func init() {
+	md := New("")
+	evaluator := th.EvaluatorFromFunc(md[0].F)
+	metadata.SetEvaluator(evaluator)
+	helper.SetEvaluator(evaluator)
+	for _, m := range md {
+		metadata.RegisterFunction(m.Name, m.F, zap.NewNop())
+	}
+}
+
+func TestUseSeriesAbove(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	tests := []th.EvalTestItem{
+		{
+			"useSeriesAbove(servers.*.reqs,10,'reqs','time')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"servers.*.reqs", 0, 1, 0}: {
+					types.MakeMetricData("servers.s1.reqs", []float64{1, 20, math.NaN()}, 1, now32),
+					types.MakeMetricData("servers.s2.reqs", []float64{1, 10, 5}, 1, now32),
+					types.MakeMetricData("servers.s3.reqs", []float64{11, 2, 3}, 1, now32),
+				},
+				{"servers.s1.time", 0, 1, 0}: {types.MakeMetricData("servers.s1.time", []float64{100, 200, 300}, 1, now32)},
+				{"servers.s2.time", 0, 1, 0}: {types.MakeMetricData("servers.s2.time", []float64{400, 500, 600}, 1, now32)},
+			},
+			[]*types.MetricData{
+				types.MakeMetricData("servers.s1.time", []float64{100, 200, 300}, 1, now32),
+			},
+		},
+		{
+			"useSeriesAbove(servers.*.reqs,0,'(s[0-9]).reqs','\\1.time')",
+			map[parser.MetricRequest][]*types.MetricData{
+				{"servers.*.reqs", 0, 1, 0}: {
+					types.MakeMetricData("servers.s1.reqs", []float64{1, 20, math.NaN()}, 1, now32),
+					types.MakeMetricData("servers.s2.reqs", []float64{math.NaN(), math.NaN(), math.NaN()}, 1, now32),
+				},
+				{"servers.s1.time", 0, 1, 0}: {types.MakeMetricData("servers.s1.time", []float64{100, 200, 300}, 1, now32)},
+			},
+			[]*types.MetricData{
+				types.MakeMetricData("servers.s1.time", []float64{100, 200, 300}, 1, now32),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		testName := tt.Target
+		t.Run(testName, func(t *testing.T) {
+			th.TestEvalExpr(t, &tt)
+		})
+	}
+}
+
+func TestUseSeriesAboveFetchesReplaced(t *testing.T) {
+	now32 := int32(time.Now().Unix())
+
+	values := map[parser.MetricRequest][]*types.MetricData{
+		{"servers.*.reqs", 0, 1, 0}: {
+			types.MakeMetricData("servers.s1.reqs", []float64{1, 20, 5}, 1, now32),
+			types.MakeMetricData("servers.s2.reqs", []float64{1, 2, 3}, 1, now32),
+		},
+	}
+	backend := map[string]*types.MetricData{
+		"servers.s1.time": types.MakeMetricData("servers.s1.time", []float64{100, 200, 300}, 1, now32),
+		"servers.s2.time": types.MakeMetricData("servers.s2.time", []float64{400, 500, 600}, 1, now32),
+	}
+
+	var fetched []string
+	getTargetData := func(ctx context.Context, exp parser.Expr, from, until int32, metricMap map[parser.MetricRequest][]*types.MetricData) (error, int) {
+		for _, m := range exp.Metrics() {
+			fetched = append(fetched, m.Metric)
+			if s, ok := backend[m.Metric]; ok {
+				metricMap[parser.MetricRequest{Metric: m.Metric, From: from, Until: until}] = []*types.MetricData{s}
+			}
+		}
+		return nil, 0
+	}
+
+	exp, _, err := parser.ParseExpr("useSeriesAbove(servers.*.reqs,10,'reqs','time')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := metadata.GetEvaluator().EvalExpr(context.Background(), exp, 0, 1, values, getTargetData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fetched) != 1 || fetched[0] != "servers.s1.time" {
+		t.Errorf("expected only servers.s1.time to be fetched, got %v", fetched)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 series, got %d", len(got))
+	}
+	if got[0].Name != "servers.s1.time" || !reflect.DeepEqual(got[0].Values, []float64{100, 200, 300}) {
+		t.Errorf("expected the fetched servers.s1.time, got %s %v", got[0].Name, got[0].Values)
+	}
+}
@@ -79,12 +79,12 @@ func (f *weightedAverage) Do(ctx context.Context, e parser.Expr, from, until int
 	}
 
 	sumOfProductMetricName := "sumOfProducts"
-	sumOfProductsMetrics, err := helper.AggregateSeries(sumOfProductMetricName, productMetrics, false, false, sum.SumAggregation)
+	sumOfProductsMetrics, err := helper.AggregateSeries(sumOfProductMetricName, productMetrics, false, false, 0, sum.SumAggregation)
 	if err != nil {
 		return nil, err
 	}
 	sumOfWeightsMetricName := "sumOfWeights"
-	sumOfWeightsMetrics, err := helper.AggregateSeries(sumOfWeightsMetricName, weightArg, false, false, sum.SumAggregation)
+	sumOfWeightsMetrics, err := helper.AggregateSeries(sumOfWeightsMetricName, weightArg, false, false, 0, sum.SumAggregation)
 	if err != nil {
 		return nil, err
 	}
@@ -112,8 +112,9 @@ func ForEachSeriesDo(ctx context.Context, e parser.Expr, from, until int32, valu
 // AggregateFunc type that defined aggregate function
 type AggregateFunc func([]float64) (float64, bool)
 
-// AggregateSeries aggregates series
-func AggregateSeries(name string, args []*types.MetricData, absent_if_first_series_absent bool, absent_if_any_absent bool, function AggregateFunc) ([]*types.MetricData, error) {
+// AggregateSeries aggregates series. A point is absent if less than xFilesFactor of the series have a value at it.
+// As in graphite-web, the xFilesFactor of the series themselves is not taken into account.
+func AggregateSeries(name string, args []*types.MetricData, absent_if_first_series_absent bool, absent_if_any_absent bool, xFilesFactor float32, function AggregateFunc) ([]*types.MetricData, error) {
 	seriesList, start, end, step, err := Normalize(args)
 	if err != nil {
 		return nil, err
@@ -138,14 +139,33 @@ func AggregateSeries(name string, args []*types.MetricData, absent_if_first_seri
 		isAbsent[i] = true
 
 		absent = absent || (absent_if_first_series_absent && (i >= len(seriesList[0].IsAbsent) || seriesList[0].IsAbsent[i]))
-		if len(values) > 0 && !absent {
+		if types.XFilesFactorValid(len(values), len(seriesList), xFilesFactor) && !absent {
 			result[i], isAbsent[i] = function(values)
 		}
 	}
 	ret := types.New(name, result, isAbsent, step, start)
+	ret.XFilesFactor = xFilesFactor
 	return []*types.MetricData{ret}, nil
 }
 
+// SeriesAggregation adapts the aggregation of the values of n series to AggregateSeries, which passes
+// the present values only. The others are passed as absent, as some aggregations such as avg_zero count them.
+func SeriesAggregation(function types.AggregateFunction, n int) AggregateFunc {
+	return func(values []float64) (float64, bool) {
+		if len(values) >= n {
+			return function(values, make([]bool, len(values)))
+		}
+		v := make([]float64, n)
+		absent := make([]bool, n)
+		copy(v, values)
+		for i := len(values); i < n; i++ {
+			absent[i] = true
+		}
+
+		return function(v, absent)
+	}
+}
+
 // SummarizeValues summarizes values
 func SummarizeValues(f string, values []float64) (float64, bool, error) {
 	rv := 0.0
@@ -188,6 +208,11 @@ func SummarizeValues(f string, values []float64) (float64, bool, error) {
 		val, absent := Percentile(values, 50, true)
 		return val, absent, nil
 	default:
+		if agg, ok := types.GetAggregateFunction(f); ok {
+			val, absent := agg(values, make([]bool, len(values)))
+			return val, absent, nil
+		}
+
 		looks_like_percentile, err := regexp.MatchString(`^p\d\d?$`, f)
 		if err != nil {
 			return 0, true, err
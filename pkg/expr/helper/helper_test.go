@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -27,3 +28,21 @@ func TestPercentile(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractTags(t *testing.T) {
+	tests := []struct {
+		name string
+		exp  map[string]string
+	}{
+		{"metric.foo", map[string]string{"name": "metric.foo"}},
+		{"cpu.load;dc=ams;host=a", map[string]string{"name": "cpu.load", "dc": "ams", "host": "a"}},
+		{"sumSeries(cpu;dc=ams)", map[string]string{"name": "cpu", "dc": "ams"}},
+	}
+
+	for _, tt := range tests {
+		got := ExtractTags(tt.name)
+		if !reflect.DeepEqual(got, tt.exp) {
+			t.Errorf("ExtractTags(%s) = %v, expected %v", tt.name, got, tt.exp)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+	"github.com/bookingcom/carbonapi/pkg/util"
+)
+
+// ExtractTags parses the tags out of a series name in the name;tag1=value1;tag2=value2 form.
+// The series name is returned as the "name" tag, so untagged series only have that one.
+func ExtractTags(s string) map[string]string {
+	i := strings.IndexByte(s, ';')
+	if i < 0 {
+		return map[string]string{"name": ExtractMetric(s)}
+	}
+
+	// the tagged series might be wrapped in functions, e.g. sumSeries(cpu;dc=ams)
+	start := strings.LastIndexAny(s[:i], "(,") + 1
+	end := strings.IndexAny(s[i:], "),")
+	if end < 0 {
+		end = len(s)
+	} else {
+		end += i
+	}
+
+	parts := strings.Split(s[start:end], ";")
+	tags := make(map[string]string, len(parts))
+	tags["name"] = strings.TrimSpace(parts[0])
+	for _, p := range parts[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		tags[kv[0]] = kv[1]
+	}
+
+	return tags
+}
+
+const groupStub = "group"
+
+// AggregateGroups applies the aggregation callback, e.g. 'sum' or 'average', to every group of series.
+// The results are named after the group keys and are returned in the order of keys.
+func AggregateGroups(ctx context.Context, callback string, keys []string, groups map[string][]*types.MetricData,
+	from, until int32, getTargetData interfaces.GetTargetData) ([]*types.MetricData, error) {
+	// the callback is evaluated on a stub name, as the group keys are not always valid series names
+	nexpr, _, err := parser.ParseExpr(fmt.Sprintf("%s(%s)", callback, groupStub))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*types.MetricData
+	for _, k := range keys {
+		nvalues := map[parser.MetricRequest][]*types.MetricData{
+			{
+				Metric:        groupStub,
+				From:          from,
+				Until:         until,
+				MaxDataPoints: util.GetMaxDataPoints(ctx),
+			}: groups[k],
+		}
+
+		r, err := evaluator.EvalExpr(ctx, nexpr, from, until, nvalues, getTargetData)
+		if err != nil {
+			return nil, err
+		}
+		if len(r) > 0 {
+			r[0].Name = k
+			results = append(results, r...)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,99 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/bookingcom/carbonapi/pkg/expr/interfaces"
+	"github.com/bookingcom/carbonapi/pkg/expr/types"
+	"github.com/bookingcom/carbonapi/pkg/parser"
+)
+
+// Window is the size of a moving window, either a number of points or an interval.
+type Window struct {
+	points  int
+	seconds int32
+	arg     string
+}
+
+// GetWindowArg returns the n-th argument as a moving window size, a number of points or an interval string.
+func GetWindowArg(e parser.Expr, n int) (Window, error) {
+	if len(e.Args()) <= n {
+		return Window{}, parser.ErrMissingArgument
+	}
+
+	switch e.Args()[n].Type() {
+	case parser.EtConst:
+		points, err := e.GetIntArg(n)
+		if err != nil {
+			return Window{}, err
+		}
+		if points <= 0 {
+			return Window{}, fmt.Errorf("%w: the window size must be positive", parser.ErrInvalidArgumentValue)
+		}
+		return Window{points: points, arg: strconv.Itoa(points)}, nil
+	case parser.EtString:
+		seconds, err := e.GetIntervalArg(n, 1)
+		if err != nil {
+			return Window{}, err
+		}
+		// the sign of an interval is ignored, as in graphite-web
+		if seconds < 0 {
+			seconds = -seconds
+		}
+		if seconds == 0 {
+			return Window{}, fmt.Errorf("%w: the window size must not be zero", parser.ErrInvalidArgumentValue)
+		}
+		return Window{seconds: seconds, arg: strconv.Quote(e.Args()[n].StringValue())}, nil
+	}
+
+	return Window{}, parser.ErrBadType
+}
+
+// String returns the window as it is shown in the series names.
+func (w Window) String() string {
+	return w.arg
+}
+
+// Points returns the number of points of the window for a series with the step.
+func (w Window) Points(step int32) int {
+	if w.seconds > 0 {
+		return int(w.seconds / step)
+	}
+	return w.points
+}
+
+// GetSeriesArg returns the series of the expression with the history before from that a full first
+// window needs, and the seconds of that history. The history is fetched first, which for a window of
+// points needs the steps of the series from from.
+func (w Window) GetSeriesArg(ctx context.Context, arg parser.Expr, from, until int32, values map[parser.MetricRequest][]*types.MetricData, getTargetData interfaces.GetTargetData) ([]*types.MetricData, int32, error) {
+	bootstrap := w.seconds
+	if bootstrap == 0 {
+		args, err := GetSeriesArg(ctx, arg, from, until, values, getTargetData)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var step int32
+		for _, a := range args {
+			if a.StepTime > step {
+				step = a.StepTime
+			}
+		}
+		bootstrap = step * int32(w.points)
+	}
+
+	if bootstrap > 0 {
+		if err, _ := getTargetData(ctx, arg, from-bootstrap, until, values); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	series, err := GetSeriesArg(ctx, arg, from-bootstrap, until, values, getTargetData)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return series, bootstrap, nil
+}
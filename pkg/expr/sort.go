@@ -145,6 +145,13 @@ func splitByDotsIgnoringBraces(str string) []string {
 
 // SortMetrics sort metric data alphabetically.
 func SortMetrics(metrics []*types.MetricData, mfetch parser.MetricRequest) {
+	// Tagged series have no glob segments to sort by, so the full names are used
+	if mfetch.IsSeriesByTag() {
+		sort.SliceStable(metrics, func(i, j int) bool {
+			return metrics[i].Name < metrics[j].Name
+		})
+		return
+	}
 	// Don't do any work if there are no globs in the metric name
 	if !strings.ContainsAny(mfetch.Metric, "*?[{") {
 		return
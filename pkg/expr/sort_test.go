@@ -116,6 +116,24 @@ func TestSortMetrics(t *testing.T) {
 				types.MakeMetricData(fourth, []float64{}, 1, 0),
 			},
 		},
+		{
+			[]*types.MetricData{
+				types.MakeMetricData("cpu;dc=lhr", []float64{}, 1, 0),
+				types.MakeMetricData("cpu;dc=ams", []float64{}, 1, 0),
+				types.MakeMetricData("cpu;dc=fra", []float64{}, 1, 0),
+			},
+			parser.MetricRequest{
+				Metric: "seriesByTag('name=cpu')",
+				From:   0,
+				Until:  1,
+			},
+			[]*types.MetricData{
+				// Tagged series are sorted by full name
+				types.MakeMetricData("cpu;dc=ams", []float64{}, 1, 0),
+				types.MakeMetricData("cpu;dc=fra", []float64{}, 1, 0),
+				types.MakeMetricData("cpu;dc=lhr", []float64{}, 1, 0),
+			},
+		},
 	}
 	for i, test := range tests {
 		if len(test.metrics) != len(test.sorted) {
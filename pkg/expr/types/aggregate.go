@@ -0,0 +1,144 @@
+package types
+
+import (
+	"math"
+	"sort"
+)
+
+// AggregateFunction aggregates the values, skipping the absent ones. It returns true if the result is absent.
+type AggregateFunction func(v []float64, absent []bool) (float64, bool)
+
+// aggregateFunctions are the aggregations known to graphite by their names and aliases.
+// They are shared by consolidateBy, filterSeries and the aggregate family of functions.
+var aggregateFunctions = map[string]AggregateFunction{
+	"average":  AggMean,
+	"avg":      AggMean,
+	"avg_zero": AggAvgZero,
+	"median":   AggMedian,
+	"sum":      AggSum,
+	"total":    AggSum,
+	"min":      AggMin,
+	"max":      AggMax,
+	"diff":     AggDiff,
+	"stddev":   AggStddev,
+	"count":    AggCount,
+	"range":    AggRange,
+	"rangeOf":  AggRange,
+	"multiply": AggMultiply,
+	"first":    AggFirst,
+	"last":     AggLast,
+	"current":  AggLast,
+}
+
+// GetAggregateFunction returns the aggregation with the graphite name or alias.
+func GetAggregateFunction(name string) (AggregateFunction, bool) {
+	f, ok := aggregateFunctions[name]
+	return f, ok
+}
+
+// AggregateFunctionNames returns the sorted names of the aggregations, aliases included.
+func AggregateFunctionNames() []string {
+	names := make([]string, 0, len(aggregateFunctions))
+	for name := range aggregateFunctions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// presentValues returns the values which are neither absent nor NaN.
+func presentValues(v []float64, absent []bool) []float64 {
+	res := make([]float64, 0, len(v))
+	for i, vv := range v {
+		if !absent[i] && !math.IsNaN(vv) {
+			res = append(res, vv)
+		}
+	}
+
+	return res
+}
+
+// AggAvgZero computes mean of values, the absent points count as zeros
+func AggAvgZero(v []float64, absent []bool) (float64, bool) {
+	if len(v) == 0 {
+		return math.NaN(), true
+	}
+	sum, _ := AggSum(v, absent)
+
+	return sum / float64(len(v)), false
+}
+
+// AggMedian computes median of values
+func AggMedian(v []float64, absent []bool) (float64, bool) {
+	values := presentValues(v, absent)
+	if len(values) == 0 {
+		return math.NaN(), true
+	}
+	sort.Float64s(values)
+
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2, false
+	}
+	return values[mid], false
+}
+
+// AggDiff subtracts the rest of values from the first one
+func AggDiff(v []float64, absent []bool) (float64, bool) {
+	values := presentValues(v, absent)
+	if len(values) == 0 {
+		return math.NaN(), true
+	}
+
+	diff := values[0]
+	for _, vv := range values[1:] {
+		diff -= vv
+	}
+	return diff, false
+}
+
+// AggStddev computes population standard deviation of values
+func AggStddev(v []float64, absent []bool) (float64, bool) {
+	values := presentValues(v, absent)
+	if len(values) == 0 {
+		return math.NaN(), true
+	}
+
+	mean, _ := AggMean(values, make([]bool, len(values)))
+	var sum float64
+	for _, vv := range values {
+		sum += (vv - mean) * (vv - mean)
+	}
+	return math.Sqrt(sum / float64(len(values))), false
+}
+
+// AggCount computes the number of present values
+func AggCount(v []float64, absent []bool) (float64, bool) {
+	n := len(presentValues(v, absent))
+	return float64(n), n == 0
+}
+
+// AggRange computes the difference of max and min of values
+func AggRange(v []float64, absent []bool) (float64, bool) {
+	max, abs := AggMax(v, absent)
+	if abs {
+		return math.NaN(), true
+	}
+	min, _ := AggMin(v, absent)
+	return max - min, false
+}
+
+// AggMultiply computes product of values
+func AggMultiply(v []float64, absent []bool) (float64, bool) {
+	values := presentValues(v, absent)
+	if len(values) == 0 {
+		return math.NaN(), true
+	}
+
+	product := 1.0
+	for _, vv := range values {
+		product *= vv
+	}
+	return product, false
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAggregateFunctions(t *testing.T) {
+	values := []float64{3, 0, 1, math.NaN(), 4, 0}
+	absent := []bool{false, true, false, false, false, true}
+
+	tests := []struct {
+		name       string
+		want       float64
+		wantAbsent bool
+	}{
+		{name: "avg", want: 8.0 / 3},
+		{name: "avg_zero", want: 8.0 / 6},
+		{name: "median", want: 3},
+		{name: "sum", want: 8},
+		{name: "min", want: 1},
+		{name: "max", want: 4},
+		{name: "diff", want: -2},
+		{name: "stddev", want: math.Sqrt(14.0 / 9)},
+		{name: "count", want: 3},
+		{name: "range", want: 3},
+		{name: "multiply", want: 12},
+		{name: "first", want: 3},
+		{name: "last", want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := GetAggregateFunction(tt.name)
+			if !ok {
+				t.Fatalf("the aggregation %s is not registered", tt.name)
+			}
+			got, gotAbsent := f(values, absent)
+			if gotAbsent != tt.wantAbsent || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v (absent %v), want %v (absent %v)", got, gotAbsent, tt.want, tt.wantAbsent)
+			}
+		})
+	}
+}
+
+func TestAggregateFunctionsAllAbsent(t *testing.T) {
+	for _, name := range []string{"median", "diff", "stddev", "count", "range", "multiply"} {
+		f, _ := GetAggregateFunction(name)
+		if _, absent := f([]float64{0, 0}, []bool{true, true}); !absent {
+			t.Errorf("%s of the absent values is present", name)
+		}
+	}
+
+	if _, ok := GetAggregateFunction("foo"); ok {
+		t.Error("an unknown aggregation is registered")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"math/rand"
 	"testing"
+	"time"
 )
 
 func TestJSONResponse(t *testing.T) {
@@ -53,6 +54,45 @@ func TestRawResponse(t *testing.T) {
 	}
 }
 
+func TestWriteMatchesMarshal(t *testing.T) {
+	results := []*MetricData{
+		MakeMetricData("metric1", []float64{1, 1.5, 2.25, math.NaN()}, 100, 100),
+		MakeMetricData("metric2", []float64{2, 2.5, 3.25, 4, 5}, 100, 100),
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), MarshalJSON(results)) {
+		t.Errorf("WriteJSON=%s, want %s", buf.String(), MarshalJSON(results))
+	}
+
+	buf.Reset()
+	if err := WriteRaw(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), MarshalRaw(results)) {
+		t.Errorf("WriteRaw=%s, want %s", buf.String(), MarshalRaw(results))
+	}
+
+	buf.Reset()
+	if err := WriteCSV(&buf, results, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), MarshalCSV(results, time.UTC)) {
+		t.Errorf("WriteCSV=%s, want %s", buf.String(), MarshalCSV(results, time.UTC))
+	}
+
+	buf.Reset()
+	if err := WriteJSON(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[]" {
+		t.Errorf("WriteJSON(nil)=%s, want []", buf.String())
+	}
+}
+
 func getData(rangeSize int) []float64 {
 	var data = make([]float64, rangeSize)
 	var r = rand.New(rand.NewSource(99)) // nolint:gosec
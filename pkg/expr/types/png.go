@@ -1,6 +0,0 @@
-// +build !cairo
-
-package types
-
-type GraphOptions struct {
-}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"io"
 	"math"
 	"strconv"
 	"time"
@@ -31,6 +32,16 @@ type MetricData struct {
 	AggregateFunction func([]float64, []bool) (float64, bool)
 }
 
+// XFilesFactorValid reports whether nonNull present points out of total are enough
+// for an aggregate of them to be present under xFilesFactor.
+func XFilesFactorValid(nonNull, total int, xFilesFactor float32) bool {
+	if nonNull == 0 || total == 0 {
+		return false
+	}
+
+	return float32(nonNull)/float32(total) >= xFilesFactor
+}
+
 // New creates new MetricData with given metric timeseries values and isAbsent
 func New(name string, values []float64, isAbsent []bool, step, start int32) *MetricData {
 	stop := start + int32(len(values))*step
@@ -74,34 +85,59 @@ func MarshalCSV(results []*MetricData, location *time.Location) []byte {
 	var b []byte
 
 	for _, r := range results {
+		b = appendCSV(b, r, location)
+	}
+	return b
+}
 
-		step := r.StepTime
-		t := r.StartTime
-		for i, v := range r.Values {
-			b = append(b, '"')
-			b = append(b, r.Name...)
-			b = append(b, '"')
-			b = append(b, ',')
+// WriteCSV writes metric data to w as CSV, one series at a time.
+func WriteCSV(w io.Writer, results []*MetricData, location *time.Location) error {
+	var b []byte
+	for _, r := range results {
+		b = appendCSV(b[:0], r, location)
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
 
-			tmp := time.Unix(int64(t), 0)
-			if location != nil {
-				tmp = tmp.In(location)
-			}
+	return nil
+}
 
-			b = append(b, tmp.Format("2006-01-02 15:04:05")...)
-			b = append(b, ',')
-			if !r.IsAbsent[i] {
-				b = strconv.AppendFloat(b, v, 'f', -1, 64)
-			}
-			b = append(b, '\n')
-			t += step
+func appendCSV(b []byte, r *MetricData, location *time.Location) []byte {
+	step := r.StepTime
+	t := r.StartTime
+	for i, v := range r.Values {
+		b = append(b, '"')
+		b = append(b, r.Name...)
+		b = append(b, '"')
+		b = append(b, ',')
+
+		tmp := time.Unix(int64(t), 0)
+		if location != nil {
+			tmp = tmp.In(location)
+		}
+
+		b = append(b, tmp.Format("2006-01-02 15:04:05")...)
+		b = append(b, ',')
+		if !r.IsAbsent[i] {
+			b = strconv.AppendFloat(b, v, 'f', -1, 64)
 		}
+		b = append(b, '\n')
+		t += step
 	}
+
 	return b
 }
 
 // ConsolidateJSON consolidates values to maxDataPoints size
-func ConsolidateJSON(maxDataPoints int, results []*MetricData) (consolidated []*MetricData) {
+//
+// Deprecated: use Consolidate, which is not specific to the JSON format.
+func ConsolidateJSON(maxDataPoints int, results []*MetricData) []*MetricData {
+	return Consolidate(maxDataPoints, results)
+}
+
+// Consolidate consolidates values to maxDataPoints size
+func Consolidate(maxDataPoints int, results []*MetricData) (consolidated []*MetricData) {
 	var startTime int32 = -1
 	var endTime int32 = -1
 
@@ -119,11 +155,15 @@ func ConsolidateJSON(maxDataPoints int, results []*MetricData) (consolidated []*
 	timeRange := endTime - startTime
 
 	if timeRange <= 0 {
-		return
+		return results
 	}
 
 	ret := make([]*MetricData, len(results))
 	for i, r := range results {
+		if r.StepTime <= 0 {
+			ret[i] = r
+			continue
+		}
 		numberOfDataPoints := math.Floor(float64(timeRange) / float64(r.StepTime))
 		if numberOfDataPoints > float64(maxDataPoints) {
 			valuesPerPoint := math.Ceil(numberOfDataPoints / float64(maxDataPoints))
@@ -151,40 +191,74 @@ func MarshalJSON(results []*MetricData) []byte {
 		}
 		topComma = true
 
-		b = append(b, `{"target":`...)
-		b = strconv.AppendQuoteToASCII(b, r.Name)
-		b = append(b, `,"datapoints":[`...)
+		b = appendJSON(b, r)
+	}
 
-		var innerComma bool
-		t := r.StartTime
-		absent := r.IsAbsent
-		for i, v := range r.Values {
-			if innerComma {
-				b = append(b, ',')
-			}
-			innerComma = true
+	b = append(b, ']')
 
-			b = append(b, '[')
+	return b
+}
 
-			if (i < len(absent) && absent[i]) || math.IsInf(v, 0) || math.IsNaN(v) {
-				b = append(b, "null"...)
-			} else {
-				b = strconv.AppendFloat(b, v, 'f', -1, 64)
-			}
+// WriteJSON writes metric data to w as JSON, one series at a time.
+func WriteJSON(w io.Writer, results []*MetricData) error {
+	b := []byte{'['}
 
+	var topComma bool
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+
+		if topComma {
 			b = append(b, ',')
+		}
+		topComma = true
+
+		b = appendJSON(b, r)
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		b = b[:0]
+	}
+
+	b = append(b, ']')
+	_, err := w.Write(b)
 
-			b = strconv.AppendInt(b, int64(t), 10)
+	return err
+}
+
+func appendJSON(b []byte, r *MetricData) []byte {
+	b = append(b, `{"target":`...)
+	b = strconv.AppendQuoteToASCII(b, r.Name)
+	b = append(b, `,"datapoints":[`...)
+
+	var innerComma bool
+	t := r.StartTime
+	absent := r.IsAbsent
+	for i, v := range r.Values {
+		if innerComma {
+			b = append(b, ',')
+		}
+		innerComma = true
 
-			b = append(b, ']')
+		b = append(b, '[')
 
-			t += r.StepTime
+		if (i < len(absent) && absent[i]) || math.IsInf(v, 0) || math.IsNaN(v) {
+			b = append(b, "null"...)
+		} else {
+			b = strconv.AppendFloat(b, v, 'f', -1, 64)
 		}
 
-		b = append(b, `]}`...)
+		b = append(b, ',')
+
+		b = strconv.AppendInt(b, int64(t), 10)
+
+		b = append(b, ']')
+
+		t += r.StepTime
 	}
 
-	b = append(b, ']')
+	b = append(b, `]}`...)
 
 	return b
 }
@@ -237,32 +311,50 @@ func MarshalRaw(results []*MetricData) []byte {
 	var b []byte
 
 	for _, r := range results {
+		b = appendRaw(b, r)
+	}
+	return b
+}
 
-		b = append(b, r.Name...)
+// WriteRaw writes metric data to w in the raw format, one series at a time.
+func WriteRaw(w io.Writer, results []*MetricData) error {
+	var b []byte
+	for _, r := range results {
+		b = appendRaw(b[:0], r)
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
 
-		b = append(b, ',')
-		b = strconv.AppendInt(b, int64(r.StartTime), 10)
-		b = append(b, ',')
-		b = strconv.AppendInt(b, int64(r.StopTime), 10)
-		b = append(b, ',')
-		b = strconv.AppendInt(b, int64(r.StepTime), 10)
-		b = append(b, '|')
+	return nil
+}
 
-		var comma bool
-		for i, v := range r.Values {
-			if comma {
-				b = append(b, ',')
-			}
-			comma = true
-			if r.IsAbsent[i] {
-				b = append(b, "None"...)
-			} else {
-				b = strconv.AppendFloat(b, v, 'f', -1, 64)
-			}
-		}
+func appendRaw(b []byte, r *MetricData) []byte {
+	b = append(b, r.Name...)
 
-		b = append(b, '\n')
+	b = append(b, ',')
+	b = strconv.AppendInt(b, int64(r.StartTime), 10)
+	b = append(b, ',')
+	b = strconv.AppendInt(b, int64(r.StopTime), 10)
+	b = append(b, ',')
+	b = strconv.AppendInt(b, int64(r.StepTime), 10)
+	b = append(b, '|')
+
+	var comma bool
+	for i, v := range r.Values {
+		if comma {
+			b = append(b, ',')
+		}
+		comma = true
+		if r.IsAbsent[i] {
+			b = append(b, "None"...)
+		} else {
+			b = strconv.AppendFloat(b, v, 'f', -1, 64)
+		}
 	}
+
+	b = append(b, '\n')
+
 	return b
 }
 
@@ -292,10 +384,7 @@ func (r *MetricData) Consolidate(valuesPerPoint int) *MetricData {
 	absent := r.IsAbsent
 
 	for len(v) >= valuesPerPoint && valuesPerPoint > 0 {
-		val, abs := ret.AggregateFunction(v[:valuesPerPoint], absent[:valuesPerPoint])
-		if math.IsNaN(val) {
-			val = 0
-		}
+		val, abs := ret.consolidatePoint(v[:valuesPerPoint], absent[:valuesPerPoint])
 		aggV = append(aggV, val)
 		aggA = append(aggA, abs)
 		v = v[valuesPerPoint:]
@@ -303,10 +392,7 @@ func (r *MetricData) Consolidate(valuesPerPoint int) *MetricData {
 	}
 
 	if len(v) > 0 {
-		val, abs := ret.AggregateFunction(v, absent)
-		if math.IsNaN(val) {
-			val = 0
-		}
+		val, abs := ret.consolidatePoint(v, absent)
 		aggV = append(aggV, val)
 		aggA = append(aggA, abs)
 	}
@@ -317,6 +403,27 @@ func (r *MetricData) Consolidate(valuesPerPoint int) *MetricData {
 	return &ret
 }
 
+// consolidatePoint aggregates the points of one interval, which is absent if
+// too few of them are present for the xFilesFactor.
+func (r *MetricData) consolidatePoint(v []float64, absent []bool) (float64, bool) {
+	nonNull := 0
+	for i, vv := range v {
+		if !absent[i] && !math.IsNaN(vv) {
+			nonNull++
+		}
+	}
+	if !XFilesFactorValid(nonNull, len(v), r.XFilesFactor) {
+		return 0, true
+	}
+
+	val, abs := r.AggregateFunction(v, absent)
+	if math.IsNaN(val) {
+		val = 0
+	}
+
+	return val, abs
+}
+
 // AggMean computes mean (sum(v)/len(v), excluding NaN points) of values
 func AggMean(v []float64, absent []bool) (float64, bool) {
 	var sum float64
@@ -373,22 +480,22 @@ func AggSum(v []float64, absent []bool) (float64, bool) {
 	return sum, abs
 }
 
-// AggFirst returns first point
+// AggFirst returns first present point
 func AggFirst(v []float64, absent []bool) (float64, bool) {
-	var m = math.Inf(-1)
-	var abs = true
-	if len(v) > 0 {
-		return v[0], absent[0]
+	for i, vv := range v {
+		if !absent[i] && !math.IsNaN(vv) {
+			return vv, false
+		}
 	}
-	return m, abs
+	return math.Inf(-1), true
 }
 
-// AggLast returns last point
+// AggLast returns last present point
 func AggLast(v []float64, absent []bool) (float64, bool) {
-	var m = math.Inf(-1)
-	var abs = true
-	if len(v) > 0 {
-		return v[len(v)-1], absent[len(v)-1]
+	for i := len(v) - 1; i >= 0; i-- {
+		if !absent[i] && !math.IsNaN(v[i]) {
+			return v[i], false
+		}
 	}
-	return m, abs
+	return math.Inf(-1), true
 }
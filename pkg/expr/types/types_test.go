@@ -66,6 +66,7 @@ func TestConsolidate(t *testing.T) {
 		expected       *MetricData
 		expectedEnd    int32
 		aggregation    func([]float64, []bool) (float64, bool)
+		xFilesFactor   float32
 	}{
 		{"no consolidation",
 			MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, 0),
@@ -73,6 +74,7 @@ func TestConsolidate(t *testing.T) {
 			MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, 0),
 			6,
 			nil,
+			0,
 		},
 		{"zero vpp, no consolidation",
 			MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, 0),
@@ -80,6 +82,7 @@ func TestConsolidate(t *testing.T) {
 			MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, 0),
 			6,
 			nil,
+			0,
 		},
 		{"negative vpp, no consolidation",
 			MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, 0),
@@ -87,6 +90,7 @@ func TestConsolidate(t *testing.T) {
 			MakeMetricData("metric1", []float64{1, math.NaN(), math.NaN(), 3, 4, 12}, 1, 0),
 			6,
 			nil,
+			0,
 		},
 		{"valuesPerPoint_2_none_values",
 			MakeMetricData("metric1", []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()}, 1, 0),
@@ -94,6 +98,7 @@ func TestConsolidate(t *testing.T) {
 			MakeMetricData("metric1", []float64{math.NaN(), math.NaN(), math.NaN()}, 2, 0),
 			5,
 			nil,
+			0,
 		},
 		{"valuesPerPoint_2_some_none_values",
 			MakeMetricData("metric1", []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), 1, 2, 3, 4}, 1, 0),
@@ -101,6 +106,7 @@ func TestConsolidate(t *testing.T) {
 			MakeMetricData("metric1", []float64{math.NaN(), math.NaN(), 1, 2.5, 4}, 2, 0),
 			9,
 			nil,
+			0,
 		},
 		{"valuesPerPoint_2_average",
 			MakeMetricData("metric1", []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 1, 0),
@@ -108,6 +114,7 @@ func TestConsolidate(t *testing.T) {
 			MakeMetricData("metric1", []float64{0.5, 2.5, 4.5, 6.5, 8.5, 10}, 2, 0),
 			11,
 			nil,
+			0,
 		},
 		{"valuesPerPoint_2_max",
 			MakeMetricData("metric1", []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 1, 0),
@@ -115,6 +122,7 @@ func TestConsolidate(t *testing.T) {
 			MakeMetricData("metric1", []float64{1, 3, 5, 7, 9, 10}, 2, 0),
 			11,
 			AggMax,
+			0,
 		},
 		{"valuesPerPoint_2_min",
 			MakeMetricData("metric1", []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 1, 0),
@@ -122,6 +130,23 @@ func TestConsolidate(t *testing.T) {
 			MakeMetricData("metric1", []float64{0, 2, 4, 6, 8, 10}, 2, 0),
 			11,
 			AggMin,
+			0,
+		},
+		{"valuesPerPoint_2_xFilesFactor",
+			MakeMetricData("metric1", []float64{math.NaN(), 1, 2, 3, math.NaN(), math.NaN(), 4}, 1, 0),
+			2,
+			MakeMetricData("metric1", []float64{math.NaN(), 2.5, math.NaN(), 4}, 2, 0),
+			7,
+			nil,
+			0.6,
+		},
+		{"valuesPerPoint_3_xFilesFactor_sum",
+			MakeMetricData("metric1", []float64{1, math.NaN(), 2, 3, math.NaN(), math.NaN()}, 1, 0),
+			3,
+			MakeMetricData("metric1", []float64{3, math.NaN()}, 3, 0),
+			6,
+			AggSum,
+			0.5,
 		},
 	}
 
@@ -129,6 +154,7 @@ func TestConsolidate(t *testing.T) {
 		if test.aggregation != nil {
 			test.input.AggregateFunction = test.aggregation
 		}
+		test.input.XFilesFactor = test.xFilesFactor
 		got := test.input.Consolidate(test.valuesPerPoint)
 		if diff := cmp.Diff(test.expected.Values, got.Values); diff != "" {
 			t.Errorf("Consolidation Values for %s (-want +got):\n%s", test.name, diff)
@@ -151,3 +177,50 @@ func TestConsolidate(t *testing.T) {
 
 	}
 }
+
+func TestConsolidateToMaxDataPoints(t *testing.T) {
+	results := []*MetricData{
+		MakeMetricData("fine", []float64{1, 2, 3, 4, 5, 6}, 10, 0),
+		MakeMetricData("coarse", []float64{1, 2}, 30, 0),
+		MakeMetricData("const", []float64{1}, 0, 0),
+	}
+
+	got := Consolidate(2, results)
+
+	if got[0].StepTime != 30 || len(got[0].Values) != 2 {
+		t.Errorf("Expected fine series to be consolidated to step 30 and 2 values, got step %d and %v", got[0].StepTime, got[0].Values)
+	}
+	if got[1] != results[1] {
+		t.Error("Expected coarse series to be left as is")
+	}
+	if got[2] != results[2] {
+		t.Error("Expected series without step to be left as is")
+	}
+
+	empty := []*MetricData{MakeMetricData("empty", nil, 10, 100)}
+	if got := Consolidate(2, empty); len(got) != 1 {
+		t.Errorf("Expected series with empty time range to be returned, got %v", got)
+	}
+}
+
+func TestXFilesFactorValid(t *testing.T) {
+	tests := []struct {
+		nonNull, total int
+		xFilesFactor   float32
+		want           bool
+	}{
+		{0, 0, 0, false},
+		{0, 10, 0, false},
+		{1, 10, 0, true},
+		{3, 10, 0.3, true},
+		{2, 10, 0.3, false},
+		{10, 10, 1, true},
+		{9, 10, 1, false},
+	}
+
+	for _, tt := range tests {
+		if got := XFilesFactorValid(tt.nonNull, tt.total, tt.xFilesFactor); got != tt.want {
+			t.Errorf("XFilesFactorValid(%d, %d, %v) = %v, want %v", tt.nonNull, tt.total, tt.xFilesFactor, got, tt.want)
+		}
+	}
+}
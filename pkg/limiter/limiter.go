@@ -0,0 +1,161 @@
+package limiter
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// The weights of a latency in the short and the long term average latencies.
+	shortWeight = 0.1
+	longWeight  = 0.01
+	// The requests of a kind that are served before its averages are trusted.
+	warmup = 10
+)
+
+// Limiter is a semaphore whose size may adapt to the latency of the requests.
+// A nil *Limiter does not limit anything.
+//
+// Unless the adaptive limit is enabled, the limit is fixed at its maximum. Otherwise it follows
+// the additive increase, multiplicative decrease scheme of the TCP congestion control: it grows by one
+// with each request served in time while it is in use, and shrinks by a factor with each request that
+// times out or is served while the requests slow down. The requests slow down when the short term
+// average of their latency is above a tolerance of its long term average. The averages are kept
+// per kind of request, as the finds and the renders, or the small and the large renders, take
+// different times when healthy.
+type Limiter struct {
+	config   cfg.AdaptiveLimit
+	maxLimit float64
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	limit     float64
+	inFlight  int
+	latencies map[string]*latency
+
+	limitGauge prometheus.Gauge
+}
+
+// latency keeps the short and the long term averages of the latencies of a kind of request.
+// The first ones are plain averages, so they do not lean on the first latencies.
+type latency struct {
+	n           int
+	short, long float64
+}
+
+func (lat *latency) add(d time.Duration) {
+	lat.n++
+	lat.short += math.Max(1/float64(lat.n), shortWeight) * (float64(d) - lat.short)
+	lat.long += math.Max(1/float64(lat.n), longWeight) * (float64(d) - lat.long)
+}
+
+// New makes a limiter starting at, and never growing above, the maximum limit.
+// The gauge is optional and exposes the current limit.
+func New(config cfg.AdaptiveLimit, maxLimit int, limitGauge prometheus.Gauge) *Limiter {
+	if maxLimit < 1 {
+		maxLimit = 1
+	}
+	if config.MinLimit < 1 {
+		config.MinLimit = 1
+	}
+	if config.MinLimit > maxLimit {
+		config.MinLimit = maxLimit
+	}
+	if config.Tolerance <= 1 {
+		config.Tolerance = cfg.DefaultAdaptiveLimitTolerance
+	}
+	if config.Backoff <= 0 || config.Backoff >= 1 {
+		config.Backoff = cfg.DefaultAdaptiveLimitBackoff
+	}
+
+	l := &Limiter{
+		config:     config,
+		maxLimit:   float64(maxLimit),
+		limit:      float64(maxLimit),
+		latencies:  make(map[string]*latency),
+		limitGauge: limitGauge,
+	}
+	l.cond = sync.NewCond(&l.mu)
+	l.setLimit(l.limit)
+
+	return l
+}
+
+// Acquire blocks until a request can be made within the limit.
+func (l *Limiter) Acquire() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	for l.inFlight >= int(l.limit) {
+		l.cond.Wait()
+	}
+	l.inFlight++
+	l.mu.Unlock()
+}
+
+// Release releases a request of the given kind acquired before, and adapts the limit to its latency,
+// which includes the time it waited in the queue. A dropped request, one that timed out,
+// always shrinks the limit.
+func (l *Limiter) Release(kind string, d time.Duration, dropped bool) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// the limit only grows when it is in use
+	used := float64(l.inFlight)*2 >= l.limit
+	l.inFlight--
+	l.cond.Broadcast()
+
+	if !l.config.Enabled {
+		return
+	}
+
+	lat, ok := l.latencies[kind]
+	if !ok {
+		lat = &latency{}
+		l.latencies[kind] = lat
+	}
+	lat.add(d)
+	slow := lat.n >= warmup && lat.short > l.config.Tolerance*lat.long
+
+	switch {
+	case dropped || slow:
+		l.setLimit(l.limit * l.config.Backoff)
+	case used:
+		l.setLimit(l.limit + 1)
+	}
+}
+
+// Limit returns the current limit.
+func (l *Limiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+func (l *Limiter) setLimit(limit float64) {
+	if limit < float64(l.config.MinLimit) {
+		limit = float64(l.config.MinLimit)
+	}
+	if limit > l.maxLimit {
+		limit = l.maxLimit
+	}
+	l.limit = limit
+	if l.limitGauge != nil {
+		l.limitGauge.Set(float64(int(limit)))
+	}
+}
@@ -0,0 +1,145 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bookingcom/carbonapi/pkg/cfg"
+)
+
+func TestLimiterAdapts(t *testing.T) {
+	l := New(cfg.AdaptiveLimit{Enabled: true, MinLimit: 2}, 10, nil)
+	if got := l.Limit(); got != 10 {
+		t.Fatalf("expected to start at the maximum limit, got %d", got)
+	}
+
+	serve := func(latency time.Duration, dropped bool) {
+		l.Acquire()
+		l.Release("render", latency, dropped)
+	}
+
+	for i := 0; i < 100; i++ {
+		serve(10*time.Millisecond, false)
+	}
+	if got := l.Limit(); got != 10 {
+		t.Fatalf("expected the steady requests not to shrink the limit, got %d", got)
+	}
+
+	for i := 0; i < 5; i++ {
+		serve(100*time.Millisecond, false)
+	}
+	if got := l.Limit(); got >= 10 {
+		t.Errorf("expected the slowdown to shrink the limit, got %d", got)
+	}
+
+	limit := l.Limit()
+	serve(10*time.Millisecond, true)
+	if got := l.Limit(); got >= limit {
+		t.Errorf("expected a dropped request to shrink the limit below %d, got %d", limit, got)
+	}
+
+	for i := 0; i < 100; i++ {
+		serve(time.Second, true)
+	}
+	if got := l.Limit(); got != 2 {
+		t.Errorf("expected the limit not to shrink below the minimum, got %d", got)
+	}
+
+	// the limit only grows while it is used
+	for i := 0; i < 10; i++ {
+		n := l.Limit()
+		for j := 0; j < n; j++ {
+			l.Acquire()
+		}
+		for j := 0; j < n; j++ {
+			l.Release("render", time.Second, false)
+		}
+	}
+	if got := l.Limit(); got != 10 {
+		t.Errorf("expected the limit to grow back to the maximum, got %d", got)
+	}
+}
+
+func TestLimiterMixedLatencies(t *testing.T) {
+	l := New(cfg.AdaptiveLimit{Enabled: true}, 100, nil)
+
+	// the healthy requests of different kinds, and of the same kind, take different times
+	for i := 0; i < 1000; i++ {
+		l.Acquire()
+		l.Release("find", 5*time.Millisecond, false)
+		l.Acquire()
+		l.Release("render", 100*time.Millisecond, false)
+		l.Acquire()
+		if i%2 == 0 {
+			l.Release("batch", 5*time.Millisecond, false)
+		} else {
+			l.Release("batch", 100*time.Millisecond, false)
+		}
+	}
+
+	if got := l.Limit(); got != 100 {
+		t.Errorf("expected the healthy requests not to shrink the limit, got %d", got)
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l := New(cfg.AdaptiveLimit{}, 10, nil)
+	for i := 0; i < 100; i++ {
+		l.Acquire()
+		l.Release("render", time.Second, true)
+	}
+	if got := l.Limit(); got != 10 {
+		t.Errorf("expected the limit to stay fixed at the maximum, got %d", got)
+	}
+}
+
+func TestLimiterUnused(t *testing.T) {
+	l := New(cfg.AdaptiveLimit{Enabled: true}, 10, nil)
+	for i := 0; i < 100; i++ {
+		l.Acquire()
+		l.Release("render", time.Second, true)
+	}
+	if got := l.Limit(); got != 1 {
+		t.Fatalf("expected the limit to shrink to 1, got %d", got)
+	}
+
+	l.setLimit(8)
+	l.Acquire()
+	l.Release("render", time.Millisecond, false)
+	if got := l.Limit(); got != 8 {
+		t.Errorf("expected an unused limit not to grow, got %d", got)
+	}
+}
+
+func TestLimiterBlocks(t *testing.T) {
+	l := New(cfg.AdaptiveLimit{}, 1, nil)
+	l.Acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		l.Acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("expected the limiter to block above its limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	l.Release("render", time.Millisecond, false)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected the limiter to unblock on release")
+	}
+}
+
+func TestNilLimiter(t *testing.T) {
+	var l *Limiter
+	l.Acquire()
+	l.Release("render", time.Second, true)
+	if got := l.Limit(); got != 0 {
+		t.Errorf("expected no limit, got %d", got)
+	}
+}
@@ -10,6 +10,40 @@ type MetricRequest struct {
 	Metric string
 	From   int32
 	Until  int32
+
+	// MaxDataPoints is passed on to the backends that can consolidate server-side.
+	// Zero means no limit.
+	MaxDataPoints int64
+}
+
+const seriesByTagPrefix = "seriesByTag("
+
+// IsSeriesByTag reports whether the request is a seriesByTag call that has to be
+// resolved with tag expressions instead of globs.
+func (m MetricRequest) IsSeriesByTag() bool {
+	return strings.HasPrefix(m.Metric, seriesByTagPrefix)
+}
+
+// TagExpressions returns the tag expressions of a seriesByTag request.
+func (m MetricRequest) TagExpressions() ([]string, error) {
+	e, _, err := ParseExpr(m.Metric)
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := make([]string, 0, len(e.Args()))
+	for i := range e.Args() {
+		expr, err := e.GetStringArg(i)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) == 0 {
+		return nil, ErrMissingArgument
+	}
+
+	return exprs, nil
 }
 
 // ExprType defines a type for expression types constants (e.x. functions, values, constants, parameters, strings)
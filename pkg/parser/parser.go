@@ -123,6 +123,12 @@ func (e *expr) Metrics() []MetricRequest {
 	case EtConst, EtString:
 		return nil
 	case EtFunc:
+		if e.target == "seriesByTag" {
+			// the tag expressions are resolved to series by the backends,
+			// so the whole call is requested as a single metric
+			return []MetricRequest{{Metric: e.ToString()}}
+		}
+
 		var r []MetricRequest
 		for _, a := range e.args {
 			r = append(r, a.Metrics()...)
@@ -166,11 +172,11 @@ func (e *expr) Metrics() []MetricRequest {
 			}
 
 			return r2
-		case "holtWintersForecast", "holtWintersConfidenceBands", "holtWintersAberration":
+		case "holtWintersForecast", "holtWintersConfidenceBands", "holtWintersConfidenceArea", "holtWintersAberration":
 			for i := range r {
 				r[i].From -= 7 * 86400 // starts -7 days from where the original starts
 			}
-		case "movingAverage", "movingMedian", "movingMin", "movingMax", "movingSum":
+		case "movingAverage", "movingMedian", "movingMin", "movingMax", "movingSum", "movingWindow", "exponentialMovingAverage":
 			if len(e.args) >= 2 && e.args[1].etype == EtString {
 				offs, err := e.GetIntervalArg(1, 1)
 				if err != nil {
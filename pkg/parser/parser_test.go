@@ -443,3 +443,48 @@ func TestParseExpr(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesByTagMetrics(t *testing.T) {
+	tests := []struct {
+		s     string
+		m     []MetricRequest
+		exprs []string
+	}{
+		{
+			s:     "seriesByTag('name=cpu')",
+			m:     []MetricRequest{{Metric: "seriesByTag('name=cpu')"}},
+			exprs: []string{"name=cpu"},
+		},
+		{
+			s:     "sumSeries(seriesByTag('name=cpu', 'dc=~ams.*'))",
+			m:     []MetricRequest{{Metric: "seriesByTag('name=cpu', 'dc=~ams.*')"}},
+			exprs: []string{"name=cpu", "dc=~ams.*"},
+		},
+	}
+
+	for _, ttr := range tests {
+		tt := ttr
+		t.Run(tt.s, func(t *testing.T) {
+			e, _, err := ParseExpr(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := e.Metrics()
+			if !reflect.DeepEqual(m, tt.m) {
+				t.Fatalf("metrics for %s: got %v, want %v", tt.s, m, tt.m)
+			}
+			if !m[0].IsSeriesByTag() {
+				t.Errorf("expected %s to be a seriesByTag request", m[0].Metric)
+			}
+
+			exprs, err := m[0].TagExpressions()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(exprs, tt.exprs) {
+				t.Errorf("tag expressions for %s: got %v, want %v", tt.s, exprs, tt.exprs)
+			}
+		})
+	}
+}
@@ -148,6 +148,13 @@ const TLDCacheMissErr = "TLD cache doesn't have the targets TLDs"
 // backends with the second return value as false. If the TLDs of targets were not found in the cache, it returns all
 // backends with the second return value as true.
 func FilterBackendByTopLevelDomain(cache *expirecache.Cache, TLDPrefixes []TopLevelDomainPrefix, backends []backend.Backend, targets []string) ([]backend.Backend, bool) {
+	for _, target := range targets {
+		if types.IsTagged(target) {
+			// tagged series are not part of the top-level domain tree
+			return backends, false
+		}
+	}
+
 	targetTlds := make([]string, 0, len(targets))
 	for _, target := range targets {
 		targetTlds = append(targetTlds, getTargetTopLevelDomain(target, TLDPrefixes))
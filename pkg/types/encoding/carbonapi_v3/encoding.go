@@ -0,0 +1,149 @@
+/*
+Package carbonapi_v3 defines encoding and decoding methods for Find and Render
+requests and responses in the version 3 of the carbonapi protocol buffer schema.
+
+Unlike version 2, the requests are protocol buffer messages themselves.
+They carry a time range and maxDataPoints per target, which allows backends
+such as graphite-clickhouse to consolidate the data server-side.
+Absent points are encoded as NaN values.
+*/
+package carbonapi_v3
+
+import (
+	"math"
+
+	"github.com/bookingcom/carbonapi/pkg/types"
+
+	"github.com/go-graphite/protocol/carbonapi_v3_pb"
+)
+
+// RenderRequestEncoder encodes the render request with one fetch request per target.
+func RenderRequestEncoder(request types.RenderRequest) ([]byte, error) {
+	out := carbonapi_v3_pb.MultiFetchRequest{
+		Metrics: make([]*carbonapi_v3_pb.FetchRequest, len(request.Targets)),
+	}
+
+	for i, target := range request.Targets {
+		out.Metrics[i] = &carbonapi_v3_pb.FetchRequest{
+			Name:           target,
+			PathExpression: target,
+			StartTime:      int64(request.From),
+			StopTime:       int64(request.Until),
+			MaxDataPoints:  request.MaxDataPoints,
+		}
+	}
+
+	return out.MarshalVT()
+}
+
+// FindRequestEncoder encodes the find request.
+func FindRequestEncoder(request types.FindRequest) ([]byte, error) {
+	out := carbonapi_v3_pb.MultiGlobRequest{
+		Metrics: []string{request.Query},
+	}
+
+	return out.MarshalVT()
+}
+
+func FindEncoder(matches types.Matches) ([]byte, error) {
+	glob := &carbonapi_v3_pb.GlobResponse{
+		Name:    matches.Name,
+		Matches: make([]*carbonapi_v3_pb.GlobMatch, len(matches.Matches)),
+	}
+
+	for i, match := range matches.Matches {
+		glob.Matches[i] = &carbonapi_v3_pb.GlobMatch{
+			Path:   match.Path,
+			IsLeaf: match.IsLeaf,
+		}
+	}
+
+	out := carbonapi_v3_pb.MultiGlobResponse{
+		Metrics: []*carbonapi_v3_pb.GlobResponse{glob},
+	}
+
+	return out.MarshalVT()
+}
+
+// FindDecoder decodes the find response, merging the matches of all globs into one.
+func FindDecoder(blob []byte) (types.Matches, error) {
+	f := carbonapi_v3_pb.MultiGlobResponse{}
+	if err := f.UnmarshalVT(blob); err != nil {
+		return types.Matches{}, err
+	}
+
+	matches := types.Matches{}
+	for _, glob := range f.Metrics {
+		if matches.Name == "" {
+			matches.Name = glob.Name
+		}
+		for _, match := range glob.Matches {
+			matches.Matches = append(matches.Matches, types.Match{
+				Path:   match.Path,
+				IsLeaf: match.IsLeaf,
+			})
+		}
+	}
+
+	return matches, nil
+}
+
+func RenderEncoder(metrics []types.Metric) ([]byte, error) {
+	out := carbonapi_v3_pb.MultiFetchResponse{
+		Metrics: make([]*carbonapi_v3_pb.FetchResponse, len(metrics)),
+	}
+
+	for i, m := range metrics {
+		values := make([]float64, len(m.Values))
+		for j, v := range m.Values {
+			if j < len(m.IsAbsent) && m.IsAbsent[j] {
+				values[j] = math.NaN()
+			} else {
+				values[j] = v
+			}
+		}
+
+		out.Metrics[i] = &carbonapi_v3_pb.FetchResponse{
+			Name:           m.Name,
+			PathExpression: m.Name,
+			StartTime:      int64(m.StartTime),
+			StopTime:       int64(m.StopTime),
+			StepTime:       int64(m.StepTime),
+			XFilesFactor:   m.XFilesFactor,
+			Values:         values,
+		}
+	}
+
+	return out.MarshalVT()
+}
+
+func RenderDecoder(blob []byte) ([]types.Metric, error) {
+	resp := carbonapi_v3_pb.MultiFetchResponse{}
+	if err := resp.UnmarshalVT(blob); err != nil {
+		return nil, err
+	}
+
+	metrics := make([]types.Metric, len(resp.Metrics))
+	for i, m := range resp.Metrics {
+		metric := types.Metric{
+			Name:         m.Name,
+			StartTime:    int32(m.StartTime),
+			StopTime:     int32(m.StopTime),
+			StepTime:     int32(m.StepTime),
+			Values:       m.Values,
+			IsAbsent:     make([]bool, len(m.Values)),
+			XFilesFactor: m.XFilesFactor,
+		}
+
+		for j, v := range metric.Values {
+			if math.IsNaN(v) {
+				metric.Values[j] = 0
+				metric.IsAbsent[j] = true
+			}
+		}
+
+		metrics[i] = metric
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,98 @@
+package carbonapi_v3
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/bookingcom/carbonapi/pkg/types"
+
+	"github.com/go-graphite/protocol/carbonapi_v3_pb"
+)
+
+func TestRenderRequestEncoder(t *testing.T) {
+	request := types.NewRenderRequest([]string{"foo.*", "bar"}, 100, 200)
+	request.MaxDataPoints = 50
+
+	blob, err := RenderRequestEncoder(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := carbonapi_v3_pb.MultiFetchRequest{}
+	if err := got.UnmarshalVT(blob); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Metrics) != 2 {
+		t.Fatalf("Expected 2 fetch requests, got %d", len(got.Metrics))
+	}
+	for i, m := range got.Metrics {
+		if m.Name != request.Targets[i] || m.PathExpression != request.Targets[i] {
+			t.Errorf("Expected target %s, got %s", request.Targets[i], m.PathExpression)
+		}
+		if m.StartTime != 100 || m.StopTime != 200 || m.MaxDataPoints != 50 {
+			t.Errorf("Bad fetch request %+v", m)
+		}
+	}
+}
+
+func TestRenderEncodeDecode(t *testing.T) {
+	metrics := []types.Metric{
+		{
+			Name:         "foo",
+			StartTime:    100,
+			StopTime:     130,
+			StepTime:     10,
+			Values:       []float64{1, 0, 3},
+			IsAbsent:     []bool{false, true, false},
+			XFilesFactor: 0.5,
+		},
+	}
+
+	blob, err := RenderEncoder(metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := carbonapi_v3_pb.MultiFetchResponse{}
+	if err := resp.UnmarshalVT(blob); err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(resp.Metrics[0].Values[1]) {
+		t.Errorf("Expected absent point to be encoded as NaN, got %v", resp.Metrics[0].Values[1])
+	}
+
+	got, err := RenderDecoder(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, metrics) {
+		t.Errorf("Expected %+v, got %+v", metrics, got)
+	}
+}
+
+func TestFindEncodeDecode(t *testing.T) {
+	matches := types.Matches{
+		Name: "foo.*",
+		Matches: []types.Match{
+			{Path: "foo.bar", IsLeaf: true},
+			{Path: "foo.baz", IsLeaf: false},
+		},
+	}
+
+	blob, err := FindEncoder(matches)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FindDecoder(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, matches) {
+		t.Errorf("Expected %+v, got %+v", matches, got)
+	}
+}
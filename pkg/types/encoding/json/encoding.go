@@ -1,6 +1,6 @@
 /*
-Package json defines encoding and decoding methods for Find, Info and Render
-responses.
+Package json defines encoding and decoding methods for Find, Info, Render and
+tag series responses.
 */
 package json
 
@@ -84,6 +84,129 @@ the identity map, or that the iteration at least stabilizes.
 func FindDecoder(blob []byte) ([]types.Match, error) { }
 */
 
+// TagSeriesDecoder converts the JSON list of series names returned by
+// /tags/findSeries to matches
+func TagSeriesDecoder(blob []byte) (types.Matches, error) {
+	var series []string
+	if err := json.Unmarshal(blob, &series); err != nil {
+		return types.Matches{}, err
+	}
+
+	matches := types.Matches{
+		Matches: make([]types.Match, 0, len(series)),
+	}
+	for _, s := range series {
+		matches.Matches = append(matches.Matches, types.Match{
+			Path:   s,
+			IsLeaf: true,
+		})
+	}
+
+	return matches, nil
+}
+
+// TagSeriesEncoder converts tagged series matches to the JSON list of series names
+func TagSeriesEncoder(matches types.Matches) ([]byte, error) {
+	series := make([]string, 0, len(matches.Matches))
+	for _, m := range matches.Matches {
+		series = append(series, m.Path)
+	}
+
+	return json.Marshal(series)
+}
+
+type jsonTag struct {
+	Tag string `json:"tag"`
+}
+
+type jsonTagValue struct {
+	Count int    `json:"count"`
+	Value string `json:"value"`
+}
+
+type jsonTagValues struct {
+	Tag    string         `json:"tag"`
+	Values []jsonTagValue `json:"values"`
+}
+
+// TagNamesEncoder converts tag names to the /tags JSON format
+func TagNamesEncoder(names []types.TagValue) ([]byte, error) {
+	jts := make([]jsonTag, 0, len(names))
+	for _, n := range names {
+		jts = append(jts, jsonTag{Tag: n.Value})
+	}
+
+	return json.Marshal(jts)
+}
+
+// TagNamesDecoder converts the /tags JSON response to tag names
+func TagNamesDecoder(blob []byte) ([]types.TagValue, error) {
+	var jts []jsonTag
+	if err := json.Unmarshal(blob, &jts); err != nil {
+		return nil, err
+	}
+
+	names := make([]types.TagValue, 0, len(jts))
+	for _, jt := range jts {
+		names = append(names, types.TagValue{Value: jt.Tag})
+	}
+
+	return names, nil
+}
+
+// TagValuesEncoder converts the values of a tag to the /tags/<tag> JSON format
+func TagValuesEncoder(tag string, values []types.TagValue) ([]byte, error) {
+	jtv := jsonTagValues{
+		Tag:    tag,
+		Values: make([]jsonTagValue, 0, len(values)),
+	}
+	for _, v := range values {
+		jtv.Values = append(jtv.Values, jsonTagValue{Count: v.Count, Value: v.Value})
+	}
+
+	return json.Marshal(jtv)
+}
+
+// TagValuesDecoder converts the /tags/<tag> JSON response to tag values
+func TagValuesDecoder(blob []byte) ([]types.TagValue, error) {
+	var jtv jsonTagValues
+	if err := json.Unmarshal(blob, &jtv); err != nil {
+		return nil, err
+	}
+
+	values := make([]types.TagValue, 0, len(jtv.Values))
+	for _, v := range jtv.Values {
+		values = append(values, types.TagValue{Value: v.Value, Count: v.Count})
+	}
+
+	return values, nil
+}
+
+// AutoCompleteEncoder converts tag names or values to the autocompletion JSON list
+func AutoCompleteEncoder(values []types.TagValue) ([]byte, error) {
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		list = append(list, v.Value)
+	}
+
+	return json.Marshal(list)
+}
+
+// AutoCompleteDecoder converts the autocompletion JSON list to tag names or values
+func AutoCompleteDecoder(blob []byte) ([]types.TagValue, error) {
+	var list []string
+	if err := json.Unmarshal(blob, &list); err != nil {
+		return nil, err
+	}
+
+	values := make([]types.TagValue, 0, len(list))
+	for _, v := range list {
+		values = append(values, types.TagValue{Value: v})
+	}
+
+	return values, nil
+}
+
 type jsonInfo struct {
 	Name              string    `json:"name"`
 	AggregationMethod string    `json:"aggregationMethod"`
@@ -129,3 +129,51 @@ func TestFindMatchesJSONEncoding(t *testing.T) {
 		})
 	}
 }
+
+func TestTagValuesRoundTrip(t *testing.T) {
+	values := []types.TagValue{
+		{Value: "ams", Count: 3},
+		{Value: "lhr", Count: 1},
+	}
+
+	blob, err := TagValuesEncoder("dc", values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `{"tag":"dc","values":[{"count":3,"value":"ams"},{"count":1,"value":"lhr"}]}`
+	if string(blob) != exp {
+		t.Errorf("got %s, %s expected", blob, exp)
+	}
+
+	got, err := TagValuesDecoder(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("got %v values, %v expected", got, values)
+	}
+}
+
+func TestTagNamesDecoder(t *testing.T) {
+	got, err := TagNamesDecoder([]byte(`[{"tag":"dc"},{"tag":"name"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []types.TagValue{{Value: "dc"}, {Value: "name"}}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got %v names, %v expected", got, exp)
+	}
+}
+
+func TestTagSeriesDecoder(t *testing.T) {
+	got, err := TagSeriesDecoder([]byte(`["cpu;dc=ams","cpu;dc=lhr"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Matches) != 2 || got.Matches[0].Path != "cpu;dc=ams" || !got.Matches[0].IsLeaf {
+		t.Errorf("got %v matches", got.Matches)
+	}
+}
@@ -1,8 +1,8 @@
 /*
 Package types defines the main Graphite types we use internally.
 
-The definitions correspond to the types of responses to the /render, /info, and
-/metrics/find handlers in graphite-web and go-carbon.
+The definitions correspond to the types of responses to the /render, /info,
+/metrics/find and /tags handlers in graphite-web and go-carbon.
 */
 package types
 
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"math"
 	"sort"
+	"strings"
 
 	"github.com/bookingcom/carbonapi/pkg/cfg"
 
@@ -22,6 +23,7 @@ var (
 	ErrMetricsNotFound = ErrNotFound("No metrics returned")
 	ErrMatchesNotFound = ErrNotFound("No matches found")
 	ErrInfoNotFound    = ErrNotFound("No information found")
+	ErrTagsNotFound    = ErrNotFound("No tags found")
 )
 
 // ErrNotFound signals the HTTP not found error
@@ -54,10 +56,88 @@ func NewInfoRequest(target string) InfoRequest {
 	}
 }
 
+// TagSeriesRequest is a request to find the series matching all of the given
+// tag expressions, e.g. 'name=cpu' or 'dc=~ams.*'.
+type TagSeriesRequest struct {
+	Exprs []string
+}
+
+func NewTagSeriesRequest(exprs []string) TagSeriesRequest {
+	return TagSeriesRequest{
+		Exprs: exprs,
+	}
+}
+
+// TagsRequestType is the kind of tag lookup of a TagsRequest.
+type TagsRequestType int
+
+const (
+	// TagNames lists the tag names, /tags.
+	TagNames TagsRequestType = iota
+	// TagValues lists the values of a single tag, /tags/<tag>.
+	TagValues
+	// AutoCompleteTags completes tag names, /tags/autoComplete/tags.
+	AutoCompleteTags
+	// AutoCompleteValues completes the values of a tag, /tags/autoComplete/values.
+	AutoCompleteValues
+)
+
+// TagsRequest is a request for tag names or values.
+type TagsRequest struct {
+	Type   TagsRequestType
+	Tag    string   // The tag to list or complete the values of.
+	Exprs  []string // The tag expressions to narrow the autocompletion by.
+	Filter string   // The regular expression to filter the names or values by.
+	Prefix string   // The prefix of the autocompleted names or values.
+	Limit  int      // The maximum number of results, 0 means no limit.
+}
+
+// TagValue is a tag name or value with the number of series it is in.
+// The count is only reported for TagValues requests.
+type TagValue struct {
+	Value string
+	Count int
+}
+
+// MergeTagValues merges and deduplicates the tag values returned by several backends.
+// As the replicas report the same series, the highest count is kept for each value.
+func MergeTagValues(values [][]TagValue, limit int) []TagValue {
+	set := make(map[string]int)
+	for _, vs := range values {
+		for _, v := range vs {
+			if c, ok := set[v.Value]; !ok || v.Count > c {
+				set[v.Value] = v.Count
+			}
+		}
+	}
+
+	merged := make([]TagValue, 0, len(set))
+	for v, c := range set {
+		merged = append(merged, TagValue{Value: v, Count: c})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Value < merged[j].Value
+	})
+
+	if limit > 0 && len(merged) > limit {
+		merged = merged[:limit]
+	}
+
+	return merged
+}
+
+// IsTagged reports whether the series name is in the tagged name;tag=value form.
+func IsTagged(name string) bool {
+	return strings.IndexByte(name, ';') > 0
+}
+
 type RenderRequest struct {
 	Targets []string
 	From    int32
 	Until   int32
+
+	// MaxDataPoints, if positive, lets the backends that support it consolidate the series server-side.
+	MaxDataPoints int64
 }
 
 func NewRenderRequest(targets []string, from int32, until int32) RenderRequest {
@@ -105,6 +185,10 @@ type Metric struct {
 	Values    []float64
 	IsAbsent  []bool
 
+	// XFilesFactor is the fraction of the points of an interval that must be present
+	// for its consolidated or aggregated point to be present.
+	XFilesFactor float32
+
 	SourceClusters []string
 }
 
@@ -2,6 +2,7 @@ package types
 
 import (
 	"math"
+	"reflect"
 	"sort"
 	"testing"
 
@@ -61,6 +62,24 @@ func TestMergeMatchesDeduplicate(t *testing.T) {
 	}
 }
 
+func TestMergeTagValues(t *testing.T) {
+	values := [][]TagValue{
+		{{Value: "ams", Count: 2}, {Value: "lhr", Count: 1}},
+		{{Value: "ams", Count: 3}, {Value: "fra", Count: 1}},
+	}
+
+	got := MergeTagValues(values, 0)
+	exp := []TagValue{{Value: "ams", Count: 3}, {Value: "fra", Count: 1}, {Value: "lhr", Count: 1}}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	got = MergeTagValues(values, 2)
+	if len(got) != 2 || got[1].Value != "fra" {
+		t.Errorf("Expected the first 2 values, got %v", got)
+	}
+}
+
 func TestSortMetrics(t *testing.T) {
 	metrics := []Metric{
 		{
@@ -4,6 +4,7 @@ package util
 import (
 	"context"
 	"net/http"
+	"time"
 
 	uuid "github.com/satori/go.uuid"
 	"google.golang.org/grpc/metadata"
@@ -17,6 +18,8 @@ const (
 
 	uuidKey key = iota
 	priorityKey
+	maxDataPointsKey
+	timeZoneKey
 )
 
 // GetPriority returns the current request priority. Less is more
@@ -33,6 +36,34 @@ func WithPriority(ctx context.Context, priority int) context.Context {
 	return context.WithValue(ctx, priorityKey, priority)
 }
 
+// GetMaxDataPoints returns the maxDataPoints of the current render request.
+// If not set, returns 0, which means no limit.
+func GetMaxDataPoints(ctx context.Context) int64 {
+	if p := ctx.Value(maxDataPointsKey); p != nil {
+		return p.(int64)
+	}
+	return 0
+}
+
+// WithMaxDataPoints returns new context with maxDataPoints set
+func WithMaxDataPoints(ctx context.Context, maxDataPoints int64) context.Context {
+	return context.WithValue(ctx, maxDataPointsKey, maxDataPoints)
+}
+
+// GetTimeZone returns the time zone of the current render request.
+// If not set, returns the local time zone.
+func GetTimeZone(ctx context.Context) *time.Location {
+	if tz := ctx.Value(timeZoneKey); tz != nil {
+		return tz.(*time.Location)
+	}
+	return time.Local
+}
+
+// WithTimeZone returns new context with the time zone set
+func WithTimeZone(ctx context.Context, tz *time.Location) context.Context {
+	return context.WithValue(ctx, timeZoneKey, tz)
+}
+
 // GetUUID gets the Carbon UUID of a request.
 func GetUUID(ctx context.Context) string {
 	if id := ctx.Value(uuidKey); id != nil {
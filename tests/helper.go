@@ -67,12 +67,13 @@ func DeepClone(original map[parser.MetricRequest][]*types.MetricData) map[parser
 		for _, originalMetric := range originalMetrics {
 			copiedMetric := types.MetricData{
 				Metric: dataTypes.Metric{
-					Name:      originalMetric.Name,
-					StartTime: originalMetric.StartTime,
-					StopTime:  originalMetric.StopTime,
-					StepTime:  originalMetric.StepTime,
-					Values:    make([]float64, len(originalMetric.Values)),
-					IsAbsent:  make([]bool, len(originalMetric.IsAbsent)),
+					Name:         originalMetric.Name,
+					StartTime:    originalMetric.StartTime,
+					StopTime:     originalMetric.StopTime,
+					StepTime:     originalMetric.StepTime,
+					Values:       make([]float64, len(originalMetric.Values)),
+					IsAbsent:     make([]bool, len(originalMetric.IsAbsent)),
+					XFilesFactor: originalMetric.XFilesFactor,
 				},
 			}
 